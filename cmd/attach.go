@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+	"github.com/spf13/cobra"
+)
+
+func newAttachCmd(a *app) *cobra.Command {
+	attachCmd := &cobra.Command{
+		Use:   "attach <issue-id> <file>...",
+		Short: "Attach files to a Redmine issue",
+		Long: `Upload files and attach them to an existing Redmine issue.
+Files are streamed, so large logs and screenshots are not loaded into memory.`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			issueID, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid issue ID: %s", args[0])
+			}
+
+			description, _ := cmd.Flags().GetString("description")
+			contentType, _ := cmd.Flags().GetString("content-type")
+			specs := make([]attachSpec, 0, len(args)-1)
+			for _, path := range args[1:] {
+				specs = append(specs, attachSpec{path: path, description: description, contentType: contentType})
+			}
+
+			client, err := a.client(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			uploads, err := uploadAttachments(ctx, client, specs)
+			if err != nil {
+				return err
+			}
+
+			update := &redmine.IssueUpdate{Uploads: uploads}
+			update.Notes, _ = cmd.Flags().GetString("note")
+			if err := client.UpdateIssueContext(ctx, issueID, update); err != nil {
+				return fmt.Errorf("failed to attach files: %w", err)
+			}
+
+			fmt.Fprintf(a.stdout, "Attached %d file(s) to issue #%d\n", len(uploads), issueID)
+			return nil
+		},
+	}
+
+	attachCmd.Flags().String("description", "", "Description for the attached files")
+	attachCmd.Flags().String("content-type", "", "Content type (default: guessed from the file extension)")
+	attachCmd.Flags().String("note", "", "Add a note/comment with the attachments")
+	return attachCmd
+}
+
+// attachSpec は --attach で指定された1ファイル分の情報
+type attachSpec struct {
+	path        string
+	description string
+	contentType string
+}
+
+// parseAttachSpecs は "path[;description[;content-type]]" 形式の指定を解析する。
+func parseAttachSpecs(values []string) ([]attachSpec, error) {
+	specs := make([]attachSpec, 0, len(values))
+	for _, v := range values {
+		parts := strings.SplitN(v, ";", 3)
+		spec := attachSpec{path: strings.TrimSpace(parts[0])}
+		if spec.path == "" {
+			return nil, fmt.Errorf("invalid attachment '%s': expected path[;description[;content-type]]", v)
+		}
+		if len(parts) > 1 {
+			spec.description = strings.TrimSpace(parts[1])
+		}
+		if len(parts) > 2 {
+			spec.contentType = strings.TrimSpace(parts[2])
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// uploadAttachments はファイルをアップロードし、チケットに添付するためのトークンを返す。
+// 途中で失敗して無駄なアップロードが残らないよう、先に全ファイルを確認する。
+func uploadAttachments(ctx context.Context, client redmine.API, specs []attachSpec) ([]redmine.Upload, error) {
+	for _, spec := range specs {
+		info, err := os.Stat(spec.path)
+		if err != nil {
+			return nil, fmt.Errorf("cannot attach %s: %w", spec.path, err)
+		}
+		if !info.Mode().IsRegular() {
+			return nil, fmt.Errorf("cannot attach %s: not a regular file", spec.path)
+		}
+	}
+
+	var uploads []redmine.Upload
+	for _, spec := range specs {
+		upload, err := uploadFile(ctx, client, spec)
+		if err != nil {
+			return nil, err
+		}
+		uploads = append(uploads, *upload)
+	}
+	return uploads, nil
+}
+
+func uploadFile(ctx context.Context, client redmine.API, spec attachSpec) (*redmine.Upload, error) {
+	f, err := os.Open(spec.path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot attach %s: %w", spec.path, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("cannot attach %s: %w", spec.path, err)
+	}
+
+	name := filepath.Base(spec.path)
+	token, err := client.UploadContext(ctx, name, f, info.Size())
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := spec.contentType
+	if contentType == "" {
+		// "text/plain; charset=utf-8" のようなパラメータは付けない
+		contentType, _, _ = strings.Cut(mime.TypeByExtension(filepath.Ext(name)), ";")
+	}
+	return &redmine.Upload{
+		Token:       token,
+		Filename:    name,
+		Description: spec.description,
+		ContentType: contentType,
+	}, nil
+}
+
+// attachFlagUsage は create / update / comment 共通の --attach フラグの説明
+const attachFlagUsage = `Attach a file (repeatable, format: path[;description[;content-type]])`
+
+// uploadAttachFlag は --attach で指定されたファイルをアップロードする。指定がなければ nil を返す。
+func uploadAttachFlag(cmd *cobra.Command, client redmine.API) ([]redmine.Upload, error) {
+	values, _ := cmd.Flags().GetStringArray("attach")
+	if len(values) == 0 {
+		return nil, nil
+	}
+	specs, err := parseAttachSpecs(values)
+	if err != nil {
+		return nil, err
+	}
+	return uploadAttachments(cmd.Context(), client, specs)
+}
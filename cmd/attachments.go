@@ -0,0 +1,406 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+	"github.com/spf13/cobra"
+)
+
+func newAttachmentsCmd(a *app) *cobra.Command {
+	attachmentsCmd := &cobra.Command{
+		Use:   "attachments <issue-id>",
+		Short: "List files attached to a Redmine issue",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			issueID, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid issue ID: %s", args[0])
+			}
+
+			client, err := a.client(cmd)
+			if err != nil {
+				return err
+			}
+
+			attachments, err := client.ListAttachmentsContext(cmd.Context(), issueID)
+			if err != nil {
+				return fmt.Errorf("failed to list attachments: %w", err)
+			}
+
+			if jsonFlag, _ := cmd.Root().Flags().GetBool("json"); jsonFlag {
+				return printJSON(a.stdout, attachments)
+			}
+
+			w := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tFilename\tSize\tType\tAuthor\tCreated")
+			fmt.Fprintln(w, strings.Repeat("-", 80))
+			for _, att := range attachments {
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n",
+					att.ID,
+					att.Filename,
+					formatSize(att.Filesize),
+					att.ContentType,
+					att.Author.Name,
+					att.CreatedOn.Format("2006-01-02 15:04"),
+				)
+			}
+			return w.Flush()
+		},
+	}
+
+	return attachmentsCmd
+}
+
+func newDownloadCmd(a *app) *cobra.Command {
+	downloadCmd := &cobra.Command{
+		Use:   "download <issue-id>",
+		Short: "Download files attached to a Redmine issue",
+		Long: `Download the attachments of an issue in parallel.
+Files already present with a matching digest are skipped, interrupted downloads
+resume from their .part file, and every file is checked against its digest.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			issueID, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid issue ID: %s", args[0])
+			}
+			pattern, _ := cmd.Flags().GetString("name")
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid --name pattern '%s': %w", pattern, err)
+			}
+			dir, _ := cmd.Flags().GetString("output")
+			parallel, _ := cmd.Flags().GetInt("parallel")
+			force, _ := cmd.Flags().GetBool("force")
+			quiet, _ := cmd.Root().Flags().GetBool("quiet")
+
+			client, err := a.client(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			attachments, err := client.ListAttachmentsContext(ctx, issueID)
+			if err != nil {
+				return fmt.Errorf("failed to list attachments: %w", err)
+			}
+
+			jobs := downloadJobs(attachments, pattern, dir)
+			if len(jobs) == 0 {
+				return fmt.Errorf("no attachments matching '%s' on issue #%d", pattern, issueID)
+			}
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				return err
+			}
+
+			d := &downloader{app: a, client: client, force: force}
+			for _, job := range jobs {
+				d.total += job.att.Filesize
+			}
+			if !quiet && isTerminal(a.stderr) {
+				stop := d.showProgress(len(jobs))
+				defer stop()
+			}
+			return d.run(ctx, jobs, parallel)
+		},
+	}
+
+	downloadCmd.Flags().String("name", "*", "Only download files whose name matches this glob")
+	downloadCmd.Flags().StringP("output", "o", ".", "Directory to save files in")
+	downloadCmd.Flags().Int("parallel", 4, "Files downloaded in parallel")
+	downloadCmd.Flags().Bool("force", false, "Download again even if the file is already present")
+	return downloadCmd
+}
+
+type downloadJob struct {
+	att  redmine.Attachment
+	dest string
+}
+
+// downloadJobs は保存先を決める。同じ名前の添付が複数あれば ID を前に付けて区別する。
+func downloadJobs(attachments []redmine.Attachment, pattern, dir string) []downloadJob {
+	counts := map[string]int{}
+	for _, att := range attachments {
+		counts[att.Filename]++
+	}
+
+	var jobs []downloadJob
+	for _, att := range attachments {
+		if ok, _ := path.Match(pattern, att.Filename); !ok {
+			continue
+		}
+		// サーバーが返すファイル名でディレクトリの外に書き込まないようにする
+		name := filepath.Base(filepath.FromSlash(att.Filename))
+		if name == "." || name == ".." || name == string(filepath.Separator) {
+			name = fmt.Sprintf("attachment-%d", att.ID)
+		}
+		if counts[att.Filename] > 1 {
+			name = fmt.Sprintf("%d_%s", att.ID, name)
+		}
+		jobs = append(jobs, downloadJob{att: att, dest: filepath.Join(dir, name)})
+	}
+	return jobs
+}
+
+type downloader struct {
+	*app
+	client redmine.API
+	force  bool
+
+	total int64
+	done  atomic.Int64
+
+	mu       sync.Mutex
+	progress bool
+}
+
+func (d *downloader) run(ctx context.Context, jobs []downloadJob, parallel int) error {
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	queue := make(chan downloadJob)
+	var wg sync.WaitGroup
+	var errMu sync.Mutex
+	var errs []error
+	for i := 0; i < parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				if err := d.download(ctx, job); err != nil {
+					errMu.Lock()
+					errs = append(errs, fmt.Errorf("%s: %w", job.att.Filename, err))
+					errMu.Unlock()
+				}
+			}
+		}()
+	}
+	for _, job := range jobs {
+		if ctx.Err() != nil {
+			break
+		}
+		queue <- job
+	}
+	close(queue)
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		errs = append(errs, ctx.Err())
+	}
+	return errors.Join(errs...)
+}
+
+func (d *downloader) download(ctx context.Context, job downloadJob) error {
+	att := job.att
+
+	// 既に同じ内容のファイルがあれば取得しない
+	if !d.force {
+		if same, err := sameContent(job.dest, &att); err != nil {
+			return err
+		} else if same {
+			d.done.Add(att.Filesize)
+			d.report("Skipped %s (already downloaded)\n", job.dest)
+			return nil
+		}
+	}
+
+	// 途中まで取得した .part があれば続きから取得する
+	part := job.dest + ".part"
+	var offset int64
+	if info, err := os.Stat(part); err == nil && !d.force {
+		offset = info.Size()
+	}
+
+	start, err := d.fetch(ctx, &att, part, offset)
+	if err != nil {
+		return err
+	}
+	if err := verifyFile(part, &att); err != nil {
+		if start == 0 {
+			os.Remove(part)
+			return err
+		}
+		// 再開元の .part が壊れていた可能性があるので、最初から取り直す
+		if info, statErr := os.Stat(part); statErr == nil {
+			d.done.Add(-info.Size())
+		}
+		if start, err = d.fetch(ctx, &att, part, 0); err != nil {
+			return err
+		}
+		if err := verifyFile(part, &att); err != nil {
+			os.Remove(part)
+			return err
+		}
+	}
+	if err := os.Rename(part, job.dest); err != nil {
+		return err
+	}
+	if start > 0 {
+		d.report("Downloaded %s (%s, resumed at %s)\n", job.dest, formatSize(att.Filesize), formatSize(start))
+	} else {
+		d.report("Downloaded %s (%s)\n", job.dest, formatSize(att.Filesize))
+	}
+	return nil
+}
+
+// fetch は offset から part に書き込み、実際に書き始めた位置を返す。
+func (d *downloader) fetch(ctx context.Context, att *redmine.Attachment, part string, offset int64) (int64, error) {
+	body, start, err := d.client.OpenAttachmentContext(ctx, att, offset)
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if start > 0 {
+		flags = os.O_WRONLY | os.O_APPEND
+	}
+	f, err := os.OpenFile(part, flags, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	d.done.Add(start)
+	_, copyErr := io.Copy(f, &countingReader{r: body, n: &d.done})
+	if err := f.Close(); err != nil && copyErr == nil {
+		copyErr = err
+	}
+	// 失敗しても .part は残して次回再開できるようにする
+	return start, copyErr
+}
+
+// sameContent は dest が既に添付ファイルと同じ内容かを digest（なければサイズ）で判定する。
+func sameContent(dest string, att *redmine.Attachment) (bool, error) {
+	info, err := os.Stat(dest)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if info.Size() != att.Filesize {
+		return false, nil
+	}
+	if redmine.NewDigest(att.Digest) == nil {
+		return true, nil
+	}
+	return verifyFile(dest, att) == nil, nil
+}
+
+// verifyFile はサイズと digest を確認する。
+func verifyFile(file string, att *redmine.Attachment) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h := redmine.NewDigest(att.Digest)
+	var w io.Writer = io.Discard
+	if h != nil {
+		w = h
+	}
+	n, err := io.Copy(w, f)
+	if err != nil {
+		return err
+	}
+	if att.Filesize > 0 && n != att.Filesize {
+		return fmt.Errorf("size mismatch: got %d bytes, expected %d", n, att.Filesize)
+	}
+	if h != nil && !redmine.DigestMatches(h, att.Digest) {
+		return fmt.Errorf("digest mismatch (expected %s)", att.Digest)
+	}
+	return nil
+}
+
+// report は進捗表示の行を消してから結果を出力する。
+func (d *downloader) report(format string, args ...interface{}) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.progress {
+		fmt.Fprint(d.stderr, "\r\033[K")
+	}
+	fmt.Fprintf(d.stdout, format, args...)
+}
+
+// showProgress は全体の進捗を stderr に表示し続け、停止用の関数を返す。
+func (d *downloader) showProgress(files int) func() {
+	d.progress = true
+	ticker := time.NewTicker(200 * time.Millisecond)
+	stopped := make(chan struct{})
+	finished := make(chan struct{})
+	render := func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		done := d.done.Load()
+		percent := 100
+		if d.total > 0 {
+			percent = int(done * 100 / d.total)
+		}
+		fmt.Fprintf(d.stderr, "\r\033[KDownloading %d file(s): %s / %s (%d%%)", files, formatSize(done), formatSize(d.total), percent)
+	}
+	go func() {
+		defer close(finished)
+		for {
+			select {
+			case <-ticker.C:
+				render()
+			case <-stopped:
+				return
+			}
+		}
+	}()
+	return func() {
+		ticker.Stop()
+		close(stopped)
+		<-finished
+		render()
+		fmt.Fprintln(d.stderr)
+	}
+}
+
+type countingReader struct {
+	r io.Reader
+	n *atomic.Int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n.Add(int64(n))
+	return n, err
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// formatSize はバイト数を読みやすい単位で表す。
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
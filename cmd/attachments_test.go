@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+)
+
+// attachmentFixture は data.bin を添付したチケット #1 と、ダウンロードを記録するクライアントを用意する。
+func attachmentFixture(t *testing.T) (*redmine.Client, *requestLog, []byte, string) {
+	t.Helper()
+	srv := newTestServer(t)
+	client := srv.Client()
+	content := bytes.Repeat([]byte("0123456789"), 10000)
+	token, err := client.Upload("data.bin", bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	project, _ := client.GetProject("demo")
+	if _, err := client.CreateIssue(&redmine.IssueCreate{ProjectID: project.ID, Subject: "Files",
+		Uploads: []redmine.Upload{{Token: token, Filename: "data.bin"}}}); err != nil {
+		t.Fatal(err)
+	}
+	return client, logRequests(client, "/attachments/download/"), content, t.TempDir()
+}
+
+func TestDownloadResumesPartialFile(t *testing.T) {
+	client, log, content, dir := attachmentFixture(t)
+	writeFile(t, filepath.Join(dir, "data.bin.part"), string(content[:40000]))
+
+	out := mustRun(t, client, "download", "1", "-o", dir)
+	if !strings.Contains(out, "resumed at") {
+		t.Errorf("output = %q, want a resumed download", out)
+	}
+	if len(log.header("Range")) != 1 || log.header("Range")[0] != "bytes=40000-" {
+		t.Errorf("download requests = %q, want one from byte 40000", log.header("Range"))
+	}
+	assertDownloaded(t, dir, content)
+}
+
+func TestDownloadRefetchesCorruptedPartialFile(t *testing.T) {
+	client, log, content, dir := attachmentFixture(t)
+	writeFile(t, filepath.Join(dir, "data.bin.part"), strings.Repeat("?", 40000))
+
+	out := mustRun(t, client, "download", "1", "-o", dir)
+	if strings.Contains(out, "resumed") {
+		t.Errorf("output = %q, want a fresh download", out)
+	}
+	if len(log.header("Range")) != 2 || log.header("Range")[0] != "bytes=40000-" || log.header("Range")[1] != "" {
+		t.Errorf("download requests = %q, want a resume and then a full download", log.header("Range"))
+	}
+	assertDownloaded(t, dir, content)
+}
+
+// .part が既に完全なら 416 が返り、取得し直さずに完了する。
+func TestDownloadCompletesFinishedPartialFile(t *testing.T) {
+	client, log, content, dir := attachmentFixture(t)
+	writeFile(t, filepath.Join(dir, "data.bin.part"), string(content))
+
+	mustRun(t, client, "download", "1", "-o", dir)
+	if len(log.header("Range")) != 1 {
+		t.Errorf("download requests = %q, want one", log.header("Range"))
+	}
+	assertDownloaded(t, dir, content)
+}
+
+func TestDownloadSkipsFileWithMatchingDigest(t *testing.T) {
+	client, log, content, dir := attachmentFixture(t)
+	writeFile(t, filepath.Join(dir, "data.bin"), string(content))
+
+	if out := mustRun(t, client, "download", "1", "-o", dir); !strings.Contains(out, "Skipped") {
+		t.Errorf("output = %q, want the file skipped", out)
+	}
+	if len(log.header("Range")) != 0 {
+		t.Errorf("download requests = %q, want none", log.header("Range"))
+	}
+
+	// 同じサイズでも内容が違えば取り直す
+	writeFile(t, filepath.Join(dir, "data.bin"), strings.Repeat("?", len(content)))
+	if out := mustRun(t, client, "download", "1", "-o", dir); !strings.Contains(out, "Downloaded") {
+		t.Errorf("output = %q, want the file downloaded again", out)
+	}
+	assertDownloaded(t, dir, content)
+}
+
+func assertDownloaded(t *testing.T, dir string, content []byte) {
+	t.Helper()
+	got, err := os.ReadFile(filepath.Join(dir, "data.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("data.bin has %d bytes, want the %d uploaded", len(got), len(content))
+	}
+	if _, err := os.Stat(filepath.Join(dir, "data.bin.part")); !os.IsNotExist(err) {
+		t.Errorf("data.bin.part was left behind")
+	}
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+	"github.com/spf13/cobra"
+)
+
+func newCacheCmd(a *app) *cobra.Command {
+	cacheCmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the local metadata cache",
+		Long: `Manage the on-disk cache of custom fields, trackers, statuses, time entry activities,
+versions and users.
+The cache lives under the user cache directory and is kept separately for each Redmine URL.`,
+	}
+
+	cacheCmd.AddCommand(newCacheClearCmd(a), newCacheRefreshCmd(a))
+	return cacheCmd
+}
+
+// cachedClient はキャッシュを持つ実クライアントを返す（フェイクの API ではキャッシュは使えない）
+func (a *app) cachedClient(cmd *cobra.Command) (*redmine.Client, error) {
+	api, err := a.client(cmd)
+	if err != nil {
+		return nil, err
+	}
+	client, ok := api.(*redmine.Client)
+	if !ok || client.Cache == nil {
+		return nil, fmt.Errorf("cache is disabled")
+	}
+	return client, nil
+}
+
+func newCacheClearCmd(a *app) *cobra.Command {
+	cacheClearCmd := &cobra.Command{
+		Use:   "clear",
+		Short: "Remove cached metadata",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if all, _ := cmd.Flags().GetBool("all"); all {
+				root, err := redmine.CacheRoot()
+				if err != nil {
+					return err
+				}
+				if err := os.RemoveAll(root); err != nil {
+					return fmt.Errorf("failed to clear cache: %w", err)
+				}
+				fmt.Fprintln(a.stdout, "Cache cleared for all Redmine instances")
+				return nil
+			}
+
+			client, err := a.cachedClient(cmd)
+			if err != nil {
+				return err
+			}
+			if err := client.Cache.Clear(); err != nil {
+				return fmt.Errorf("failed to clear cache: %w", err)
+			}
+			fmt.Fprintf(a.stdout, "Cache cleared for %s\n", client.BaseURL)
+			return nil
+		},
+	}
+
+	cacheClearCmd.Flags().Bool("all", false, "Clear the cache of every Redmine instance")
+	return cacheClearCmd
+}
+
+func newCacheRefreshCmd(a *app) *cobra.Command {
+	cacheRefreshCmd := &cobra.Command{
+		Use:   "refresh",
+		Short: "Fetch metadata again and update the cache",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := a.cachedClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := redmine.WithCacheRefresh(cmd.Context())
+
+			// 権限によっては取得できないリソースもあるので、失敗しても続行する
+			refresh := func(name string, fn func() error) {
+				if err := fn(); err != nil {
+					fmt.Fprintf(a.stderr, "warning: %s: %v\n", name, err)
+					return
+				}
+				fmt.Fprintf(a.stdout, "Refreshed %s\n", name)
+			}
+			refresh("custom fields", func() error {
+				_, err := client.ListCustomFieldsContext(ctx)
+				return err
+			})
+			refresh("trackers", func() error {
+				_, err := client.ListTrackersContext(ctx)
+				return err
+			})
+			refresh("issue statuses", func() error {
+				_, err := client.ListIssueStatusesContext(ctx)
+				return err
+			})
+			refresh("issue priorities", func() error {
+				_, err := client.ListIssuePrioritiesContext(ctx)
+				return err
+			})
+			refresh("time entry activities", func() error {
+				_, err := client.ListTimeEntryActivitiesContext(ctx)
+				return err
+			})
+			refresh("current user", func() error {
+				_, err := client.GetCurrentUserContext(ctx)
+				return err
+			})
+			if project, _ := cmd.Flags().GetString("project"); project != "" {
+				refresh("versions of "+project, func() error {
+					_, err := client.ListVersionsContext(ctx, project)
+					return err
+				})
+				refresh("issue categories of "+project, func() error {
+					_, err := client.ListIssueCategoriesContext(ctx, project)
+					return err
+				})
+			}
+			return nil
+		},
+	}
+
+	cacheRefreshCmd.Flags().String("project", "", "Also refresh versions and issue categories of this project")
+	return cacheRefreshCmd
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+)
+
+func TestCacheRefreshAndClear(t *testing.T) {
+	srv := newTestServer(t)
+	srv.AddVersion("demo", "v1.0")
+	client := srv.Client()
+	client.Cache = &redmine.Cache{Dir: t.TempDir(), TTL: redmine.DefaultCacheTTLs()}
+
+	out := mustRun(t, client, "cache", "refresh", "--project", "demo")
+	for _, want := range []string{"Refreshed trackers", "Refreshed current user", "Refreshed versions of demo"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("refresh output lacks %q:\n%s", want, out)
+		}
+	}
+	if entries, _ := os.ReadDir(client.Cache.Dir); len(entries) == 0 {
+		t.Fatal("refresh wrote nothing to the cache")
+	}
+
+	out = mustRun(t, client, "cache", "clear")
+	if want := "Cache cleared for " + client.BaseURL; !strings.Contains(out, want) {
+		t.Errorf("clear output = %q, want %q", out, want)
+	}
+	if entries, _ := os.ReadDir(client.Cache.Dir); len(entries) != 0 {
+		t.Errorf("%d cache entries left after clear", len(entries))
+	}
+}
+
+func TestCacheCommandsNeedCache(t *testing.T) {
+	_, err := runRD(t, newTestServer(t).Client(), "cache", "clear")
+	if err == nil || !strings.Contains(err.Error(), "cache is disabled") {
+		t.Errorf("err = %v, want cache is disabled", err)
+	}
+}
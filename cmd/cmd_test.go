@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+	"github.com/ikasamt/rd/pkg/redmine/redminetest"
+)
+
+// newTestServer starts a fake Redmine with a "demo" project.
+func newTestServer(t *testing.T) *redminetest.Server {
+	t.Helper()
+	srv := redminetest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.AddProject("demo", "Demo")
+	return srv
+}
+
+// runRD runs rd with args against api and returns what it wrote to stdout.
+func runRD(t *testing.T, api redmine.API, args ...string) (string, error) {
+	t.Helper()
+	var out, errOut bytes.Buffer
+	root := NewRootCmd(api, &out, &errOut)
+	root.SetArgs(args)
+	err := root.Execute()
+	return out.String(), err
+}
+
+// mustRun is runRD for commands that must succeed.
+func mustRun(t *testing.T, api redmine.API, args ...string) string {
+	t.Helper()
+	out, err := runRD(t, api, args...)
+	if err != nil {
+		t.Fatalf("rd %v: %v", args, err)
+	}
+	return out
+}
+
+// writeFile writes text to path.
+func writeFile(t *testing.T, path, text string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readFile returns the contents of path.
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// requestLog records the requests a client sends to paths starting with prefix.
+type requestLog struct {
+	next   http.RoundTripper
+	prefix string
+
+	mu       sync.Mutex
+	requests []*http.Request
+}
+
+// logRequests makes client record its requests to paths starting with prefix.
+func logRequests(client *redmine.Client, prefix string) *requestLog {
+	l := &requestLog{next: client.HTTPClient.Transport, prefix: prefix}
+	client.HTTPClient.Transport = l
+	return l
+}
+
+func (l *requestLog) RoundTrip(req *http.Request) (*http.Response, error) {
+	if strings.HasPrefix(req.URL.Path, l.prefix) {
+		l.mu.Lock()
+		l.requests = append(l.requests, req)
+		l.mu.Unlock()
+	}
+	return l.next.RoundTrip(req)
+}
+
+// header returns the value of header name in every recorded request.
+func (l *requestLog) header(name string) []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	values := []string{}
+	for _, req := range l.requests {
+		values = append(values, req.Header.Get(name))
+	}
+	return values
+}
+
+// query returns the value of parameter name in the last recorded request.
+func (l *requestLog) query(name string) string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.requests) == 0 {
+		return ""
+	}
+	return l.requests[len(l.requests)-1].URL.Query().Get(name)
+}
@@ -2,44 +2,57 @@ package cmd
 
 import (
 	"bufio"
-	"encoding/json"
+	"context"
 	"fmt"
-	"os"
+	"io"
 	"strconv"
 
-	"github.com/ikasamt/rd/pkg/config"
 	"github.com/ikasamt/rd/pkg/redmine"
 	"github.com/spf13/cobra"
 )
 
-var createCmd = &cobra.Command{
-	Use:   "create",
-	Short: "Create a new Redmine issue",
-	Long:  `Create a new issue in Redmine with various options or interactive mode.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		urlFlag, _ := cmd.Root().Flags().GetString("url")
-		keyFlag, _ := cmd.Root().Flags().GetString("key")
-		debugFlag, _ := cmd.Root().Flags().GetBool("debug")
-		
-		cfg, err := config.Load(urlFlag, keyFlag)
-		if err != nil {
-			return err
-		}
+func newCreateCmd(a *app) *cobra.Command {
+	createCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a new Redmine issue",
+		Long:  `Create a new issue in Redmine with various options or interactive mode.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := a.client(cmd)
+			if err != nil {
+				return err
+			}
 
-		client := redmine.NewClient(cfg.RedmineURL, cfg.APIKey)
-		client.Debug = debugFlag
+			interactive, _ := cmd.Flags().GetBool("interactive")
+			if interactive {
+				return a.createIssueInteractive(cmd.Context(), cmd.InOrStdin(), client)
+			}
 
-		interactive, _ := cmd.Flags().GetBool("interactive")
-		if interactive {
-			return createIssueInteractive(client)
-		}
+			// コマンドラインオプションから作成
+			return a.createIssueFromFlags(cmd, client)
+		},
+	}
 
-		// コマンドラインオプションから作成
-		return createIssueFromFlags(cmd, client)
-	},
+	createCmd.Flags().String("title", "", "Issue title")
+	createCmd.Flags().String("description", "", "Issue description")
+	createCmd.Flags().String("project", "", "Project ID or identifier")
+	createCmd.Flags().String("assignee", "", "Assignee (name, ID, 'me' or, with an admin key, login)")
+	createCmd.Flags().String("tracker", "", "Tracker name or ID")
+	createCmd.Flags().String("priority", "", "Priority name or ID")
+	createCmd.Flags().String("status", "", "Status name or ID")
+	createCmd.Flags().String("category", "", "Issue category name or ID")
+	createCmd.Flags().Int("parent", 0, "Parent issue ID")
+	createCmd.Flags().String("start-date", "", "Start date (YYYY-MM-DD)")
+	createCmd.Flags().String("due-date", "", "Due date (YYYY-MM-DD)")
+	createCmd.Flags().StringArray("field", nil, "Custom field (format: name=value, comma-separated for multi-value fields; repeatable)")
+	createCmd.Flags().StringArray("attach", nil, attachFlagUsage)
+	createCmd.Flags().StringArray("watcher", nil, "Watcher name, ID or, with an admin key, login (repeatable)")
+	createCmd.Flags().Bool("interactive", false, "Interactive mode")
+	return createCmd
 }
 
-func createIssueFromFlags(cmd *cobra.Command, client *redmine.Client) error {
+func (a *app) createIssueFromFlags(cmd *cobra.Command, client redmine.API) error {
+	ctx := cmd.Context()
+
 	title, _ := cmd.Flags().GetString("title")
 	if title == "" {
 		return fmt.Errorf("title is required (use --title or --interactive)")
@@ -51,7 +64,7 @@ func createIssueFromFlags(cmd *cobra.Command, client *redmine.Client) error {
 	}
 
 	// プロジェクトIDの取得
-	project, err := client.GetProject(projectID)
+	project, err := client.GetProjectContext(ctx, projectID)
 	if err != nil {
 		return fmt.Errorf("failed to get project: %w", err)
 	}
@@ -67,21 +80,46 @@ func createIssueFromFlags(cmd *cobra.Command, client *redmine.Client) error {
 		issue.Description = description
 	}
 
+	// 担当者・トラッカー・優先度・ステータス・カテゴリは名前でも ID でも指定できる
 	assignee, _ := cmd.Flags().GetString("assignee")
 	if assignee != "" {
-		if id, err := strconv.Atoi(assignee); err == nil {
-			issue.AssignedToID = id
+		user, err := client.ResolveUserContext(ctx, projectID, assignee)
+		if err != nil {
+			return err
 		}
+		issue.AssignedToID = user.ID
 	}
 
-	trackerID, _ := cmd.Flags().GetInt("tracker")
-	if trackerID > 0 {
-		issue.TrackerID = trackerID
+	if tracker, _ := cmd.Flags().GetString("tracker"); tracker != "" {
+		t, err := client.ResolveTrackerContext(ctx, tracker)
+		if err != nil {
+			return err
+		}
+		issue.TrackerID = t.ID
 	}
 
-	priorityID, _ := cmd.Flags().GetInt("priority")
-	if priorityID > 0 {
-		issue.PriorityID = priorityID
+	if priority, _ := cmd.Flags().GetString("priority"); priority != "" {
+		p, err := client.ResolvePriorityContext(ctx, priority)
+		if err != nil {
+			return err
+		}
+		issue.PriorityID = p.ID
+	}
+
+	if status, _ := cmd.Flags().GetString("status"); status != "" {
+		st, err := client.ResolveStatusContext(ctx, status)
+		if err != nil {
+			return err
+		}
+		issue.StatusID = st.ID
+	}
+
+	if category, _ := cmd.Flags().GetString("category"); category != "" {
+		c, err := client.ResolveCategoryContext(ctx, projectID, category)
+		if err != nil {
+			return err
+		}
+		issue.CategoryID = c.ID
 	}
 
 	parentID, _ := cmd.Flags().GetInt("parent")
@@ -89,11 +127,6 @@ func createIssueFromFlags(cmd *cobra.Command, client *redmine.Client) error {
 		issue.ParentIssueID = parentID
 	}
 
-	statusID, _ := cmd.Flags().GetInt("status")
-	if statusID > 0 {
-		issue.StatusID = statusID
-	}
-
 	startDate, _ := cmd.Flags().GetString("start-date")
 	if startDate != "" {
 		issue.StartDate = startDate
@@ -105,9 +138,9 @@ func createIssueFromFlags(cmd *cobra.Command, client *redmine.Client) error {
 	}
 
 	// カスタムフィールド
-	fields, _ := cmd.Flags().GetStringSlice("field")
+	fields, _ := cmd.Flags().GetStringArray("field")
 	if len(fields) > 0 {
-		customFields, err := resolveCustomFields(client, fields)
+		customFields, err := resolveCustomFields(ctx, client, strconv.Itoa(project.ID), 0, fields)
 		if err != nil {
 			return err
 		}
@@ -116,8 +149,26 @@ func createIssueFromFlags(cmd *cobra.Command, client *redmine.Client) error {
 		}
 	}
 
+	// ウォッチャー
+	if watchers, _ := cmd.Flags().GetStringArray("watcher"); len(watchers) > 0 {
+		users, err := resolveUsers(ctx, client, strconv.Itoa(project.ID), watchers)
+		if err != nil {
+			return err
+		}
+		for _, u := range users {
+			issue.WatcherUserIDs = append(issue.WatcherUserIDs, u.ID)
+		}
+	}
+
+	// 添付ファイル
+	uploads, err := uploadAttachFlag(cmd, client)
+	if err != nil {
+		return err
+	}
+	issue.Uploads = uploads
+
 	// チケット作成
-	created, err := client.CreateIssue(issue)
+	created, err := client.CreateIssueContext(ctx, issue)
 	if err != nil {
 		return fmt.Errorf("failed to create issue: %w", err)
 	}
@@ -125,31 +176,29 @@ func createIssueFromFlags(cmd *cobra.Command, client *redmine.Client) error {
 	// 出力
 	jsonFlag, _ := cmd.Root().Flags().GetBool("json")
 	if jsonFlag {
-		encoder := json.NewEncoder(os.Stdout)
-		encoder.SetIndent("", "  ")
-		return encoder.Encode(created)
+		return printJSON(a.stdout, created)
 	}
 
-	fmt.Printf("Issue #%d created successfully\n", created.ID)
-	fmt.Printf("URL: %s/issues/%d\n", client.BaseURL, created.ID)
+	fmt.Fprintf(a.stdout, "Issue #%d created successfully\n", created.ID)
+	fmt.Fprintf(a.stdout, "URL: %s\n", client.IssueURL(created.ID))
 	return nil
 }
 
-func createIssueInteractive(client *redmine.Client) error {
-	scanner := bufio.NewScanner(os.Stdin)
+func (a *app) createIssueInteractive(ctx context.Context, in io.Reader, client redmine.API) error {
+	scanner := bufio.NewScanner(in)
 
 	// プロジェクト選択
-	projects, err := client.ListProjects()
+	projects, err := client.ListProjectsContext(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to list projects: %w", err)
 	}
 
-	fmt.Println("Available projects:")
+	fmt.Fprintln(a.stdout, "Available projects:")
 	for i, p := range projects.Projects {
-		fmt.Printf("%d. %s\n", i+1, p.Name)
+		fmt.Fprintf(a.stdout, "%d. %s\n", i+1, p.Name)
 	}
 
-	fmt.Print("\nSelect project number: ")
+	fmt.Fprint(a.stdout, "\nSelect project number: ")
 	scanner.Scan()
 	projectNum, err := strconv.Atoi(scanner.Text())
 	if err != nil || projectNum < 1 || projectNum > len(projects.Projects) {
@@ -158,7 +207,7 @@ func createIssueInteractive(client *redmine.Client) error {
 	selectedProject := projects.Projects[projectNum-1]
 
 	// タイトル入力
-	fmt.Print("\nIssue title: ")
+	fmt.Fprint(a.stdout, "\nIssue title: ")
 	scanner.Scan()
 	title := scanner.Text()
 	if title == "" {
@@ -166,7 +215,7 @@ func createIssueInteractive(client *redmine.Client) error {
 	}
 
 	// 説明入力
-	fmt.Print("\nDescription (optional, press Enter to skip): ")
+	fmt.Fprint(a.stdout, "\nDescription (optional, press Enter to skip): ")
 	scanner.Scan()
 	description := scanner.Text()
 
@@ -177,29 +226,12 @@ func createIssueInteractive(client *redmine.Client) error {
 		Description: description,
 	}
 
-	created, err := client.CreateIssue(issue)
+	created, err := client.CreateIssueContext(ctx, issue)
 	if err != nil {
 		return fmt.Errorf("failed to create issue: %w", err)
 	}
 
-	fmt.Printf("\nIssue #%d created successfully\n", created.ID)
-	fmt.Printf("URL: %s/issues/%d\n", client.BaseURL, created.ID)
+	fmt.Fprintf(a.stdout, "\nIssue #%d created successfully\n", created.ID)
+	fmt.Fprintf(a.stdout, "URL: %s\n", client.IssueURL(created.ID))
 	return nil
 }
-
-func init() {
-	rootCmd.AddCommand(createCmd)
-
-	createCmd.Flags().String("title", "", "Issue title")
-	createCmd.Flags().String("description", "", "Issue description")
-	createCmd.Flags().String("project", "", "Project ID or identifier")
-	createCmd.Flags().String("assignee", "", "Assignee user ID")
-	createCmd.Flags().Int("tracker", 0, "Tracker ID")
-	createCmd.Flags().Int("priority", 0, "Priority ID")
-	createCmd.Flags().Int("status", 0, "Status ID")
-	createCmd.Flags().Int("parent", 0, "Parent issue ID")
-	createCmd.Flags().String("start-date", "", "Start date (YYYY-MM-DD)")
-	createCmd.Flags().String("due-date", "", "Due date (YYYY-MM-DD)")
-	createCmd.Flags().StringSlice("field", []string{}, "Custom field (format: name=value)")
-	createCmd.Flags().Bool("interactive", false, "Interactive mode")
-}
\ No newline at end of file
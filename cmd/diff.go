@@ -0,0 +1,293 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type diffOp struct {
+	kind byte // ' ', '-', '+'
+	text string
+}
+
+// splitLines は改行コードを揃えて行に分ける。
+func splitLines(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffLines は最長共通部分列で a から b への行単位の差分を求める。
+func diffLines(a, b []string) []diffOp {
+	// 共通の先頭・末尾を除いてから比べる
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	ops = lcsOps(ops, a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops
+}
+
+// lcsOps は x から y への操作を ops に追加する。Hirschberg の方法で x を半分ずつに
+// 分けて求めるので、大きなページでも (len(x)+1)×(len(y)+1) の表は作らず、
+// メモリは len(y) に比例する。
+func lcsOps(ops []diffOp, x, y []string) []diffOp {
+	switch {
+	case len(x) == 0:
+		for _, line := range y {
+			ops = append(ops, diffOp{'+', line})
+		}
+		return ops
+	case len(y) == 0:
+		for _, line := range x {
+			ops = append(ops, diffOp{'-', line})
+		}
+		return ops
+	case len(x) == 1:
+		k := slices.Index(y, x[0])
+		if k < 0 {
+			ops = append(ops, diffOp{'-', x[0]})
+			return lcsOps(ops, nil, y)
+		}
+		ops = lcsOps(ops, nil, y[:k])
+		ops = append(ops, diffOp{' ', x[0]})
+		return lcsOps(ops, nil, y[k+1:])
+	}
+
+	// x の前半と y[:k]、後半と y[k:] の共通部分列が最長になる k で分ける
+	mid := len(x) / 2
+	fwd := lcsLengths(x[:mid], y, false)
+	bwd := lcsLengths(x[mid:], y, true)
+	split, best := 0, -1
+	for k := 0; k <= len(y); k++ {
+		if n := fwd[k] + bwd[len(y)-k]; n > best {
+			split, best = k, n
+		}
+	}
+	ops = lcsOps(ops, x[:mid], y[:split])
+	return lcsOps(ops, x[mid:], y[split:])
+}
+
+// lcsLengths は row[j] = (x と y[:j] の最長共通部分列の長さ) を返す。
+// reverse なら両方を末尾から見るので、row[j] は x と y[len(y)-j:] の長さになる。
+func lcsLengths(x, y []string, reverse bool) []int {
+	at := func(s []string, i int) string {
+		if reverse {
+			return s[len(s)-1-i]
+		}
+		return s[i]
+	}
+	prev, cur := make([]int, len(y)+1), make([]int, len(y)+1)
+	for i := range x {
+		for j := range y {
+			if at(x, i) == at(y, j) {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(cur[j], prev[j+1])
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev
+}
+
+// unifiedDiff は diff -u 形式の差分を返す。差分がなければ空文字列を返す。
+func unifiedDiff(fromName, toName string, a, b []string, context int) string {
+	ops := diffLines(a, b)
+
+	// 各操作の直前までの行番号
+	aPos := make([]int, len(ops)+1)
+	bPos := make([]int, len(ops)+1)
+	var changes []int
+	for k, op := range ops {
+		aPos[k+1], bPos[k+1] = aPos[k], bPos[k]
+		if op.kind != '+' {
+			aPos[k+1]++
+		}
+		if op.kind != '-' {
+			bPos[k+1]++
+		}
+		if op.kind != ' ' {
+			changes = append(changes, k)
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromName, toName)
+	for c := 0; c < len(changes); {
+		// 近い変更はひとつのハンクにまとめる
+		last := c
+		for last+1 < len(changes) && changes[last+1]-changes[last] <= 2*context {
+			last++
+		}
+		start := changes[c] - context
+		if start < 0 {
+			start = 0
+		}
+		end := changes[last] + context + 1
+		if end > len(ops) {
+			end = len(ops)
+		}
+
+		aCount, bCount := aPos[end]-aPos[start], bPos[end]-bPos[start]
+		aStart, bStart := aPos[start], bPos[start]
+		if aCount > 0 {
+			aStart++
+		}
+		if bCount > 0 {
+			bStart++
+		}
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", aStart, aCount, bStart, bCount)
+		for _, op := range ops[start:end] {
+			sb.WriteByte(op.kind)
+			sb.WriteString(op.text)
+			sb.WriteByte('\n')
+		}
+		c = last + 1
+	}
+	return sb.String()
+}
+
+// diffHunk は base[start:end] を lines で置き換える変更
+type diffHunk struct {
+	start, end int
+	lines      []string
+}
+
+// diffHunks は base から other への差分を変更箇所ごとにまとめる。
+func diffHunks(base, other []string) []diffHunk {
+	var hunks []diffHunk
+	pos := 0
+	var cur *diffHunk
+	for _, op := range diffLines(base, other) {
+		if op.kind == ' ' {
+			if cur != nil {
+				hunks = append(hunks, *cur)
+				cur = nil
+			}
+			pos++
+			continue
+		}
+		if cur == nil {
+			cur = &diffHunk{start: pos, end: pos}
+		}
+		if op.kind == '-' {
+			pos++
+			cur.end = pos
+		} else {
+			cur.lines = append(cur.lines, op.text)
+		}
+	}
+	if cur != nil {
+		hunks = append(hunks, *cur)
+	}
+	return hunks
+}
+
+// applyHunks は base[lo:hi] に hunks を適用した行を返す。
+func applyHunks(base []string, lo, hi int, hunks []diffHunk) []string {
+	var out []string
+	pos := lo
+	for _, h := range hunks {
+		out = append(out, base[pos:h.start]...)
+		out = append(out, h.lines...)
+		pos = h.end
+	}
+	return append(out, base[pos:hi]...)
+}
+
+// merge3 は base に対する ours と theirs の変更をまとめる。両方が同じ箇所を
+// 違うように変えていれば、git と同じ形式の衝突マーカーで両方を残し、衝突の数を返す。
+func merge3(base, ours, theirs []string, oursName, theirsName string) ([]string, int) {
+	a, b := diffHunks(base, ours), diffHunks(base, theirs)
+
+	var merged []string
+	conflicts := 0
+	pos := 0
+	for len(a) > 0 || len(b) > 0 {
+		// 先に始まる変更から、範囲が重なる（接する）変更を両側から集める
+		var lo int
+		switch {
+		case len(b) == 0 || (len(a) > 0 && a[0].start <= b[0].start):
+			lo = a[0].start
+		default:
+			lo = b[0].start
+		}
+		hi := lo
+		na, nb := 0, 0
+		for {
+			if na < len(a) && a[na].start <= hi {
+				if a[na].end > hi {
+					hi = a[na].end
+				}
+				na++
+				continue
+			}
+			if nb < len(b) && b[nb].start <= hi {
+				if b[nb].end > hi {
+					hi = b[nb].end
+				}
+				nb++
+				continue
+			}
+			break
+		}
+
+		merged = append(merged, base[pos:lo]...)
+		switch {
+		case nb == 0:
+			merged = append(merged, applyHunks(base, lo, hi, a[:na])...)
+		case na == 0:
+			merged = append(merged, applyHunks(base, lo, hi, b[:nb])...)
+		default:
+			x, y := applyHunks(base, lo, hi, a[:na]), applyHunks(base, lo, hi, b[:nb])
+			if slices.Equal(x, y) {
+				merged = append(merged, x...)
+				break
+			}
+			conflicts++
+			merged = append(merged, "<<<<<<< "+oursName)
+			merged = append(merged, x...)
+			merged = append(merged, "=======")
+			merged = append(merged, y...)
+			merged = append(merged, ">>>>>>> "+theirsName)
+		}
+		pos = hi
+		a, b = a[na:], b[nb:]
+	}
+	return append(merged, base[pos:]...), conflicts
+}
+
+// hasConflictMarkers は merge3 の衝突マーカーが残っているかを調べる。
+func hasConflictMarkers(lines []string) bool {
+	var start, sep bool
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "<<<<<<< "):
+			start = true
+		case line == "=======" && start:
+			sep = true
+		case strings.HasPrefix(line, ">>>>>>> ") && sep:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"math/rand"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// opsString は差分を "-a +b  c" のように表す。
+func opsString(ops []diffOp) string {
+	var parts []string
+	for _, op := range ops {
+		parts = append(parts, string(op.kind)+op.text)
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{"identical", "a\nb\n", "a\nb\n", " a  b"},
+		{"insert", "a\nc\n", "a\nb\nc\n", " a +b  c"},
+		{"replace", "a\nb\nc\n", "a\nx\nc\n", " a -b +x  c"},
+		{"delete at start", "a\nb\nc\n", "b\nc\n", "-a  b  c"},
+		{"delete at end", "a\nb\nc\n", "a\nb\n", " a  b -c"},
+		{"everything changed", "a\nb\n", "x\ny\n", "-a -b +x +y"},
+		{"moved line", "a\nb\nc\n", "b\nc\na\n", "-a  b  c +a"},
+		{"from empty", "", "a\n", "+a"},
+		{"CRLF", "a\r\nb\r\n", "a\nb\nc\n", " a  b +c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := opsString(diffLines(splitLines(tt.a), splitLines(tt.b))); got != tt.want {
+				t.Errorf("diffLines = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// diffLines は両方の行を復元でき、共通行の数は表で求めた最長共通部分列と一致する。
+func TestDiffLinesIsMinimal(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	randomLines := func() []string {
+		lines := make([]string, rng.Intn(30))
+		for i := range lines {
+			lines[i] = string(rune('a' + rng.Intn(4)))
+		}
+		return lines
+	}
+	for n := 0; n < 500; n++ {
+		a, b := randomLines(), randomLines()
+		var gotA, gotB []string
+		common := 0
+		for _, op := range diffLines(a, b) {
+			if op.kind != '+' {
+				gotA = append(gotA, op.text)
+			}
+			if op.kind != '-' {
+				gotB = append(gotB, op.text)
+			}
+			if op.kind == ' ' {
+				common++
+			}
+		}
+		if !slices.Equal(gotA, a) || !slices.Equal(gotB, b) {
+			t.Fatalf("diffLines(%q, %q) does not reproduce its inputs", a, b)
+		}
+		if want := lcsTable(a, b); common != want {
+			t.Fatalf("diffLines(%q, %q) keeps %d lines, want %d", a, b, common, want)
+		}
+	}
+}
+
+func lcsTable(a, b []string) int {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	return lcs[0][0]
+}
+
+func TestDiffHunksAndApplyHunks(t *testing.T) {
+	tests := []struct {
+		name        string
+		base, other string
+		want        []diffHunk
+	}{
+		{"no change", "a\nb\n", "a\nb\n", nil},
+		{"replace", "a\nb\nc\n", "a\nx\nc\n", []diffHunk{{1, 2, []string{"x"}}}},
+		{"insert at start", "a\nb\n", "z\na\nb\n", []diffHunk{{0, 0, []string{"z"}}}},
+		{"delete at end", "a\nb\nc\n", "a\nb\n", []diffHunk{{2, 3, nil}}},
+		{"two changes", "a\nb\nc\nd\n", "A\nb\nc\nD\n", []diffHunk{{0, 1, []string{"A"}}, {3, 4, []string{"D"}}}},
+		{"CRLF", "a\r\nb\r\n", "a\nB\n", []diffHunk{{1, 2, []string{"B"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base, other := splitLines(tt.base), splitLines(tt.other)
+			hunks := diffHunks(base, other)
+			if !reflect.DeepEqual(hunks, tt.want) {
+				t.Errorf("diffHunks = %+v, want %+v", hunks, tt.want)
+			}
+			if got := applyHunks(base, 0, len(base), hunks); !slices.Equal(got, other) {
+				t.Errorf("applyHunks = %q, want %q", got, other)
+			}
+		})
+	}
+}
+
+func TestMerge3(t *testing.T) {
+	const base = "a\nb\nc\nd\ne\n"
+	tests := []struct {
+		name               string
+		base, ours, theirs string
+		want               string
+		wantConflicts      int
+	}{
+		{"clean", base, "a\nB\nc\nd\ne\n", "a\nb\nc\nD\ne\n", "a\nB\nc\nD\ne\n", 0},
+		{"only theirs", base, base, "a\nb\nC\nd\ne\n", "a\nb\nC\nd\ne\n", 0},
+		{"identical changes", base, "a\nB\nc\nd\ne\n", "a\nB\nc\nd\ne\n", "a\nB\nc\nd\ne\n", 0},
+		{"overlapping conflict", base, "a\nB1\nc\nd\ne\n", "a\nB2\nc\nd\ne\n",
+			"a\n<<<<<<< local\nB1\n=======\nB2\n>>>>>>> remote\nc\nd\ne\n", 1},
+		{"adjacent conflict", base, "a\nB\nc\nd\ne\n", "a\nb\nC\nd\ne\n",
+			"a\n<<<<<<< local\nB\nc\n=======\nb\nC\n>>>>>>> remote\nd\ne\n", 1},
+		{"two conflicts", base, "A1\nb\nc\nd\nE1\n", "A2\nb\nc\nd\nE2\n",
+			"<<<<<<< local\nA1\n=======\nA2\n>>>>>>> remote\nb\nc\nd\n<<<<<<< local\nE1\n=======\nE2\n>>>>>>> remote\n", 2},
+		{"deletions at start and end", base, "b\nc\nd\ne\n", "a\nb\nc\nd\n", "b\nc\nd\n", 0},
+		{"delete against edit", base, "b\nc\nd\ne\n", "A\nb\nc\nd\ne\n",
+			"<<<<<<< local\n=======\nA\n>>>>>>> remote\nb\nc\nd\ne\n", 1},
+		{"appends at the end", base, base + "x\n", base + "y\n",
+			"a\nb\nc\nd\ne\n<<<<<<< local\nx\n=======\ny\n>>>>>>> remote\n", 1},
+		{"CRLF", "a\r\nb\r\nc\r\nd\r\n", "a\nB\nc\nd\n", "a\r\nb\r\nc\r\nD\r\n", "a\nB\nc\nD\n", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, conflicts := merge3(splitLines(tt.base), splitLines(tt.ours), splitLines(tt.theirs), "local", "remote")
+			if got := strings.Join(merged, "\n") + "\n"; got != tt.want || conflicts != tt.wantConflicts {
+				t.Errorf("merge3 = %d conflicts\n%s\nwant %d\n%s", conflicts, got, tt.wantConflicts, tt.want)
+			}
+			if hasConflictMarkers(merged) != (conflicts > 0) {
+				t.Errorf("hasConflictMarkers = %v with %d conflicts", !(conflicts > 0), conflicts)
+			}
+		})
+	}
+}
@@ -1,124 +1,172 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
+	"io"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/ikasamt/rd/pkg/config"
 	"github.com/ikasamt/rd/pkg/redmine"
 	"github.com/spf13/cobra"
 )
 
-var getCmd = &cobra.Command{
-	Use:   "get <issue-id>",
-	Short: "Get a specific Redmine issue",
-	Long:  `Display detailed information about a specific Redmine issue.`,
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		issueID, err := strconv.Atoi(args[0])
-		if err != nil {
-			return fmt.Errorf("invalid issue ID: %s", args[0])
-		}
+func newGetCmd(a *app) *cobra.Command {
+	getCmd := &cobra.Command{
+		Use:   "get <issue-id>",
+		Short: "Get a specific Redmine issue",
+		Long:  `Display detailed information about a specific Redmine issue.`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			issueID, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid issue ID: %s", args[0])
+			}
 
-		urlFlag, _ := cmd.Root().Flags().GetString("url")
-		keyFlag, _ := cmd.Root().Flags().GetString("key")
-		debugFlag, _ := cmd.Root().Flags().GetBool("debug")
-		
-		cfg, err := config.Load(urlFlag, keyFlag)
-		if err != nil {
-			return err
-		}
+			client, err := a.client(cmd)
+			if err != nil {
+				return err
+			}
 
-		client := redmine.NewClient(cfg.RedmineURL, cfg.APIKey)
-		client.Debug = debugFlag
+			// 表示するものだけを含める
+			include := []string{redmine.IncludeChildren, redmine.IncludeAttachments, redmine.IncludeRelations, redmine.IncludeWatchers}
+			if noComments, _ := cmd.Flags().GetBool("no-comments"); !noComments {
+				include = append(include, redmine.IncludeJournals)
+			}
+			issue, err := client.GetIssueWithContext(cmd.Context(), issueID, include...)
+			if err != nil {
+				return fmt.Errorf("failed to get issue: %w", err)
+			}
 
-		noComments, _ := cmd.Flags().GetBool("no-comments")
-		issue, err := client.GetIssue(issueID, !noComments)
-		if err != nil {
-			return fmt.Errorf("failed to get issue: %w", err)
-		}
+			// 出力形式の判定
+			jsonFlag, _ := cmd.Root().Flags().GetBool("json")
+			if jsonFlag {
+				return printJSON(a.stdout, issue)
+			}
 
-		// 出力形式の判定
-		jsonFlag, _ := cmd.Root().Flags().GetBool("json")
-		if jsonFlag {
-			encoder := json.NewEncoder(os.Stdout)
-			encoder.SetIndent("", "  ")
-			return encoder.Encode(issue)
-		}
+			// 関連チケットの題名とステータスを表示するためにまとめて取得する（失敗しても番号だけ表示する）
+			related := map[int]redmine.Issue{}
+			if len(issue.Relations) > 0 {
+				filter := &redmine.IssueFilter{StatusID: "*"}
+				for _, r := range issue.Relations {
+					_, other := r.From(issue.ID)
+					filter.IssueIDs = append(filter.IssueIDs, other)
+				}
+				pager := client.IssuePaginator(filter)
+				if issues, err := pager.All(cmd.Context()); err == nil {
+					for _, ri := range issues {
+						related[ri.ID] = ri
+					}
+				}
+			}
+
+			return printIssueDetail(a.stdout, issue, related)
+		},
+	}
 
-		return printIssueDetail(issue)
-	},
+	getCmd.Flags().Bool("no-comments", false, "Exclude comments")
+	getCmd.Flags().Bool("fields", false, "Include custom fields")
+	return getCmd
 }
 
-func printIssueDetail(issue *redmine.Issue) error {
-	fmt.Printf("Issue #%d\n", issue.ID)
-	fmt.Println(strings.Repeat("=", 80))
-	fmt.Printf("Subject:     %s\n", issue.Subject)
-	fmt.Printf("Project:     %s\n", issue.Project.Name)
-	fmt.Printf("Tracker:     %s\n", issue.Tracker.Name)
-	fmt.Printf("Status:      %s\n", issue.Status.Name)
-	fmt.Printf("Priority:    %s\n", issue.Priority.Name)
-	fmt.Printf("Author:      %s\n", issue.Author.Name)
-	
+func printIssueDetail(w io.Writer, issue *redmine.Issue, related map[int]redmine.Issue) error {
+	fmt.Fprintf(w, "Issue #%d\n", issue.ID)
+	fmt.Fprintln(w, strings.Repeat("=", 80))
+	fmt.Fprintf(w, "Subject:     %s\n", issue.Subject)
+	fmt.Fprintf(w, "Project:     %s\n", issue.Project.Name)
+	fmt.Fprintf(w, "Tracker:     %s\n", issue.Tracker.Name)
+	fmt.Fprintf(w, "Status:      %s\n", issue.Status.Name)
+	fmt.Fprintf(w, "Priority:    %s\n", issue.Priority.Name)
+	fmt.Fprintf(w, "Author:      %s\n", issue.Author.Name)
+
 	if issue.AssignedTo != nil {
-		fmt.Printf("Assigned to: %s\n", issue.AssignedTo.Name)
+		fmt.Fprintf(w, "Assigned to: %s\n", issue.AssignedTo.Name)
 	} else {
-		fmt.Printf("Assigned to: -\n")
+		fmt.Fprintf(w, "Assigned to: -\n")
 	}
 
 	if issue.Parent != nil {
-		fmt.Printf("Parent:      #%d\n", issue.Parent.ID)
+		fmt.Fprintf(w, "Parent:      #%d\n", issue.Parent.ID)
 	}
 
 	if issue.StartDate != nil {
-		fmt.Printf("Start Date:  %s\n", *issue.StartDate)
+		fmt.Fprintf(w, "Start Date:  %s\n", *issue.StartDate)
 	}
 	if issue.DueDate != nil {
-		fmt.Printf("Due Date:    %s\n", *issue.DueDate)
+		fmt.Fprintf(w, "Due Date:    %s\n", *issue.DueDate)
 	}
 
-	fmt.Printf("Done Ratio:  %d%%\n", issue.DoneRatio)
+	fmt.Fprintf(w, "Done Ratio:  %d%%\n", issue.DoneRatio)
 	if issue.EstimatedHours != nil {
-		fmt.Printf("Estimated:   %.1f hours\n", *issue.EstimatedHours)
+		fmt.Fprintf(w, "Estimated:   %.1f hours\n", *issue.EstimatedHours)
 	}
 
-	fmt.Printf("Created:     %s\n", issue.CreatedOn.Format(time.RFC3339))
-	fmt.Printf("Updated:     %s\n", issue.UpdatedOn.Format(time.RFC3339))
+	fmt.Fprintf(w, "Created:     %s\n", issue.CreatedOn.Format(time.RFC3339))
+	fmt.Fprintf(w, "Updated:     %s\n", issue.UpdatedOn.Format(time.RFC3339))
 
 	// カスタムフィールド
 	if len(issue.CustomFields) > 0 {
-		fmt.Println("\nCustom Fields:")
+		fmt.Fprintln(w, "\nCustom Fields:")
 		for _, cf := range issue.CustomFields {
-			fmt.Printf("  %s: %v\n", cf.Name, cf.Value)
+			fmt.Fprintf(w, "  %s: %s\n", cf.Name, customFieldText(cf.Value))
 		}
 	}
 
 	// 子チケット
 	if len(issue.Children) > 0 {
-		fmt.Println("\nChild Issues:")
+		fmt.Fprintln(w, "\nChild Issues:")
 		for _, child := range issue.Children {
-			fmt.Printf("  #%d [%s] %s\n", child.ID, child.Tracker.Name, child.Subject)
+			fmt.Fprintf(w, "  #%d [%s] %s\n", child.ID, child.Tracker.Name, child.Subject)
+		}
+	}
+
+	// 関連するチケット
+	if len(issue.Relations) > 0 {
+		fmt.Fprintln(w, "\nRelations:")
+		for _, r := range issue.Relations {
+			t, other := r.From(issue.ID)
+			line := fmt.Sprintf("  %s #%d", relationLabels[t], other)
+			if ri, ok := related[other]; ok {
+				line += fmt.Sprintf(" [%s] %s", ri.Status.Name, ri.Subject)
+			}
+			if r.Delay != nil && *r.Delay != 0 {
+				line += fmt.Sprintf(" (delay: %d days)", *r.Delay)
+			}
+			fmt.Fprintln(w, line)
+		}
+	}
+
+	// ウォッチャー
+	if len(issue.Watchers) > 0 {
+		names := make([]string, 0, len(issue.Watchers))
+		for _, u := range issue.Watchers {
+			names = append(names, u.Name)
+		}
+		fmt.Fprintf(w, "\nWatchers: %s\n", strings.Join(names, ", "))
+	}
+
+	// 添付ファイル
+	if len(issue.Attachments) > 0 {
+		fmt.Fprintln(w, "\nAttachments:")
+		for _, att := range issue.Attachments {
+			fmt.Fprintf(w, "  #%d %s (%s, %s) %s\n", att.ID, att.Filename, formatSize(att.Filesize), att.ContentType, att.Author.Name)
 		}
 	}
 
 	// 説明
 	if issue.Description != "" {
-		fmt.Println("\nDescription:")
-		fmt.Println(strings.Repeat("-", 80))
-		fmt.Println(issue.Description)
+		fmt.Fprintln(w, "\nDescription:")
+		fmt.Fprintln(w, strings.Repeat("-", 80))
+		fmt.Fprintln(w, issue.Description)
 	}
 
 	// コメント（ジャーナル）
 	if len(issue.Journals) > 0 {
-		fmt.Println("\nComments:")
-		fmt.Println(strings.Repeat("-", 80))
+		fmt.Fprintln(w, "\nComments:")
+		fmt.Fprintln(w, strings.Repeat("-", 80))
 		for _, journal := range issue.Journals {
 			if journal.Notes != "" {
-				fmt.Printf("\n[%s] %s:\n%s\n",
+				fmt.Fprintf(w, "\n[%s] %s:\n%s\n",
 					journal.CreatedOn.Format("2006-01-02 15:04"),
 					journal.User.Name,
 					journal.Notes)
@@ -129,9 +177,7 @@ func printIssueDetail(issue *redmine.Issue) error {
 	return nil
 }
 
-func init() {
-	rootCmd.AddCommand(getCmd)
-
-	getCmd.Flags().Bool("no-comments", false, "Exclude comments")
-	getCmd.Flags().Bool("fields", false, "Include custom fields")
-}
\ No newline at end of file
+// customFieldText は複数選択の値をカンマ区切りで表示する。
+func customFieldText(value interface{}) string {
+	return strings.Join(customFieldStrings(value), ", ")
+}
@@ -1,49 +1,388 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/ikasamt/rd/pkg/config"
 	"github.com/ikasamt/rd/pkg/redmine"
+	"github.com/spf13/cobra"
 )
 
+// newClient は設定ファイル・環境変数・グローバルフラグからクライアントを生成する。
+func (a *app) newClient(cmd *cobra.Command) (*redmine.Client, error) {
+	flags := cmd.Root().PersistentFlags()
+	urlFlag, _ := flags.GetString("url")
+	keyFlag, _ := flags.GetString("key")
+	debugFlag, _ := flags.GetString("debug")
+
+	cfg, err := config.Load(urlFlag, keyFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	client := redmine.NewClient(cfg.RedmineURL, cfg.APIKey)
+	switch debugFlag {
+	case "", "off", "false", "0":
+	case "body":
+		client.Debug = true
+		client.DebugBodies = true
+	default:
+		client.Debug = true
+	}
+	if cfg.APIKey == "" && cfg.Login != "" {
+		client.Auth = redmine.BasicAuth{Login: cfg.Login, Password: cfg.Password}
+	}
+
+	// HTTPトランスポート（CA・クライアント証明書・プロキシ・タイムアウト）
+	transport := redmine.TransportOptions{
+		CAFile:   cfg.CAFile,
+		CertFile: cfg.ClientCert,
+		KeyFile:  cfg.ClientKey,
+		ProxyURL: cfg.Proxy,
+		Timeout:  cfg.Timeout,
+		Insecure: cfg.Insecure != nil && *cfg.Insecure,
+	}
+	if flags.Changed("timeout") {
+		transport.Timeout, _ = flags.GetDuration("timeout")
+	}
+	if flags.Changed("insecure") {
+		transport.Insecure, _ = flags.GetBool("insecure")
+	}
+	if err := client.ConfigureTransport(transport); err != nil {
+		return nil, err
+	}
+
+	// 管理者キーで別ユーザーとして操作する
+	client.SwitchUser = cfg.SwitchUser
+	if flags.Changed("as") {
+		client.SwitchUser, _ = flags.GetString("as")
+	}
+
+	// リトライ設定（フラグ > 設定ファイル > デフォルト）
+	if cfg.Retries != nil {
+		client.Retry.MaxRetries = *cfg.Retries
+	}
+	if cfg.RetryMinWait > 0 {
+		client.Retry.MinWait = cfg.RetryMinWait
+	}
+	if cfg.RetryMaxWait > 0 {
+		client.Retry.MaxWait = cfg.RetryMaxWait
+	}
+	if flags.Changed("retries") {
+		client.Retry.MaxRetries, _ = flags.GetInt("retries")
+	}
+	if flags.Changed("retry-max-wait") {
+		client.Retry.MaxWait, _ = flags.GetDuration("retry-max-wait")
+	}
+
+	// レート制限（全リクエストで1つのバケットを共有する）
+	rate, burst := cfg.MaxRPS, cfg.Burst
+	if flags.Changed("rate") {
+		rate, _ = flags.GetFloat64("rate")
+	}
+	if rate > 0 {
+		client.RateLimit = redmine.NewRateLimiter(rate, burst)
+	}
+
+	// メタデータキャッシュ
+	if noCache, _ := flags.GetBool("no-cache"); !noCache && (cfg.NoCache == nil || !*cfg.NoCache) {
+		cache, err := redmine.NewCache(cfg.RedmineURL)
+		if err == nil {
+			if ttl, ok := cfg.CacheTTLs[""]; ok {
+				for resource := range cache.TTL {
+					cache.TTL[resource] = ttl
+				}
+			}
+			for resource, ttl := range cfg.CacheTTLs {
+				if resource != "" {
+					cache.TTL[resource] = ttl
+				}
+			}
+			client.Cache = cache
+		}
+	}
+
+	// 通信の記録・再生（再生時はネットワークに出ないので、キャッシュ・リトライ・レート制限は使わない）
+	recordDir, _ := flags.GetString("record")
+	replayDir, _ := flags.GetString("replay")
+	if recordDir != "" && replayDir != "" {
+		return nil, fmt.Errorf("--record and --replay cannot be used together")
+	}
+	if recordDir != "" {
+		if err := client.Record(recordDir); err != nil {
+			return nil, fmt.Errorf("failed to start recording: %w", err)
+		}
+		client.Cache = nil
+	}
+	if replayDir != "" {
+		if err := client.Replay(replayDir); err != nil {
+			return nil, fmt.Errorf("failed to load recording: %w", err)
+		}
+		client.Cache = nil
+		client.Retry.MaxRetries = 0
+		client.RateLimit = nil
+	}
+
+	// セッション全体をHARとして記録し、終了時に書き出す
+	if traceFile, _ := flags.GetString("trace-file"); traceFile != "" {
+		har := client.RecordHAR()
+		a.atExit(func() error {
+			if err := har.WriteFile(traceFile); err != nil {
+				return fmt.Errorf("failed to write trace file: %w", err)
+			}
+			return nil
+		})
+	}
+
+	return client, nil
+}
+
+// reportPartial は Ctrl-C で中断されたときに取得済みの件数を通知し、
+// 部分的な結果を表示してよいかを返す。
+func (a *app) reportPartial(ctx context.Context, n int) bool {
+	if ctx.Err() == nil || n == 0 {
+		return false
+	}
+	fmt.Fprintf(a.stderr, "interrupted: showing %d partial results\n", n)
+	return true
+}
+
 // resolveCustomFields は "name=value" または "id=value" 形式のカスタムフィールド指定を解決する。
-// キーが数値ならIDとして直接使用し、文字列なら名前からIDを解決する。
-func resolveCustomFields(client *redmine.Client, fields []string) ([]redmine.CustomFieldValue, error) {
+// 管理者でない場合は更新対象のチケット（issueID）か作成先のプロジェクト（projectID）のフィールドから探す。
+// 値はフィールドの形式に合わせて変換し、同じ複数選択フィールドを繰り返し指定した場合はまとめる。
+func resolveCustomFields(ctx context.Context, client redmine.API, projectID string, issueID int, fields []string) ([]redmine.CustomFieldValue, error) {
 	var result []redmine.CustomFieldValue
-	var needNameResolve []struct {
-		name  string
-		value string
+	for _, field := range fields {
+		cf, val, err := resolveCustomFieldSpec(ctx, client, projectID, issueID, field)
+		if err != nil {
+			return nil, err
+		}
+		value, err := customFieldValue(ctx, client, projectID, cf, val)
+		if err != nil {
+			return nil, err
+		}
+
+		merged := false
+		for i := range result {
+			if result[i].ID != cf.ID {
+				continue
+			}
+			if prev, ok := result[i].Value.([]string); ok && cf.Multiple {
+				result[i].Value = append(prev, value.([]string)...)
+			} else {
+				result[i].Value = value
+			}
+			merged = true
+		}
+		if !merged {
+			result = append(result, redmine.CustomFieldValue{ID: cf.ID, Value: value})
+		}
 	}
+	return result, nil
+}
 
+// resolveCustomFieldFilters は一覧の絞り込み用に "name=value" 形式の指定を cf_N の値にする。
+// 絞り込みではどのフィールドでもカンマ区切りの値はいずれかに一致すればよいので、
+// 複数選択でなくても分割して "|" でつなぐ。
+func resolveCustomFieldFilters(ctx context.Context, client redmine.API, projectID string, fields []string) (map[int]string, error) {
+	filters := map[int]string{}
 	for _, field := range fields {
-		parts := strings.SplitN(field, "=", 2)
-		if len(parts) != 2 {
-			return nil, fmt.Errorf("invalid field format '%s': expected name=value", field)
+		cf, val, err := resolveCustomFieldSpec(ctx, client, projectID, 0, field)
+		if err != nil {
+			return nil, err
+		}
+		var values []string
+		for _, part := range strings.Split(val, ",") {
+			if part = strings.TrimSpace(part); part == "" {
+				continue
+			}
+			v, err := customFieldScalar(ctx, client, projectID, cf, part)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		// 同じフィールドを繰り返し指定した場合もいずれかに一致すればよい
+		if prev, ok := filters[cf.ID]; ok {
+			values = append([]string{prev}, values...)
+		}
+		filters[cf.ID] = strings.Join(values, "|")
+	}
+	return filters, nil
+}
+
+// resolveCustomFieldSpec は "name=value" を分け、名前または ID からフィールドを探す。
+func resolveCustomFieldSpec(ctx context.Context, client redmine.API, projectID string, issueID int, field string) (*redmine.CustomFieldDefinition, string, error) {
+	parts := strings.SplitN(field, "=", 2)
+	if len(parts) != 2 {
+		return nil, "", fmt.Errorf("invalid field format '%s': expected name=value", field)
+	}
+	key := strings.TrimSpace(parts[0])
+	val := strings.TrimSpace(parts[1])
+
+	cf, err := client.ResolveCustomFieldContext(ctx, projectID, issueID, key)
+	if err != nil {
+		// 一覧にない ID はそのまま送る（形式は分からないので値も変換しない）
+		var resolveErr *redmine.ResolveError
+		id, idErr := strconv.Atoi(key)
+		if idErr != nil || !errors.As(err, &resolveErr) || resolveErr.Ambiguous {
+			return nil, "", fmt.Errorf("failed to resolve custom field '%s': %w", key, err)
+		}
+		cf = &redmine.CustomFieldDefinition{ID: id, Name: key}
+	}
+	return cf, val, nil
+}
+
+// customFieldValue は --field の値を Redmine が受け付ける形に変換する。
+// 複数選択のフィールドはカンマ区切りを配列にする。空文字は値の削除。
+func customFieldValue(ctx context.Context, client redmine.API, projectID string, cf *redmine.CustomFieldDefinition, raw string) (interface{}, error) {
+	if !cf.Multiple {
+		return customFieldScalar(ctx, client, projectID, cf, raw)
+	}
+	values := []string{}
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		v, err := customFieldScalar(ctx, client, projectID, cf, part)
+		if err != nil {
+			return nil, err
 		}
-		key := strings.TrimSpace(parts[0])
-		val := strings.TrimSpace(parts[1])
+		values = append(values, v)
+	}
+	return values, nil
+}
 
-		if id, err := strconv.Atoi(key); err == nil {
-			result = append(result, redmine.CustomFieldValue{ID: id, Value: val})
-		} else {
-			needNameResolve = append(needNameResolve, struct {
-				name  string
-				value string
-			}{key, val})
+// customFieldStrings はカスタムフィールドの値を文字列の並びにする。値は --field から
+// 作った string や []string のことも、JSON から読んだ []interface{} のこともある。
+func customFieldStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			values = append(values, customFieldStrings(item)...)
 		}
+		return values
 	}
+	return []string{fmt.Sprint(value)}
+}
 
-	if len(needNameResolve) > 0 {
-		for _, nr := range needNameResolve {
-			cf, err := client.FindCustomFieldByName(nr.name)
-			if err != nil {
-				return nil, fmt.Errorf("failed to resolve custom field '%s': %w", nr.name, err)
+// customFieldScalar は1つの値をフィールドの形式（field_format）に合わせて検証・変換する。
+// 形式が分からない場合（管理者でない場合）はそのまま返す。
+func customFieldScalar(ctx context.Context, client redmine.API, projectID string, cf *redmine.CustomFieldDefinition, value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: custom field '%s': %s", redmine.ErrValidation, cf.Name, fmt.Sprintf(format, args...))
+	}
+
+	switch cf.FieldFormat {
+	case "bool":
+		switch strings.ToLower(value) {
+		case "1", "true", "yes", "on":
+			return "1", nil
+		case "0", "false", "no", "off":
+			return "0", nil
+		}
+		return "", invalid("'%s' is not a boolean (use true or false)", value)
+	case "int":
+		if _, err := strconv.Atoi(value); err != nil {
+			return "", invalid("'%s' is not an integer", value)
+		}
+	case "float":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return "", invalid("'%s' is not a number", value)
+		}
+	case "date":
+		date, err := parseDate(value)
+		if err != nil {
+			return "", invalid("%v", err)
+		}
+		return date, nil
+	case "list", "key_value":
+		// 管理者でなければ選択肢が分からないので、サーバー側の検証に任せる
+		if len(cf.PossibleValues) == 0 {
+			return value, nil
+		}
+		var allowed []string
+		for _, pv := range cf.PossibleValues {
+			label := pv.Label
+			if label == "" {
+				label = pv.Value
+			}
+			if strings.EqualFold(pv.Value, value) || strings.EqualFold(label, value) {
+				return pv.Value, nil
 			}
-			result = append(result, redmine.CustomFieldValue{ID: cf.ID, Value: nr.value})
+			allowed = append(allowed, label)
+		}
+		return "", invalid("'%s' is not an allowed value (allowed: %s)", value, strings.Join(allowed, ", "))
+	case "user":
+		user, err := client.ResolveUserContext(ctx, projectID, value)
+		if err != nil {
+			return "", fmt.Errorf("custom field '%s': %w", cf.Name, err)
+		}
+		return strconv.Itoa(user.ID), nil
+	case "version":
+		if _, err := strconv.Atoi(value); err == nil {
+			return value, nil
+		}
+		version, err := client.FindVersionByNameContext(ctx, projectID, value)
+		if err != nil {
+			return "", fmt.Errorf("custom field '%s': %w", cf.Name, err)
 		}
+		return strconv.Itoa(version.ID), nil
 	}
+	return value, nil
+}
 
-	return result, nil
+// resolveActivity は作業分類の ID または名前（大文字小文字を区別しない）から ID を返す。
+func resolveActivity(ctx context.Context, client redmine.API, value string) (int, error) {
+	if id, err := strconv.Atoi(value); err == nil {
+		return id, nil
+	}
+	found, err := client.FindTimeEntryActivityByNameContext(ctx, value)
+	if err != nil {
+		return 0, err
+	}
+	return found.ID, nil
+}
+
+// parseDate は YYYY-MM-DD と today / yesterday を受け付ける。
+func parseDate(s string) (string, error) {
+	switch strings.ToLower(s) {
+	case "today":
+		return time.Now().Format("2006-01-02"), nil
+	case "yesterday":
+		return time.Now().AddDate(0, 0, -1).Format("2006-01-02"), nil
+	}
+	if _, err := time.Parse("2006-01-02", s); err != nil {
+		return "", fmt.Errorf("invalid date '%s': expected YYYY-MM-DD", s)
+	}
+	return s, nil
+}
+
+// formatHours は 1.5 を "1.50h" のように表示する。
+func formatHours(h float64) string {
+	return strconv.FormatFloat(h, 'f', 2, 64) + "h"
+}
+
+// printJSON は v をインデント付きの JSON で出力する。
+func printJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
 }
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+	"github.com/ikasamt/rd/pkg/redmine/redminetest"
+)
+
+func TestCustomFieldScalar(t *testing.T) {
+	srv := newTestServer(t)
+	alice := srv.AddUser(redminetest.User{Login: "alice", FirstName: "Alice", LastName: "Smith", APIKey: "alice-key"})
+	srv.AddMember("demo", alice)
+	v1 := srv.AddVersion("demo", "v1.0")
+	client := srv.Client()
+
+	severity := []redmine.CustomFieldPossibleValue{{Value: "low"}, {Value: "high"}}
+	stages := []redmine.CustomFieldPossibleValue{{Value: "1", Label: "Draft"}, {Value: "2", Label: "Review"}}
+	today := time.Now().Format("2006-01-02")
+
+	tests := []struct {
+		format  string
+		values  []redmine.CustomFieldPossibleValue
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"bool", nil, "yes", "1", false},
+		{"bool", nil, "TRUE", "1", false},
+		{"bool", nil, "off", "0", false},
+		{"bool", nil, "maybe", "", true},
+		{"int", nil, "42", "42", false},
+		{"int", nil, "4.2", "", true},
+		{"float", nil, "4.2", "4.2", false},
+		{"float", nil, "four", "", true},
+		{"date", nil, "2025-01-31", "2025-01-31", false},
+		{"date", nil, "today", today, false},
+		{"date", nil, "31/01/2025", "", true},
+		{"list", severity, "HIGH", "high", false},
+		{"list", severity, "medium", "", true},
+		// 選択肢が分からなければサーバーに任せる
+		{"list", nil, "medium", "medium", false},
+		{"enumeration", nil, "Any", "Any", false},
+		{"key_value", stages, "review", "2", false},
+		{"key_value", stages, "1", "1", false},
+		{"key_value", stages, "Done", "", true},
+		{"user", nil, "Alice Smith", strconv.Itoa(alice.ID), false},
+		{"user", nil, "Nobody", "", true},
+		{"version", nil, "v1.0", strconv.Itoa(v1.ID), false},
+		{"version", nil, "99", "99", false},
+		{"version", nil, "v9.9", "", true},
+		{"string", nil, "anything", "anything", false},
+		{"", nil, "anything", "anything", false},
+		{"int", nil, "", "", false},
+	}
+	for _, tt := range tests {
+		cf := &redmine.CustomFieldDefinition{ID: 1, Name: "Field", FieldFormat: tt.format, PossibleValues: tt.values}
+		got, err := customFieldScalar(context.Background(), client, "demo", cf, tt.in)
+		if tt.wantErr {
+			if !errors.Is(err, redmine.ErrValidation) {
+				t.Errorf("%s %q: err = %v, want ErrValidation", tt.format, tt.in, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%s %q = %q, %v, want %q", tt.format, tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestCustomFieldValueSplitsMultipleFields(t *testing.T) {
+	cf := &redmine.CustomFieldDefinition{Name: "Platform", FieldFormat: "list", Multiple: true,
+		PossibleValues: []redmine.CustomFieldPossibleValue{{Value: "Linux"}, {Value: "Mac"}}}
+	got, err := customFieldValue(context.Background(), nil, "", cf, "linux, mac,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values, ok := got.([]string); !ok || len(values) != 2 || values[0] != "Linux" || values[1] != "Mac" {
+		t.Errorf("customFieldValue = %#v, want [Linux Mac]", got)
+	}
+	// 空にすると値を消す（空の配列を送る）
+	if got, _ := customFieldValue(context.Background(), nil, "", cf, ""); got == nil || len(got.([]string)) != 0 {
+		t.Errorf("empty value = %#v, want an empty list", got)
+	}
+}
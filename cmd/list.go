@@ -1,107 +1,301 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
+	"io"
+	"strconv"
 	"strings"
 	"text/tabwriter"
 
-	"github.com/ikasamt/rd/pkg/config"
 	"github.com/ikasamt/rd/pkg/redmine"
 	"github.com/spf13/cobra"
 )
 
-var listCmd = &cobra.Command{
-	Use:   "list",
-	Short: "List Redmine issues",
-	Long:  `List issues from Redmine with various filters and options.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		urlFlag, _ := cmd.Root().Flags().GetString("url")
-		keyFlag, _ := cmd.Root().Flags().GetString("key")
-		debugFlag, _ := cmd.Root().Flags().GetBool("debug")
-		
-		cfg, err := config.Load(urlFlag, keyFlag)
-		if err != nil {
-			return err
-		}
+func newListCmd(a *app) *cobra.Command {
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List Redmine issues",
+		Long:  `List issues from Redmine with various filters and options.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := a.client(cmd)
+			if err != nil {
+				return err
+			}
 
-		client := redmine.NewClient(cfg.RedmineURL, cfg.APIKey)
-		client.Debug = debugFlag
+			// フィルタの設定
+			filter := &redmine.IssueFilter{}
 
-		// フィルタの設定
-		filter := &redmine.IssueFilter{}
-		
-		project, _ := cmd.Flags().GetString("project")
-		if project != "" {
-			filter.ProjectID = project
-		}
+			project, _ := cmd.Flags().GetString("project")
+			if project != "" {
+				filter.ProjectID = project
+			}
 
-		status, _ := cmd.Flags().GetString("status")
-		if status != "" {
-			filter.StatusID = status
-		}
+			ctx := cmd.Context()
 
-		assignee, _ := cmd.Flags().GetString("assignee")
-		if assignee != "" {
-			if assignee == "me" {
-				assignee = "me"
+			// 保存済みクエリ（Redmine は他の絞り込み条件を無視するので併用させない）
+			if query, _ := cmd.Flags().GetString("query"); query != "" {
+				for _, name := range queryIgnoredFlags {
+					if cmd.Flags().Changed(name) {
+						return fmt.Errorf("--query cannot be combined with --%s", name)
+					}
+				}
+				q, err := client.ResolveQueryContext(ctx, query)
+				if err != nil {
+					return err
+				}
+				filter.QueryID = q.ID
+				// プロジェクト別のクエリはそのプロジェクトで実行する
+				if filter.ProjectID == "" && q.ProjectID != nil {
+					filter.ProjectID = strconv.Itoa(*q.ProjectID)
+				}
 			}
-			filter.AssignedTo = assignee
-		}
 
-		parent, _ := cmd.Flags().GetString("parent")
-		if parent != "" {
-			filter.ParentID = parent
-		}
+			// open / closed / * 以外は名前でも ID でも指定できる
+			status, _ := cmd.Flags().GetString("status")
+			switch status {
+			case "", "open", "closed", "*":
+				filter.StatusID = status
+			default:
+				st, err := client.ResolveStatusContext(ctx, status)
+				if err != nil {
+					return err
+				}
+				filter.StatusID = strconv.Itoa(st.ID)
+			}
 
-		// 取得
-		issues, err := client.ListIssues(filter)
-		if err != nil {
-			return fmt.Errorf("failed to list issues: %w", err)
-		}
+			// me は Redmine がそのまま解釈する
+			assignee, _ := cmd.Flags().GetString("assignee")
+			switch assignee {
+			case "", "me":
+				filter.AssignedTo = assignee
+			default:
+				user, err := client.ResolveUserContext(ctx, project, assignee)
+				if err != nil {
+					return err
+				}
+				filter.AssignedTo = strconv.Itoa(user.ID)
+			}
 
-		// 出力形式の判定
-		jsonFlag, _ := cmd.Root().Flags().GetBool("json")
-		if jsonFlag {
-			return outputJSON(issues)
-		}
+			parent, _ := cmd.Flags().GetString("parent")
+			if parent != "" {
+				filter.ParentID = parent
+			}
 
-		oneline, _ := cmd.Flags().GetBool("oneline")
-		if oneline {
-			return outputOneline(issues.Issues)
-		}
+			if cmd.Flags().Changed("subprojects") {
+				subprojects, _ := cmd.Flags().GetBool("subprojects")
+				filter.Subprojects = &subprojects
+			}
 
-		csv, _ := cmd.Flags().GetBool("csv")
-		if csv {
-			return outputCSV(issues.Issues)
-		}
+			if author, _ := cmd.Flags().GetString("author"); author != "" {
+				filter.AuthorID = author
+				if author != "me" {
+					user, err := client.ResolveUserContext(ctx, project, author)
+					if err != nil {
+						return err
+					}
+					filter.AuthorID = strconv.Itoa(user.ID)
+				}
+			}
 
-		return outputTable(issues.Issues)
-	},
+			if tracker, _ := cmd.Flags().GetString("tracker"); tracker != "" {
+				t, err := client.ResolveTrackerContext(ctx, tracker)
+				if err != nil {
+					return err
+				}
+				filter.TrackerID = strconv.Itoa(t.ID)
+			}
+
+			if priority, _ := cmd.Flags().GetString("priority"); priority != "" {
+				p, err := client.ResolvePriorityContext(ctx, priority)
+				if err != nil {
+					return err
+				}
+				filter.PriorityID = strconv.Itoa(p.ID)
+			}
+
+			// カテゴリとバージョンの名前はプロジェクトごとなので --project が必要
+			if category, _ := cmd.Flags().GetString("category"); category != "" {
+				filter.CategoryID = category
+				if _, err := strconv.Atoi(category); err != nil {
+					if project == "" {
+						return fmt.Errorf("--category by name requires --project")
+					}
+					c, err := client.ResolveCategoryContext(ctx, project, category)
+					if err != nil {
+						return err
+					}
+					filter.CategoryID = strconv.Itoa(c.ID)
+				}
+			}
+
+			if version, _ := cmd.Flags().GetString("version"); version != "" {
+				filter.FixedVersionID = version
+				if _, err := strconv.Atoi(version); err != nil {
+					if project == "" {
+						return fmt.Errorf("--version by name requires --project")
+					}
+					v, err := client.FindVersionByNameContext(ctx, project, version)
+					if err != nil {
+						return fmt.Errorf("failed to find version: %w", err)
+					}
+					filter.FixedVersionID = strconv.Itoa(v.ID)
+				}
+			}
+
+			filter.Subject, _ = cmd.Flags().GetString("subject")
+
+			// 日付の範囲
+			for name, target := range map[string]**redmine.DateRange{
+				"created": &filter.CreatedOn,
+				"updated": &filter.UpdatedOn,
+				"closed":  &filter.ClosedOn,
+				"due":     &filter.DueDate,
+			} {
+				value, _ := cmd.Flags().GetString(name)
+				if value == "" {
+					continue
+				}
+				r, err := parseDateRange(value)
+				if err != nil {
+					return fmt.Errorf("invalid --%s: %w", name, err)
+				}
+				*target = r
+			}
+
+			// カスタムフィールド（値は作成・更新と同じく名前や選択肢で指定できる）
+			if fields, _ := cmd.Flags().GetStringArray("field"); len(fields) > 0 {
+				values, err := resolveCustomFieldFilters(ctx, client, project, fields)
+				if err != nil {
+					return err
+				}
+				filter.CustomFields = values
+			}
+
+			// 取得（--all の場合は全ページを辿る）
+			pager := client.IssuePaginator(filter)
+			pager.Offset, _ = cmd.Flags().GetInt("offset")
+			pager.Max, _ = cmd.Flags().GetInt("limit")
+			if all, _ := cmd.Flags().GetBool("all"); all {
+				pager.Max = 0
+				pager.Concurrency, _ = cmd.Flags().GetInt("parallel")
+			}
+
+			items, err := pager.All(ctx)
+			if err != nil && !a.reportPartial(ctx, len(items)) {
+				return fmt.Errorf("failed to list issues: %w", err)
+			}
+			issues := &redmine.IssuesResponse{
+				Issues:     items,
+				TotalCount: pager.TotalCount(),
+				Offset:     pager.Offset,
+				Limit:      len(items),
+			}
+
+			// 出力形式の判定
+			jsonFlag, _ := cmd.Root().Flags().GetBool("json")
+			if jsonFlag {
+				return printJSON(a.stdout, issues)
+			}
+
+			oneline, _ := cmd.Flags().GetBool("oneline")
+			if oneline {
+				return outputOneline(a.stdout, issues.Issues)
+			}
+
+			csv, _ := cmd.Flags().GetBool("csv")
+			if csv {
+				return outputCSV(a.stdout, issues.Issues)
+			}
+
+			return outputTable(a.stdout, issues.Issues)
+		},
+	}
+
+	listCmd.Flags().Bool("all", false, "Show all issues (fetch every page)")
+	listCmd.Flags().Int("limit", 25, "Maximum number of issues to show")
+	listCmd.Flags().Int("offset", 0, "Skip this many issues")
+	listCmd.Flags().Int("parallel", 4, "Pages fetched in parallel with --all")
+	listCmd.Flags().String("project", "", "Filter by project ID")
+	listCmd.Flags().String("status", "", "Filter by status (name, ID, open, closed or *)")
+	listCmd.Flags().String("assignee", "", "Filter by assignee (name, ID, 'me' or, with an admin key, login)")
+	listCmd.Flags().Bool("subprojects", true, "Include issues of subprojects (--subprojects=false to exclude)")
+	listCmd.Flags().String("tracker", "", "Filter by tracker (name or ID)")
+	listCmd.Flags().String("priority", "", "Filter by priority (name or ID)")
+	listCmd.Flags().String("category", "", "Filter by issue category (name or ID)")
+	listCmd.Flags().String("version", "", "Filter by target version (name or ID)")
+	listCmd.Flags().String("author", "", "Filter by author (name, ID, 'me' or, with an admin key, login)")
+	listCmd.Flags().String("subject", "", "Filter by text contained in the subject")
+	listCmd.Flags().String("created", "", dateRangeUsage("creation date"))
+	listCmd.Flags().String("updated", "", dateRangeUsage("last update"))
+	listCmd.Flags().String("closed", "", dateRangeUsage("closing date"))
+	listCmd.Flags().String("due", "", dateRangeUsage("due date"))
+	listCmd.Flags().StringArray("field", nil, "Filter by custom field (format: name=value, comma-separated values match any; repeatable)")
+	listCmd.Flags().String("parent", "", "Filter by parent issue ID")
+	listCmd.Flags().String("query", "", "Use a saved query (name or ID, see 'rd queries')")
+	listCmd.Flags().Bool("oneline", false, "Display in one line format")
+	listCmd.Flags().Bool("csv", false, "Output in CSV format")
+	return listCmd
+}
+
+// queryIgnoredFlags は保存済みクエリを使うときに Redmine が無視する絞り込みのフラグ。
+var queryIgnoredFlags = []string{
+	"status", "assignee", "author", "tracker", "priority", "category", "version", "subject",
+	"created", "updated", "closed", "due", "field", "parent", "subprojects",
+}
+
+func dateRangeUsage(what string) string {
+	return "Filter by " + what + " (>=DATE, <=DATE, FROM..TO or DATE; today and yesterday are accepted)"
 }
 
-func outputJSON(issues *redmine.IssuesResponse) error {
-	encoder := json.NewEncoder(os.Stdout)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(issues)
+// parseDateRange は ">=2025-01-01"、"<=2025-01-31"、"2025-01-01..2025-01-31" と単独の日付を受け付ける。
+func parseDateRange(s string) (*redmine.DateRange, error) {
+	var from, to string
+	switch {
+	case strings.HasPrefix(s, ">="):
+		from = s[2:]
+	case strings.HasPrefix(s, "<="):
+		to = s[2:]
+	case strings.Contains(s, ".."):
+		from, to, _ = strings.Cut(s, "..")
+	default:
+		from, to = s, s
+	}
+
+	r := &redmine.DateRange{}
+	for _, d := range []struct {
+		value  string
+		target *string
+	}{{from, &r.From}, {to, &r.To}} {
+		if d.value = strings.TrimSpace(d.value); d.value == "" {
+			continue
+		}
+		date, err := parseDate(d.value)
+		if err != nil {
+			return nil, err
+		}
+		*d.target = date
+	}
+	if r.From == "" && r.To == "" {
+		return nil, fmt.Errorf("no date in '%s'", s)
+	}
+	return r, nil
 }
 
-func outputOneline(issues []redmine.Issue) error {
+func outputOneline(w io.Writer, issues []redmine.Issue) error {
 	for _, issue := range issues {
-		fmt.Printf("#%d %s\n", issue.ID, issue.Subject)
+		fmt.Fprintf(w, "#%d %s\n", issue.ID, issue.Subject)
 	}
 	return nil
 }
 
-func outputCSV(issues []redmine.Issue) error {
-	fmt.Println("ID,Project,Status,Priority,Subject,Assignee")
+func outputCSV(w io.Writer, issues []redmine.Issue) error {
+	fmt.Fprintln(w, "ID,Project,Status,Priority,Subject,Assignee")
 	for _, issue := range issues {
 		assignee := ""
 		if issue.AssignedTo != nil {
 			assignee = issue.AssignedTo.Name
 		}
-		fmt.Printf("%d,%s,%s,%s,%q,%s\n",
+		fmt.Fprintf(w, "%d,%s,%s,%s,%q,%s\n",
 			issue.ID,
 			issue.Project.Name,
 			issue.Status.Name,
@@ -113,22 +307,22 @@ func outputCSV(issues []redmine.Issue) error {
 	return nil
 }
 
-func outputTable(issues []redmine.Issue) error {
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+func outputTable(out io.Writer, issues []redmine.Issue) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
 	fmt.Fprintln(w, "ID\tProject\tStatus\tPriority\tSubject\tAssignee")
 	fmt.Fprintln(w, strings.Repeat("-", 80))
-	
+
 	for _, issue := range issues {
 		assignee := "-"
 		if issue.AssignedTo != nil {
 			assignee = issue.AssignedTo.Name
 		}
-		
+
 		subject := issue.Subject
 		if len(subject) > 40 {
 			subject = subject[:37] + "..."
 		}
-		
+
 		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n",
 			issue.ID,
 			issue.Project.Name,
@@ -138,19 +332,6 @@ func outputTable(issues []redmine.Issue) error {
 			assignee,
 		)
 	}
-	
+
 	return w.Flush()
 }
-
-func init() {
-	rootCmd.AddCommand(listCmd)
-
-	listCmd.Flags().Bool("all", false, "Show all issues")
-	listCmd.Flags().String("project", "", "Filter by project ID")
-	listCmd.Flags().String("status", "", "Filter by status")
-	listCmd.Flags().String("assignee", "", "Filter by assignee")
-	listCmd.Flags().StringSlice("field", []string{}, "Filter by custom field (format: name=value)")
-	listCmd.Flags().String("parent", "", "Filter by parent issue ID")
-	listCmd.Flags().Bool("oneline", false, "Display in one line format")
-	listCmd.Flags().Bool("csv", false, "Output in CSV format")
-}
\ No newline at end of file
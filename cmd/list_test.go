@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+	"github.com/ikasamt/rd/pkg/redmine/redminetest"
+)
+
+func TestListFilters(t *testing.T) {
+	srv := newTestServer(t)
+	alice := srv.AddUser(redminetest.User{Login: "alice", FirstName: "Alice", LastName: "Smith", APIKey: "alice-key"})
+	srv.AddMember("demo", alice)
+	ui := srv.AddCategory("demo", "UI")
+	project, _ := srv.Client().GetProject("demo")
+	srv.AddIssue(redmine.IssueCreate{ProjectID: project.ID, TrackerID: 1, Subject: "Button misaligned", CategoryID: ui.ID, AssignedToID: alice.ID})
+	srv.AddIssue(redmine.IssueCreate{ProjectID: project.ID, TrackerID: 2, Subject: "Dark mode", CategoryID: ui.ID})
+	srv.AddIssue(redmine.IssueCreate{ProjectID: project.ID, TrackerID: 1, Subject: "Crash on start", StatusID: 5})
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"open by default", nil, "#2 Dark mode\n#1 Button misaligned\n"},
+		{"status by name", []string{"--status", "closed"}, "#3 Crash on start\n"},
+		{"tracker by name", []string{"--tracker", "feature"}, "#2 Dark mode\n"},
+		{"assignee by login", []string{"--assignee", "alice"}, "#1 Button misaligned\n"},
+		{"category by name", []string{"--category", "UI", "--status", "*"}, "#2 Dark mode\n#1 Button misaligned\n"},
+		{"subject", []string{"--subject", "crash", "--status", "*"}, "#3 Crash on start\n"},
+		{"limit and offset", []string{"--status", "*", "--limit", "1", "--offset", "1"}, "#2 Dark mode\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"list", "--project", "demo", "--oneline"}, tt.args...)
+			if got := mustRun(t, srv.Client(), args...); got != tt.want {
+				t.Errorf("rd %v =\n%s\nwant\n%s", args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListWithSavedQuery(t *testing.T) {
+	srv := newTestServer(t)
+	other := srv.AddProject("other", "Other")
+	demo, _ := srv.Client().GetProject("demo")
+	srv.AddIssue(redmine.IssueCreate{ProjectID: demo.ID, TrackerID: 1, Subject: "Button misaligned"})
+	srv.AddIssue(redmine.IssueCreate{ProjectID: demo.ID, TrackerID: 2, Subject: "Dark mode"})
+	srv.AddIssue(redmine.IssueCreate{ProjectID: demo.ID, TrackerID: 1, Subject: "Crash on start", StatusID: 5})
+	srv.AddIssue(redmine.IssueCreate{ProjectID: other.ID, TrackerID: 1, Subject: "Slow import"})
+	srv.AddQuery("", "Open bugs", url.Values{"tracker_id": {"1"}, "status_id": {"open"}})
+	demoBugs := srv.AddQuery("demo", "Demo bugs", url.Values{"tracker_id": {"1"}, "status_id": {"*"}})
+
+	tests := []struct {
+		name        string
+		args        []string
+		want        string
+		wantProject string
+	}{
+		{"by name", []string{"--query", "open bugs"}, "#4 Slow import\n#1 Button misaligned\n", ""},
+		{"by ID", []string{"--query", strconv.Itoa(demoBugs.ID)}, "#3 Crash on start\n#1 Button misaligned\n", strconv.Itoa(demo.ID)},
+		// プロジェクト別のクエリはそのプロジェクトで実行する
+		{"project query", []string{"--query", "Demo bugs"}, "#3 Crash on start\n#1 Button misaligned\n", strconv.Itoa(demo.ID)},
+		{"explicit project", []string{"--query", "Open bugs", "--project", "other"}, "#4 Slow import\n", "other"},
+		{"paging", []string{"--query", "Demo bugs", "--limit", "1", "--offset", "1"}, "#1 Button misaligned\n", strconv.Itoa(demo.ID)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := srv.Client()
+			log := logRequests(client, "/issues.json")
+			args := append([]string{"list", "--oneline"}, tt.args...)
+			if got := mustRun(t, client, args...); got != tt.want {
+				t.Errorf("rd %v =\n%s\nwant\n%s", args, got, tt.want)
+			}
+			if got := log.query("project_id"); got != tt.wantProject {
+				t.Errorf("rd %v sent project_id %q, want %q", args, got, tt.wantProject)
+			}
+		})
+	}
+
+	if _, err := runRD(t, srv.Client(), "list", "--query", "Weekly"); exitCode(err) != exitValidation {
+		t.Errorf("unknown query: exit %d (%v), want %d", exitCode(err), err, exitValidation)
+	}
+}
+
+// Redmine は保存済みクエリを使うと他の絞り込みを無視するので、黙って無視せずエラーにする。
+func TestListRejectsFiltersWithQuery(t *testing.T) {
+	srv := newTestServer(t)
+	srv.AddQuery("", "Open bugs", url.Values{"tracker_id": {"1"}})
+	values := map[string]string{
+		"status": "open", "assignee": "me", "author": "me", "tracker": "Bug", "priority": "High",
+		"category": "UI", "version": "v1.0", "subject": "crash", "created": ">=today", "updated": ">=today",
+		"closed": ">=today", "due": "<=today", "field": "Severity=high", "parent": "1", "subprojects": "false",
+	}
+	for _, name := range queryIgnoredFlags {
+		value, ok := values[name]
+		if !ok {
+			t.Errorf("no test value for --%s", name)
+			continue
+		}
+		client := srv.Client()
+		log := logRequests(client, "/")
+		_, err := runRD(t, client, "list", "--query", "Open bugs", "--"+name+"="+value)
+		if err == nil || err.Error() != "--query cannot be combined with --"+name {
+			t.Errorf("--%s with --query: err = %v", name, err)
+		}
+		if len(log.requests) != 0 {
+			t.Errorf("--%s with --query sent %d requests before failing", name, len(log.requests))
+		}
+	}
+}
+
+func TestListFiltersByMultiValueField(t *testing.T) {
+	srv := newTestServer(t)
+	platform := srv.AddCustomFieldDefinition(redmine.CustomFieldDefinition{
+		Name:           "Platform",
+		FieldFormat:    "list",
+		Multiple:       true,
+		PossibleValues: []redmine.CustomFieldPossibleValue{{Value: "Linux"}, {Value: "Mac"}, {Value: "Windows"}},
+	})
+	project, _ := srv.Client().GetProject("demo")
+	for _, is := range []struct {
+		subject   string
+		platforms []string
+	}{
+		{"Linux only", []string{"Linux"}},
+		{"Desktop", []string{"Mac", "Windows"}},
+		{"Anywhere", nil},
+	} {
+		srv.AddIssue(redmine.IssueCreate{ProjectID: project.ID, Subject: is.subject,
+			CustomFields: []redmine.CustomFieldValue{{ID: platform.ID, Value: is.platforms}}})
+	}
+
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{"Platform=windows", "#2 Desktop\n"},
+		{"Platform=Linux,Mac", "#2 Desktop\n#1 Linux only\n"},
+	}
+	for _, tt := range tests {
+		if got := mustRun(t, srv.Client(), "list", "--field", tt.field, "--oneline"); got != tt.want {
+			t.Errorf("list --field %s =\n%s\nwant\n%s", tt.field, got, tt.want)
+		}
+	}
+
+	if out := mustRun(t, srv.Client(), "get", "2", "--no-comments"); !strings.Contains(out, "Platform: Mac, Windows\n") {
+		t.Errorf("get does not show both values:\n%s", out)
+	}
+}
+
+func TestListFiltersBySingleValueField(t *testing.T) {
+	srv := newTestServer(t)
+	severity := srv.AddCustomFieldDefinition(redmine.CustomFieldDefinition{
+		Name:           "Severity",
+		FieldFormat:    "list",
+		PossibleValues: []redmine.CustomFieldPossibleValue{{Value: "low"}, {Value: "high"}, {Value: "critical"}},
+	})
+	project, _ := srv.Client().GetProject("demo")
+	for _, is := range []struct{ subject, severity string }{
+		{"Typo", "low"},
+		{"Crash", "critical"},
+		{"Slow", "high"},
+	} {
+		srv.AddIssue(redmine.IssueCreate{ProjectID: project.ID, Subject: is.subject,
+			CustomFields: []redmine.CustomFieldValue{{ID: severity.ID, Value: is.severity}}})
+	}
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--field", "Severity=HIGH"}, "#3 Slow\n"},
+		{[]string{"--field", "Severity=high,critical"}, "#3 Slow\n#2 Crash\n"},
+		{[]string{"--field", "Severity=low", "--field", "Severity=critical"}, "#2 Crash\n#1 Typo\n"},
+	}
+	for _, tt := range tests {
+		args := append([]string{"list", "--oneline"}, tt.args...)
+		if got := mustRun(t, srv.Client(), args...); got != tt.want {
+			t.Errorf("list %s =\n%s\nwant\n%s", strings.Join(tt.args, " "), got, tt.want)
+		}
+	}
+
+	if _, err := runRD(t, srv.Client(), "list", "--field", "Severity=high,medium"); !errors.Is(err, redmine.ErrValidation) {
+		t.Errorf("err = %v, want an unknown value rejected", err)
+	}
+}
+
+func TestCustomFieldStrings(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  []string
+	}{
+		{nil, nil},
+		{"a", []string{"a"}},
+		{[]string{"a", "b"}, []string{"a", "b"}},
+		{[]interface{}{"a", "b"}, []string{"a", "b"}},
+		{[]interface{}{}, []string{}},
+		{float64(3), []string{"3"}},
+	}
+	for _, tt := range tests {
+		if got := customFieldStrings(tt.value); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("customFieldStrings(%#v) = %#v, want %#v", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+	"github.com/spf13/cobra"
+)
+
+func newQueriesCmd(a *app) *cobra.Command {
+	queriesCmd := &cobra.Command{
+		Use:   "queries",
+		Short: "List saved issue queries",
+		Long: `List the saved issue queries visible to you.
+Use one with "rd list --query <name|id>" to get the same issues as in the browser.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := a.client(cmd)
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+
+			queries, err := client.ListQueriesContext(ctx)
+			if err != nil {
+				return err
+			}
+
+			// --project 指定時はそのプロジェクトで使えるもの（全プロジェクト共通を含む）に絞る
+			if project, _ := cmd.Flags().GetString("project"); project != "" {
+				p, err := client.GetProjectContext(ctx, project)
+				if err != nil {
+					return fmt.Errorf("failed to get project: %w", err)
+				}
+				var usable []redmine.Query
+				for _, q := range queries {
+					if q.ProjectID == nil || *q.ProjectID == p.ID {
+						usable = append(usable, q)
+					}
+				}
+				queries = usable
+			}
+
+			if jsonFlag, _ := cmd.Root().Flags().GetBool("json"); jsonFlag {
+				if queries == nil {
+					queries = []redmine.Query{}
+				}
+				return printJSON(a.stdout, queries)
+			}
+
+			w := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tName\tProject\tPublic")
+			fmt.Fprintln(w, strings.Repeat("-", 60))
+			for _, q := range queries {
+				project := "(all)"
+				if q.ProjectID != nil {
+					project = strconv.Itoa(*q.ProjectID)
+				}
+				public := "no"
+				if q.IsPublic {
+					public = "yes"
+				}
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", q.ID, q.Name, project, public)
+			}
+			return w.Flush()
+		},
+	}
+
+	queriesCmd.Flags().String("project", "", "Only queries usable in this project")
+	return queriesCmd
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+	"github.com/spf13/cobra"
+)
+
+// relationLabels は Redmine の画面と同じ表記で関連の種類を表す。
+var relationLabels = map[string]string{
+	redmine.RelationRelates:    "related to",
+	redmine.RelationDuplicates: "duplicates",
+	redmine.RelationDuplicated: "duplicated by",
+	redmine.RelationBlocks:     "blocks",
+	redmine.RelationBlocked:    "blocked by",
+	redmine.RelationPrecedes:   "precedes",
+	redmine.RelationFollows:    "follows",
+	redmine.RelationCopiedTo:   "copied to",
+	redmine.RelationCopiedFrom: "copied from",
+}
+
+// parseRelationType は "blocks", "blocked-by", "related" のような指定を Redmine の種類名に変換する。
+func parseRelationType(s string) (string, error) {
+	t := strings.NewReplacer("-", "_", " ", "_").Replace(strings.ToLower(strings.TrimSpace(s)))
+	switch t {
+	case "related", "related_to", "relates_to":
+		t = redmine.RelationRelates
+	case "blocked_by":
+		t = redmine.RelationBlocked
+	case "duplicated_by", "duplicate_of":
+		t = redmine.RelationDuplicated
+	}
+	if !redmine.IsRelationType(t) {
+		return "", fmt.Errorf("unknown relation type '%s' (valid: %s)", s, strings.Join(redmine.RelationTypes, ", "))
+	}
+	return t, nil
+}
+
+func newRelateCmd(a *app) *cobra.Command {
+	relateCmd := &cobra.Command{
+		Use:   "relate <issue-id> <type> <other-issue-id>",
+		Short: "Add a relation between two issues",
+		Long: `Add a relation between two issues.
+Types: relates, duplicates, duplicated, blocks, blocked, precedes, follows, copied_to, copied_from.`,
+		Example: `  rd relate 123 blocks 456
+  rd relate 123 precedes 456 --delay 2
+  rd relate 123 blocked-by 456`,
+		Args: cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			issueID, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid issue ID: %s", args[0])
+			}
+			relationType, err := parseRelationType(args[1])
+			if err != nil {
+				return err
+			}
+			otherID, err := strconv.Atoi(strings.TrimPrefix(args[2], "#"))
+			if err != nil {
+				return fmt.Errorf("invalid issue ID: %s", args[2])
+			}
+
+			relation := &redmine.RelationCreate{IssueToID: otherID, RelationType: relationType}
+			if cmd.Flags().Changed("delay") {
+				if relationType != redmine.RelationPrecedes && relationType != redmine.RelationFollows {
+					return fmt.Errorf("--delay is only valid for precedes and follows")
+				}
+				delay, _ := cmd.Flags().GetInt("delay")
+				relation.Delay = &delay
+			}
+
+			client, err := a.client(cmd)
+			if err != nil {
+				return err
+			}
+			created, err := client.CreateRelationContext(cmd.Context(), issueID, relation)
+			if err != nil {
+				return fmt.Errorf("failed to add relation: %w", err)
+			}
+
+			if jsonFlag, _ := cmd.Root().Flags().GetBool("json"); jsonFlag {
+				return printJSON(a.stdout, created)
+			}
+			fmt.Fprintf(a.stdout, "Issue #%d %s #%d [relation #%d]\n", issueID, relationLabels[relationType], otherID, created.ID)
+			return nil
+		},
+	}
+
+	relateCmd.Flags().Int("delay", 0, "Delay in days (precedes/follows only)")
+	return relateCmd
+}
+
+func newUnrelateCmd(a *app) *cobra.Command {
+	unrelateCmd := &cobra.Command{
+		Use:   "unrelate <issue-id> <other-issue-id>",
+		Short: "Remove relations between two issues",
+		Long: `Remove the relations between two issues.
+With --type only relations of that type (as seen from the first issue) are removed.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			issueID, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid issue ID: %s", args[0])
+			}
+			otherID, err := strconv.Atoi(strings.TrimPrefix(args[1], "#"))
+			if err != nil {
+				return fmt.Errorf("invalid issue ID: %s", args[1])
+			}
+			var relationType string
+			if t, _ := cmd.Flags().GetString("type"); t != "" {
+				if relationType, err = parseRelationType(t); err != nil {
+					return err
+				}
+			}
+
+			client, err := a.client(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			relations, err := client.ListRelationsContext(ctx, issueID)
+			if err != nil {
+				return fmt.Errorf("failed to list relations: %w", err)
+			}
+
+			removed := 0
+			for _, r := range relations {
+				t, other := r.From(issueID)
+				if other != otherID || (relationType != "" && t != relationType) {
+					continue
+				}
+				if err := client.DeleteRelationContext(ctx, r.ID); err != nil {
+					return fmt.Errorf("failed to remove relation #%d: %w", r.ID, err)
+				}
+				fmt.Fprintf(a.stdout, "Removed: #%d %s #%d\n", issueID, relationLabels[t], otherID)
+				removed++
+			}
+			if removed == 0 && relationType != "" {
+				return fmt.Errorf("no '%s' relation from #%d to #%d", relationLabels[relationType], issueID, otherID)
+			}
+			if removed == 0 {
+				return fmt.Errorf("no relation between #%d and #%d", issueID, otherID)
+			}
+			return nil
+		},
+	}
+
+	unrelateCmd.Flags().String("type", "", "Only remove relations of this type")
+	return unrelateCmd
+}
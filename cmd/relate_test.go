@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+)
+
+// relateFixture は demo プロジェクトにチケット #1〜#3 を作る。
+func relateFixture(t *testing.T) redmine.API {
+	t.Helper()
+	srv := newTestServer(t)
+	project, _ := srv.Client().GetProject("demo")
+	for _, subject := range []string{"Parser", "Lexer", "Release"} {
+		srv.AddIssue(redmine.IssueCreate{ProjectID: project.ID, Subject: subject})
+	}
+	return srv.Client()
+}
+
+func TestRelate(t *testing.T) {
+	client := relateFixture(t)
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"1", "blocked-by", "#2"}, "Issue #1 blocked by #2 [relation #1]\n"},
+		{[]string{"1", "precedes", "3", "--delay", "2"}, "Issue #1 precedes #3 [relation #2]\n"},
+	}
+	for _, tt := range tests {
+		if got := mustRun(t, client, append([]string{"relate"}, tt.args...)...); got != tt.want {
+			t.Errorf("rd relate %v = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+
+	// 逆向きの関連はどちらのチケットから見ても正しく表示される
+	checks := map[string][]string{
+		"1": {"blocked by #2 [New] Lexer", "precedes #3 [New] Release (delay: 2 days)"},
+		"2": {"blocks #1 [New] Parser"},
+		"3": {"follows #1 [New] Parser (delay: 2 days)"},
+	}
+	for id, want := range checks {
+		out := mustRun(t, client, "get", id, "--no-comments")
+		for _, line := range want {
+			if !strings.Contains(out, "  "+line+"\n") {
+				t.Errorf("rd get %s does not show %q:\n%s", id, line, out)
+			}
+		}
+	}
+
+	for _, args := range [][]string{
+		{"1", "blocks", "2", "--delay", "1"},
+		{"1", "depends-on", "2"},
+		{"1", "blocks", "x"},
+	} {
+		if _, err := runRD(t, client, append([]string{"relate"}, args...)...); err == nil {
+			t.Errorf("rd relate %v succeeded", args)
+		}
+	}
+}
+
+func TestUnrelate(t *testing.T) {
+	client := relateFixture(t)
+	mustRun(t, client, "relate", "1", "blocks", "2")
+	mustRun(t, client, "relate", "3", "relates", "1")
+
+	// 種類は第1引数のチケットから見た向きで指定する
+	if _, err := runRD(t, client, "unrelate", "2", "1", "--type", "blocks"); err == nil || !strings.Contains(err.Error(), "no 'blocks' relation from #2 to #1") {
+		t.Errorf("wrong direction: err = %v", err)
+	}
+	if got := mustRun(t, client, "unrelate", "2", "1", "--type", "blocked-by"); got != "Removed: #2 blocked by #1\n" {
+		t.Errorf("unrelate --type blocked-by = %q", got)
+	}
+	if got := mustRun(t, client, "unrelate", "1", "3"); got != "Removed: #1 related to #3\n" {
+		t.Errorf("unrelate without --type = %q", got)
+	}
+	if _, err := runRD(t, client, "unrelate", "1", "3"); err == nil || !strings.Contains(err.Error(), "no relation between #1 and #3") {
+		t.Errorf("nothing left: err = %v", err)
+	}
+}
+
+func TestParseRelationType(t *testing.T) {
+	tests := map[string]string{
+		"blocks":        redmine.RelationBlocks,
+		"Blocked-By":    redmine.RelationBlocked,
+		"related":       redmine.RelationRelates,
+		"relates to":    redmine.RelationRelates,
+		"duplicate-of":  redmine.RelationDuplicated,
+		"duplicated_by": redmine.RelationDuplicated,
+		"copied-from":   redmine.RelationCopiedFrom,
+		"follows":       redmine.RelationFollows,
+	}
+	for in, want := range tests {
+		if got, err := parseRelationType(in); err != nil || got != want {
+			t.Errorf("parseRelationType(%q) = %q, %v, want %q", in, got, err, want)
+		}
+	}
+	if _, err := parseRelationType("parent"); err == nil {
+		t.Error("parseRelationType(parent) succeeded")
+	}
+}
@@ -1,34 +1,164 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 
+	"github.com/ikasamt/rd/pkg/redmine"
 	"github.com/spf13/cobra"
 )
 
-var rootCmd = &cobra.Command{
-	Use:   "rd",
-	Short: "Redmine CLI tool",
-	Long: `rd is a command-line interface tool for Redmine.
+// app はコマンドが共有する依存（APIクライアントと出力先）
+type app struct {
+	api    redmine.API
+	stdout io.Writer
+	stderr io.Writer
+
+	// exitHooks はコマンドの成否にかかわらず終了前に実行される
+	exitHooks []func() error
+}
+
+// client は API を返す。未設定ならフラグ・環境変数・.rd から初回に生成する。
+func (a *app) client(cmd *cobra.Command) (redmine.API, error) {
+	if a.api == nil {
+		client, err := a.newClient(cmd)
+		if err != nil {
+			return nil, err
+		}
+		client.DebugOutput = a.stderr
+		a.api = client
+	}
+	return a.api, nil
+}
+
+func (a *app) atExit(fn func() error) {
+	a.exitHooks = append(a.exitHooks, fn)
+}
+
+// runExitHooks は登録された終了処理を実行する。失敗は stderr に出すだけでコマンドの結果は変えない。
+func (a *app) runExitHooks() {
+	hooks := a.exitHooks
+	a.exitHooks = nil
+	for _, fn := range hooks {
+		if err := fn(); err != nil {
+			fmt.Fprintln(a.stderr, err)
+		}
+	}
+}
+
+// runExitHooksAfter はサブコマンドの RunE を包み、実行後に終了処理を呼ぶ。
+func (a *app) runExitHooksAfter(c *cobra.Command) {
+	for _, sub := range c.Commands() {
+		if run := sub.RunE; run != nil {
+			sub.RunE = func(cmd *cobra.Command, args []string) error {
+				defer a.runExitHooks()
+				return run(cmd, args)
+			}
+		}
+		a.runExitHooksAfter(sub)
+	}
+}
+
+// NewRootCmd builds the rd command tree. Commands talk to api and write to
+// stdout and stderr; when api is nil a client is created from flags,
+// environment and .rd on first use.
+func NewRootCmd(api redmine.API, stdout, stderr io.Writer) *cobra.Command {
+	a := &app{api: api, stdout: stdout, stderr: stderr}
+
+	rootCmd := &cobra.Command{
+		Use:   "rd",
+		Short: "Redmine CLI tool",
+		Long: `rd is a command-line interface tool for Redmine.
 It allows you to manage tickets, projects, and users from your terminal.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		cmd.Help()
-	},
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+	rootCmd.SetOut(stdout)
+	rootCmd.SetErr(stderr)
+
+	rootCmd.PersistentFlags().String("url", "", "Redmine URL (overrides REDMINE_URL and .rd)")
+	rootCmd.PersistentFlags().String("key", "", "Redmine API key (overrides REDMINE_API_KEY and .rd)")
+	rootCmd.PersistentFlags().String("as", "", "Act as this user login (X-Redmine-Switch-User, admin key required)")
+	rootCmd.PersistentFlags().Bool("json", false, "Output in JSON format")
+	rootCmd.PersistentFlags().Bool("quiet", false, "Minimal output")
+	rootCmd.PersistentFlags().Bool("verbose", false, "Verbose output")
+	rootCmd.PersistentFlags().String("debug", "", "Trace HTTP requests to stderr (--debug, or --debug=body to include headers and bodies)")
+	rootCmd.PersistentFlags().Lookup("debug").NoOptDefVal = "on"
+	rootCmd.PersistentFlags().String("trace-file", "", "Record the HTTP session to a HAR file")
+	rootCmd.PersistentFlags().String("record", "", "Record HTTP exchanges into a cassette directory")
+	rootCmd.PersistentFlags().String("replay", "", "Answer HTTP requests from a cassette directory (offline)")
+	rootCmd.PersistentFlags().Duration("timeout", redmine.DefaultTimeout, "Timeout for connecting and for the server to respond (file transfers may take longer)")
+	rootCmd.PersistentFlags().Bool("insecure", false, "Skip TLS certificate verification (test instances only)")
+	rootCmd.PersistentFlags().Int("retries", redmine.DefaultRetryPolicy().MaxRetries, "Max retries for transient failures (429/502/503/504)")
+	rootCmd.PersistentFlags().Duration("retry-max-wait", redmine.DefaultRetryPolicy().MaxWait, "Max wait between retries")
+	rootCmd.PersistentFlags().Float64("rate", 0, "Max requests per second (0 = unlimited; burst size from .rd)")
+	rootCmd.PersistentFlags().Bool("no-cache", false, "Do not use the metadata cache")
+
+	rootCmd.AddCommand(
+		newListCmd(a),
+		newQueriesCmd(a),
+		newGetCmd(a),
+		newCreateCmd(a),
+		newUpdateCmd(a),
+		newCommentCmd(a),
+		newAttachCmd(a),
+		newAttachmentsCmd(a),
+		newDownloadCmd(a),
+		newRelateCmd(a),
+		newUnrelateCmd(a),
+		newWatchCmd(a),
+		newUnwatchCmd(a),
+		newTimeCmd(a),
+		newWikiCmd(a),
+		newSearchCmd(a),
+		newCacheCmd(a),
+	)
+	// HAR の書き出しなどはコマンドの成否にかかわらず行う
+	a.runExitHooksAfter(rootCmd)
+	return rootCmd
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	// Ctrl-C で実行中のリクエストをキャンセルする
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	err := NewRootCmd(nil, os.Stdout, os.Stderr).ExecuteContext(ctx)
+	stop()
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
 
-func init() {
-    rootCmd.PersistentFlags().String("url", "", "Redmine URL (overrides REDMINE_URL and .rd)")
-    rootCmd.PersistentFlags().String("key", "", "Redmine API key (overrides REDMINE_API_KEY and .rd)")
-    rootCmd.PersistentFlags().Bool("json", false, "Output in JSON format")
-    rootCmd.PersistentFlags().Bool("quiet", false, "Minimal output")
-    rootCmd.PersistentFlags().Bool("verbose", false, "Verbose output")
-    rootCmd.PersistentFlags().Bool("debug", false, "Debug mode - show HTTP request URLs")
+// スクリプトから失敗の種類を判別できるよう、エラーごとに終了コードを分ける
+const (
+	exitError        = 1
+	exitNotFound     = 3
+	exitUnauthorized = 4
+	exitForbidden    = 5
+	exitValidation   = 6
+	exitSwitchUser   = 7
+	exitConflict     = 8
+)
+
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, redmine.ErrNotFound):
+		return exitNotFound
+	case errors.Is(err, redmine.ErrUnauthorized):
+		return exitUnauthorized
+	case errors.Is(err, redmine.ErrForbidden):
+		return exitForbidden
+	case errors.Is(err, redmine.ErrValidation):
+		return exitValidation
+	case errors.Is(err, redmine.ErrSwitchUserNotFound):
+		return exitSwitchUser
+	case errors.Is(err, redmine.ErrConflict):
+		return exitConflict
+	}
+	return exitError
 }
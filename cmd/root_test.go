@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+	"github.com/ikasamt/rd/pkg/redmine/redminetest"
+)
+
+func TestExitCodes(t *testing.T) {
+	srv := newTestServer(t)
+	bob := srv.AddUser(redminetest.User{Login: "bob", FirstName: "Bob", APIKey: "bob-key"})
+	project, _ := srv.Client().GetProject("demo")
+	srv.AddIssue(redmine.IssueCreate{ProjectID: project.ID, Subject: "Existing"})
+	srv.PutWikiPage("demo", "Spec", "v1")
+	srv.PutWikiPage("demo", "Spec", "v2")
+	srv.AddCustomField("Severity")
+
+	text := filepath.Join(t.TempDir(), "Spec.textile")
+	writeFile(t, text, "v3\n")
+	switched := srv.Client()
+	switched.SwitchUser = "nobody"
+
+	tests := []struct {
+		name string
+		api  redmine.API
+		args []string
+		want int
+	}{
+		{"other errors", srv.Client(), []string{"update", "1"}, exitError},
+		{"missing issue", srv.Client(), []string{"get", "999"}, exitNotFound},
+		{"unknown status name", srv.Client(), []string{"list", "--status", "nonexistent"}, exitValidation},
+		{"ambiguous status name", srv.Client(), []string{"list", "--status", "Re"}, exitValidation},
+		{"invalid API key", redmine.NewClient(srv.URL, "wrong"), []string{"get", "1"}, exitUnauthorized},
+		{"admin-only endpoint", srv.ClientFor(bob), []string{"list", "--field", "Severity=high"}, exitForbidden},
+		{"validation failure", srv.Client(), []string{"update", "1", "--parent", "999"}, exitValidation},
+		{"unknown switch user", switched, []string{"get", "1"}, exitSwitchUser},
+		{"wiki edit conflict", srv.Client(), []string{"wiki", "put", "demo", "Spec", text, "--version", "1"}, exitConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runRD(t, tt.api, tt.args...)
+			if err == nil {
+				t.Fatalf("rd %v succeeded", tt.args)
+			}
+			if got := exitCode(err); got != tt.want {
+				t.Errorf("exitCode(%v) = %d, want %d", err, got, tt.want)
+			}
+		})
+	}
+}
+
+// --trace-file はコマンドが失敗しても書き出され、実行ごとに別の内容になる。
+func TestTraceFileIsWrittenWhenCommandFails(t *testing.T) {
+	srv := newTestServer(t)
+	dir := t.TempDir()
+	flags := []string{"--url", srv.URL, "--key", redminetest.DefaultAPIKey, "--no-cache", "--retries", "0"}
+
+	for _, tt := range []struct {
+		args []string
+		path string
+	}{
+		{[]string{"get", "999"}, "/issues/999.json"},
+		{[]string{"list"}, "/issues.json"},
+	} {
+		file := filepath.Join(dir, tt.args[0]+".har")
+		args := append(append([]string{"--trace-file", file}, flags...), tt.args...)
+		runRD(t, nil, args...)
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("rd %v did not write the trace file: %v", tt.args, err)
+		}
+		if !strings.Contains(string(data), tt.path) {
+			t.Errorf("trace of rd %v does not contain %s", tt.args, tt.path)
+		}
+		if tt.args[0] == "list" && strings.Contains(string(data), "/issues/999.json") {
+			t.Errorf("trace of rd list contains requests of the earlier run")
+		}
+	}
+}
+
+// --as は設定から作ったクライアントに X-Redmine-Switch-User を付ける。
+func TestAsFlagActsAsAnotherUser(t *testing.T) {
+	srv := newTestServer(t)
+	alice := srv.AddUser(redminetest.User{Login: "alice", FirstName: "Alice", APIKey: "alice-key"})
+	flags := []string{"--url", srv.URL, "--key", redminetest.DefaultAPIKey, "--no-cache", "--retries", "0"}
+
+	args := append(append([]string{"--as", "alice"}, flags...), "create", "--project", "demo", "--title", "As alice")
+	mustRun(t, nil, args...)
+	issues, err := srv.Client().ListIssues(&redmine.IssueFilter{ProjectID: "demo"})
+	if err != nil || len(issues.Issues) != 1 {
+		t.Fatalf("ListIssues = %+v, %v", issues, err)
+	}
+	if author := issues.Issues[0].Author.ID; author != alice.ID {
+		t.Errorf("author = %d, want alice (%d)", author, alice.ID)
+	}
+
+	args = append(append([]string{"--as", "nobody"}, flags...), "get", fmt.Sprint(issues.Issues[0].ID))
+	_, err = runRD(t, nil, args...)
+	if exitCode(err) != exitSwitchUser || !strings.Contains(err.Error(), "cannot act as user 'nobody'") {
+		t.Errorf("err = %v (exit %d), want exit %d naming nobody", err, exitCode(err), exitSwitchUser)
+	}
+}
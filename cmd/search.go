@@ -1,193 +1,178 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
 	"strconv"
 	"strings"
 	"text/tabwriter"
 
-	"github.com/ikasamt/rd/pkg/config"
 	"github.com/ikasamt/rd/pkg/redmine"
 	"github.com/spf13/cobra"
 )
 
-var searchCmd = &cobra.Command{
-	Use:   "search <query>",
-	Short: "Search Redmine resources",
-	Long:  `Search issues and other resources in Redmine using the search API.`,
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		query := args[0]
-
-		urlFlag, _ := cmd.Root().Flags().GetString("url")
-		keyFlag, _ := cmd.Root().Flags().GetString("key")
-		debugFlag, _ := cmd.Root().Flags().GetBool("debug")
-		
-		cfg, err := config.Load(urlFlag, keyFlag)
-		if err != nil {
-			return err
-		}
-
-		client := redmine.NewClient(cfg.RedmineURL, cfg.APIKey)
-		client.Debug = debugFlag
+func newSearchCmd(a *app) *cobra.Command {
+	searchCmd := &cobra.Command{
+		Use:   "search <query>",
+		Short: "Search Redmine resources",
+		Long:  `Search issues and other resources in Redmine using the search API.`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			query := args[0]
 
-		// 検索オプションの設定
-		opts := &redmine.SearchOptions{
-			Query:      query,
-			Limit:      100, // デフォルトのページサイズ
-			Issues:     true, // デフォルトでIssuesを検索
-		}
+			client, err := a.client(cmd)
+			if err != nil {
+				return err
+			}
 
-		// フラグから検索対象を設定
-		if all, _ := cmd.Flags().GetBool("all-types"); all {
-			opts.Issues = true
-			opts.News = true
-			opts.Documents = true
-			opts.Changesets = true
-			opts.WikiPages = true
-			opts.Messages = true
-			opts.Projects = true
-		}
+			ctx := cmd.Context()
 
-		// 個別の検索対象フラグ
-		if v, _ := cmd.Flags().GetBool("issues"); v {
-			opts.Issues = v
-		}
-		if v, _ := cmd.Flags().GetBool("wiki"); v {
-			opts.WikiPages = v
-		}
-		if v, _ := cmd.Flags().GetBool("news"); v {
-			opts.News = v
-		}
-		if v, _ := cmd.Flags().GetBool("documents"); v {
-			opts.Documents = v
-		}
-		if v, _ := cmd.Flags().GetBool("changesets"); v {
-			opts.Changesets = v
-		}
-		if v, _ := cmd.Flags().GetBool("messages"); v {
-			opts.Messages = v
-		}
-		if v, _ := cmd.Flags().GetBool("projects"); v {
-			opts.Projects = v
-		}
+			// 検索オプションの設定
+			opts := &redmine.SearchOptions{
+				Query:  query,
+				Limit:  100,  // デフォルトのページサイズ
+				Issues: true, // デフォルトでIssuesを検索
+			}
 
-		// 検索範囲
-		if scope, _ := cmd.Flags().GetString("scope"); scope != "" {
-			opts.Scope = scope
-		}
+			// フラグから検索対象を設定
+			if all, _ := cmd.Flags().GetBool("all-types"); all {
+				opts.Issues = true
+				opts.News = true
+				opts.Documents = true
+				opts.Changesets = true
+				opts.WikiPages = true
+				opts.Messages = true
+				opts.Projects = true
+			}
 
-		// 検索オプション
-		if v, _ := cmd.Flags().GetBool("titles-only"); v {
-			opts.TitlesOnly = v
-		}
-		if v, _ := cmd.Flags().GetBool("all-words"); v {
-			opts.AllWords = v
-		}
+			// 個別の検索対象フラグ
+			if v, _ := cmd.Flags().GetBool("issues"); v {
+				opts.Issues = v
+			}
+			if v, _ := cmd.Flags().GetBool("wiki"); v {
+				opts.WikiPages = v
+			}
+			if v, _ := cmd.Flags().GetBool("news"); v {
+				opts.News = v
+			}
+			if v, _ := cmd.Flags().GetBool("documents"); v {
+				opts.Documents = v
+			}
+			if v, _ := cmd.Flags().GetBool("changesets"); v {
+				opts.Changesets = v
+			}
+			if v, _ := cmd.Flags().GetBool("messages"); v {
+				opts.Messages = v
+			}
+			if v, _ := cmd.Flags().GetBool("projects"); v {
+				opts.Projects = v
+			}
 
-		// ページサイズ
-		if limit, _ := cmd.Flags().GetInt("limit"); limit > 0 {
-			opts.Limit = limit
-		}
+			// 検索範囲
+			if scope, _ := cmd.Flags().GetString("scope"); scope != "" {
+				opts.Scope = scope
+			}
 
-		// 全件検索の処理
-		allFlag, _ := cmd.Flags().GetBool("all")
-		var allResults []redmine.SearchResult
-
-		if allFlag {
-			// ページネーションで全件取得
-			offset := 0
-			for {
-				opts.Offset = offset
-				result, err := client.Search(opts)
-				if err != nil {
-					return fmt.Errorf("search failed: %w", err)
-				}
+			// 検索オプション
+			if v, _ := cmd.Flags().GetBool("titles-only"); v {
+				opts.TitlesOnly = v
+			}
+			if v, _ := cmd.Flags().GetBool("all-words"); v {
+				opts.AllWords = v
+			}
 
-				if len(result.Results) == 0 {
-					break
-				}
+			// ページサイズ
+			if limit, _ := cmd.Flags().GetInt("limit"); limit > 0 {
+				opts.Limit = limit
+			}
 
-				allResults = append(allResults, result.Results...)
+			// 全件検索の処理
+			pager := client.SearchPaginator(opts)
+			pager.PageSize = opts.Limit
+			if allFlag, _ := cmd.Flags().GetBool("all"); !allFlag {
+				// 1ページのみ取得
+				pager.Max = opts.Limit
+			}
 
-				// 次のページがあるかチェック
-				if offset+len(result.Results) >= result.TotalCount {
-					break
-				}
+			allResults, err := pager.All(ctx)
+			if err != nil && !a.reportPartial(ctx, len(allResults)) {
+				return fmt.Errorf("search failed: %w", err)
+			}
 
-				offset += opts.Limit
+			// 出力形式の判定
+			jsonFlag, _ := cmd.Root().Flags().GetBool("json")
+			if jsonFlag {
+				return printJSON(a.stdout, map[string]interface{}{
+					"results": allResults,
+					"total":   len(allResults),
+				})
 			}
-		} else {
-			// 1ページのみ取得
-			result, err := client.Search(opts)
-			if err != nil {
-				return fmt.Errorf("search failed: %w", err)
+
+			oneline, _ := cmd.Flags().GetBool("oneline")
+			if oneline {
+				for _, result := range allResults {
+					// IDを抽出（URLから）
+					parts := strings.Split(result.URL, "/")
+					id := parts[len(parts)-1]
+					fmt.Fprintf(a.stdout, "%s: %s\n", id, result.Title)
+				}
+				return nil
 			}
-			allResults = result.Results
-		}
 
-		// 出力形式の判定
-		jsonFlag, _ := cmd.Root().Flags().GetBool("json")
-		if jsonFlag {
-			encoder := json.NewEncoder(os.Stdout)
-			encoder.SetIndent("", "  ")
-			return encoder.Encode(map[string]interface{}{
-				"results": allResults,
-				"total":   len(allResults),
-			})
-		}
+			// テーブル形式で出力
+			w := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "Type\tID\tTitle\tDescription")
+			fmt.Fprintln(w, strings.Repeat("-", 80))
 
-		oneline, _ := cmd.Flags().GetBool("oneline")
-		if oneline {
 			for _, result := range allResults {
-				// IDを抽出（URLから）
-				parts := strings.Split(result.URL, "/")
-				id := parts[len(parts)-1]
-				fmt.Printf("%s: %s\n", id, result.Title)
+				// URLからIDを抽出
+				id := extractIDFromURL(result.URL)
+
+				// タイトルとDescriptionを短縮
+				title := result.Title
+				if len(title) > 50 {
+					title = title[:47] + "..."
+				}
+
+				desc := result.Description
+				if len(desc) > 30 {
+					desc = desc[:27] + "..."
+				}
+				// HTMLタグを除去
+				desc = strings.ReplaceAll(desc, "<strong class=\"highlight\">", "")
+				desc = strings.ReplaceAll(desc, "</strong>", "")
+
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+					result.Type,
+					id,
+					title,
+					desc,
+				)
 			}
-			return nil
-		}
 
-		// テーブル形式で出力
-		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-		fmt.Fprintln(w, "Type\tID\tTitle\tDescription")
-		fmt.Fprintln(w, strings.Repeat("-", 80))
-		
-		for _, result := range allResults {
-			// URLからIDを抽出
-			id := extractIDFromURL(result.URL)
-			
-			// タイトルとDescriptionを短縮
-			title := result.Title
-			if len(title) > 50 {
-				title = title[:47] + "..."
-			}
-			
-			desc := result.Description
-			if len(desc) > 30 {
-				desc = desc[:27] + "..."
-			}
-			// HTMLタグを除去
-			desc = strings.ReplaceAll(desc, "<strong class=\"highlight\">", "")
-			desc = strings.ReplaceAll(desc, "</strong>", "")
-			
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
-				result.Type,
-				id,
-				title,
-				desc,
-			)
-		}
-		
-		if err := w.Flush(); err != nil {
-			return err
-		}
+			if err := w.Flush(); err != nil {
+				return err
+			}
 
-		fmt.Printf("\nFound %d results matching '%s'\n", len(allResults), query)
-		return nil
-	},
+			fmt.Fprintf(a.stdout, "\nFound %d results matching '%s'\n", len(allResults), query)
+			return nil
+		},
+	}
+
+	searchCmd.Flags().Bool("all-types", false, "Search all resource types")
+	searchCmd.Flags().Bool("issues", false, "Search issues (default: true)")
+	searchCmd.Flags().Bool("wiki", false, "Search wiki pages")
+	searchCmd.Flags().Bool("news", false, "Search news")
+	searchCmd.Flags().Bool("documents", false, "Search documents")
+	searchCmd.Flags().Bool("changesets", false, "Search changesets")
+	searchCmd.Flags().Bool("messages", false, "Search messages")
+	searchCmd.Flags().Bool("projects", false, "Search projects")
+	searchCmd.Flags().String("scope", "", "Search scope: all, my_projects, subprojects")
+	searchCmd.Flags().Bool("titles-only", false, "Search in titles only")
+	searchCmd.Flags().Bool("all-words", false, "Match all query words")
+	searchCmd.Flags().Bool("all", false, "Fetch all results (may take longer)")
+	searchCmd.Flags().Int("limit", 100, "Number of results per page")
+	searchCmd.Flags().Bool("oneline", false, "Display in one line format")
+	return searchCmd
 }
 
 // URLからIDを抽出するヘルパー関数
@@ -211,31 +196,3 @@ func extractIDFromURL(url string) string {
 	}
 	return "-"
 }
-
-func init() {
-	rootCmd.AddCommand(searchCmd)
-
-	// 検索対象の指定
-	searchCmd.Flags().Bool("all-types", false, "Search all resource types")
-	searchCmd.Flags().Bool("issues", false, "Search issues (default: true)")
-	searchCmd.Flags().Bool("wiki", false, "Search wiki pages")
-	searchCmd.Flags().Bool("news", false, "Search news")
-	searchCmd.Flags().Bool("documents", false, "Search documents")
-	searchCmd.Flags().Bool("changesets", false, "Search changesets")
-	searchCmd.Flags().Bool("messages", false, "Search messages")
-	searchCmd.Flags().Bool("projects", false, "Search projects")
-	
-	// 検索範囲
-	searchCmd.Flags().String("scope", "", "Search scope: all, my_projects, subprojects")
-	
-	// 検索オプション
-	searchCmd.Flags().Bool("titles-only", false, "Search in titles only")
-	searchCmd.Flags().Bool("all-words", false, "Match all query words")
-	
-	// ページネーション
-	searchCmd.Flags().Bool("all", false, "Fetch all results (may take longer)")
-	searchCmd.Flags().Int("limit", 100, "Number of results per page")
-	
-	// 出力形式
-	searchCmd.Flags().Bool("oneline", false, "Display in one line format")
-}
\ No newline at end of file
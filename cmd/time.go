@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+	"github.com/spf13/cobra"
+)
+
+func newTimeCmd(a *app) *cobra.Command {
+	timeCmd := &cobra.Command{
+		Use:   "time",
+		Short: "Log and list spent time",
+		Long:  `Log time on issues and list time entries (Redmine time tracking).`,
+	}
+
+	timeCmd.AddCommand(newTimeLogCmd(a), newTimeListCmd(a), newTimeDeleteCmd(a))
+	return timeCmd
+}
+
+func newTimeLogCmd(a *app) *cobra.Command {
+	timeLogCmd := &cobra.Command{
+		Use:   "log <issue-id> <hours>",
+		Short: "Log time spent on an issue",
+		Long: `Log time spent on an issue.
+Hours can be given as 1.5, 1.5h, 90m, 1h30m or 1:30.`,
+		Example: `  rd time log 123 1.5h --activity Development --comment "Fixed the parser"
+  rd time log 123 45m --date yesterday`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			issueID, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid issue ID: %s", args[0])
+			}
+			hours, err := parseHours(args[1])
+			if err != nil {
+				return err
+			}
+
+			entry := &redmine.TimeEntryCreate{IssueID: issueID, Hours: hours}
+			entry.Comments, _ = cmd.Flags().GetString("comment")
+			if date, _ := cmd.Flags().GetString("date"); date != "" {
+				if entry.SpentOn, err = parseDate(date); err != nil {
+					return err
+				}
+			}
+
+			client, err := a.client(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			// アクティビティ名からIDを解決（省略時はRedmineのデフォルト）
+			if activity, _ := cmd.Flags().GetString("activity"); activity != "" {
+				if entry.ActivityID, err = resolveActivity(ctx, client, activity); err != nil {
+					return err
+				}
+			}
+
+			created, err := client.CreateTimeEntryContext(ctx, entry)
+			if err != nil {
+				return fmt.Errorf("failed to log time: %w", err)
+			}
+
+			if jsonFlag, _ := cmd.Root().Flags().GetBool("json"); jsonFlag {
+				return printJSON(a.stdout, created)
+			}
+			fmt.Fprintf(a.stdout, "Logged %s on issue #%d (%s, %s) [time entry #%d]\n",
+				formatHours(created.Hours), issueID, created.Activity.Name, created.SpentOn, created.ID)
+			return nil
+		},
+	}
+
+	timeLogCmd.Flags().String("activity", "", "Activity name or ID (default: Redmine's default activity)")
+	timeLogCmd.Flags().StringP("comment", "m", "", "Comment")
+	timeLogCmd.Flags().String("date", "", "Date spent (YYYY-MM-DD, today or yesterday; default: today)")
+	return timeLogCmd
+}
+
+func newTimeListCmd(a *app) *cobra.Command {
+	timeListCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List time entries",
+		Example: `  rd time list --user me --from 2025-01-01 --to 2025-01-31
+  rd time list --project myproject --all --csv`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter := &redmine.TimeEntryFilter{}
+			filter.ProjectID, _ = cmd.Flags().GetString("project")
+			filter.UserID, _ = cmd.Flags().GetString("user")
+			filter.IssueID, _ = cmd.Flags().GetInt("issue")
+			var err error
+			if from, _ := cmd.Flags().GetString("from"); from != "" {
+				if filter.From, err = parseDate(from); err != nil {
+					return err
+				}
+			}
+			if to, _ := cmd.Flags().GetString("to"); to != "" {
+				if filter.To, err = parseDate(to); err != nil {
+					return err
+				}
+			}
+
+			client, err := a.client(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			if activity, _ := cmd.Flags().GetString("activity"); activity != "" {
+				if filter.ActivityID, err = resolveActivity(ctx, client, activity); err != nil {
+					return err
+				}
+			}
+
+			// 取得（--all の場合は全ページを辿る）
+			pager := client.TimeEntryPaginator(filter)
+			pager.Max, _ = cmd.Flags().GetInt("limit")
+			if all, _ := cmd.Flags().GetBool("all"); all {
+				pager.Max = 0
+				pager.Concurrency, _ = cmd.Flags().GetInt("parallel")
+			}
+			entries, err := pager.All(ctx)
+			if err != nil && !a.reportPartial(ctx, len(entries)) {
+				return fmt.Errorf("failed to list time entries: %w", err)
+			}
+
+			if jsonFlag, _ := cmd.Root().Flags().GetBool("json"); jsonFlag {
+				return printJSON(a.stdout, &redmine.TimeEntriesResponse{
+					TimeEntries: entries,
+					TotalCount:  pager.TotalCount(),
+					Limit:       len(entries),
+				})
+			}
+			if csvFlag, _ := cmd.Flags().GetBool("csv"); csvFlag {
+				return outputTimeEntriesCSV(a.stdout, entries)
+			}
+			return outputTimeEntriesTable(a.stdout, entries)
+		},
+	}
+
+	timeListCmd.Flags().String("user", "", "Filter by user ID (or 'me')")
+	timeListCmd.Flags().String("project", "", "Filter by project ID")
+	timeListCmd.Flags().Int("issue", 0, "Filter by issue ID")
+	timeListCmd.Flags().String("activity", "", "Filter by activity name or ID")
+	timeListCmd.Flags().String("from", "", "Only entries spent on or after this date (YYYY-MM-DD)")
+	timeListCmd.Flags().String("to", "", "Only entries spent on or before this date (YYYY-MM-DD)")
+	timeListCmd.Flags().Bool("all", false, "Show all entries (fetch every page)")
+	timeListCmd.Flags().Int("limit", 25, "Maximum number of entries to show")
+	timeListCmd.Flags().Int("parallel", 4, "Pages fetched in parallel with --all")
+	timeListCmd.Flags().Bool("csv", false, "Output in CSV format")
+	return timeListCmd
+}
+
+func newTimeDeleteCmd(a *app) *cobra.Command {
+	timeDeleteCmd := &cobra.Command{
+		Use:   "delete <time-entry-id>",
+		Short: "Delete a time entry",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid time entry ID: %s", args[0])
+			}
+
+			client, err := a.client(cmd)
+			if err != nil {
+				return err
+			}
+			if err := client.DeleteTimeEntryContext(cmd.Context(), id); err != nil {
+				return fmt.Errorf("failed to delete time entry: %w", err)
+			}
+
+			fmt.Fprintf(a.stdout, "Time entry #%d deleted\n", id)
+			return nil
+		},
+	}
+
+	return timeDeleteCmd
+}
+
+// parseHours は "1.5", "1.5h", "90m", "1h30m", "1:30" を時間数に変換する。
+func parseHours(s string) (float64, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	var hours float64
+	if h, m, ok := strings.Cut(s, ":"); ok {
+		hh, err1 := strconv.Atoi(h)
+		mm, err2 := strconv.Atoi(m)
+		if err1 != nil || err2 != nil || mm < 0 || mm >= 60 {
+			return 0, fmt.Errorf("invalid hours '%s'", s)
+		}
+		hours = float64(hh) + float64(mm)/60
+	} else if f, err := strconv.ParseFloat(s, 64); err == nil {
+		hours = f
+	} else if d, err := time.ParseDuration(s); err == nil {
+		hours = d.Hours()
+	} else {
+		return 0, fmt.Errorf("invalid hours '%s' (use e.g. 1.5, 1.5h, 90m or 1:30)", s)
+	}
+	if hours <= 0 {
+		return 0, fmt.Errorf("hours must be greater than 0")
+	}
+	return hours, nil
+}
+
+func outputTimeEntriesCSV(w io.Writer, entries []redmine.TimeEntry) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"ID", "Date", "User", "Project", "Issue", "Activity", "Hours", "Comment"})
+	for _, e := range entries {
+		issue := ""
+		if e.Issue != nil {
+			issue = strconv.Itoa(e.Issue.ID)
+		}
+		cw.Write([]string{
+			strconv.Itoa(e.ID),
+			e.SpentOn,
+			e.User.Name,
+			e.Project.Name,
+			issue,
+			e.Activity.Name,
+			strconv.FormatFloat(e.Hours, 'f', -1, 64),
+			e.Comments,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func outputTimeEntriesTable(out io.Writer, entries []redmine.TimeEntry) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tDate\tUser\tProject\tIssue\tActivity\tHours\tComment")
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+
+	var total float64
+	for _, e := range entries {
+		issue := "-"
+		if e.Issue != nil {
+			issue = fmt.Sprintf("#%d", e.Issue.ID)
+		}
+		comment := e.Comments
+		if len(comment) > 40 {
+			comment = comment[:37] + "..."
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%.2f\t%s\n",
+			e.ID,
+			e.SpentOn,
+			e.User.Name,
+			e.Project.Name,
+			issue,
+			e.Activity.Name,
+			e.Hours,
+			comment,
+		)
+		total += e.Hours
+	}
+	fmt.Fprintf(w, "\t\t\t\t\tTotal\t%.2f\t\n", total)
+
+	return w.Flush()
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+)
+
+func TestTimeLog(t *testing.T) {
+	srv := newTestServer(t)
+	project, _ := srv.Client().GetProject("demo")
+	issue := srv.AddIssue(redmine.IssueCreate{ProjectID: project.ID, Subject: "Parser"})
+
+	tests := []struct {
+		args         []string
+		wantHours    float64
+		wantActivity string
+	}{
+		{[]string{"1h30m"}, 1.5, "Development"},
+		{[]string{"0:45", "--activity", "testing"}, 0.75, "Testing"},
+		{[]string{"2", "--activity", "8"}, 2, "Design"},
+	}
+	for _, tt := range tests {
+		args := append([]string{"time", "log", "1"}, tt.args...)
+		args = append(args, "--date", "2025-01-10", "-m", "Fixed")
+		out := mustRun(t, srv.Client(), args...)
+		if !strings.HasPrefix(out, "Logged ") || !strings.Contains(out, "on issue #1 ("+tt.wantActivity+", 2025-01-10)") {
+			t.Errorf("rd %v printed %q", args, out)
+		}
+		entries := srv.TimeEntries()
+		got := entries[len(entries)-1]
+		if got.Hours != tt.wantHours || got.Activity.Name != tt.wantActivity || got.Comments != "Fixed" || got.Issue.ID != issue.ID {
+			t.Errorf("rd %v logged %+v", args, got)
+		}
+	}
+
+	if _, err := runRD(t, srv.Client(), "time", "log", "1", "1h", "--activity", "Meeting"); !errors.Is(err, redmine.ErrValidation) {
+		t.Errorf("unknown activity: err = %v, want ErrValidation", err)
+	}
+	if _, err := runRD(t, srv.Client(), "time", "log", "1", "soon"); err == nil {
+		t.Error("invalid hours were accepted")
+	}
+}
+
+func TestTimeList(t *testing.T) {
+	srv := newTestServer(t)
+	project, _ := srv.Client().GetProject("demo")
+	srv.AddIssue(redmine.IssueCreate{ProjectID: project.ID, Subject: "Parser"})
+	for _, args := range [][]string{
+		{"1", "--activity", "Design", "--date", "2025-01-01"},
+		{"2", "--activity", "Testing", "--date", "2025-01-02", "-m", "Unit tests"},
+		{"3", "--activity", "Testing", "--date", "2025-02-01"},
+	} {
+		mustRun(t, srv.Client(), append([]string{"time", "log", "1"}, args...)...)
+	}
+
+	// 作業分類は名前でも ID でも絞り込める
+	for _, activity := range []string{"Testing", "test", "10"} {
+		out, err := runRD(t, srv.Client(), "time", "list", "--activity", activity, "--to", "2025-01-31")
+		if activity == "test" {
+			// 前方一致は time log と同じく受け付けない
+			if !errors.Is(err, redmine.ErrValidation) {
+				t.Errorf("--activity test: err = %v, want ErrValidation", err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		total := strings.Join(strings.Fields(lines[len(lines)-1]), " ")
+		if len(lines) != 4 || !strings.Contains(out, "Unit tests") || total != "Total 2.00" {
+			t.Errorf("--activity %s:\n%s", activity, out)
+		}
+	}
+
+	out := mustRun(t, srv.Client(), "time", "list", "--csv")
+	want := "ID,Date,User,Project,Issue,Activity,Hours,Comment\n" +
+		"3,2025-02-01,Redmine Admin,Demo,1,Testing,3,\n" +
+		"2,2025-01-02,Redmine Admin,Demo,1,Testing,2,Unit tests\n" +
+		"1,2025-01-01,Redmine Admin,Demo,1,Design,1,\n"
+	if out != want {
+		t.Errorf("--csv =\n%s\nwant\n%s", out, want)
+	}
+
+	var response redmine.TimeEntriesResponse
+	if err := json.Unmarshal([]byte(mustRun(t, srv.Client(), "--json", "time", "list", "--limit", "2")), &response); err != nil {
+		t.Fatal(err)
+	}
+	if len(response.TimeEntries) != 2 || response.TotalCount != 3 {
+		t.Errorf("--json --limit 2 = %d entries of %d", len(response.TimeEntries), response.TotalCount)
+	}
+}
+
+func TestTimeDelete(t *testing.T) {
+	srv := newTestServer(t)
+	project, _ := srv.Client().GetProject("demo")
+	srv.AddIssue(redmine.IssueCreate{ProjectID: project.ID, Subject: "Parser"})
+	mustRun(t, srv.Client(), "time", "log", "1", "1h")
+
+	if out := mustRun(t, srv.Client(), "time", "delete", "1"); out != "Time entry #1 deleted\n" {
+		t.Errorf("output = %q", out)
+	}
+	if len(srv.TimeEntries()) != 0 {
+		t.Errorf("time entry was not deleted: %+v", srv.TimeEntries())
+	}
+	if _, err := runRD(t, srv.Client(), "time", "delete", "1"); exitCode(err) != exitNotFound {
+		t.Errorf("deleting again: exit %d, want %d", exitCode(err), exitNotFound)
+	}
+}
+
+func TestParseHours(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+	}{
+		{"1.5", 1.5},
+		{"1.5h", 1.5},
+		{"90m", 1.5},
+		{"1h30m", 1.5},
+		{"1:30", 1.5},
+		{" 2H ", 2},
+	}
+	for _, tt := range tests {
+		if got, err := parseHours(tt.in); err != nil || got != tt.want {
+			t.Errorf("parseHours(%q) = %v, %v, want %v", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"", "0", "-1", "1:60", "soon"} {
+		if _, err := parseHours(in); err == nil {
+			t.Errorf("parseHours(%q) succeeded", in)
+		}
+	}
+}
@@ -4,153 +4,213 @@ import (
 	"fmt"
 	"strconv"
 
-	"github.com/ikasamt/rd/pkg/config"
 	"github.com/ikasamt/rd/pkg/redmine"
 	"github.com/spf13/cobra"
 )
 
-var updateCmd = &cobra.Command{
-	Use:   "update <issue-id>",
-	Short: "Update a Redmine issue",
-	Long:  `Update an existing Redmine issue with various options.`,
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		issueID, err := strconv.Atoi(args[0])
-		if err != nil {
-			return fmt.Errorf("invalid issue ID: %s", args[0])
-		}
-
-		urlFlag, _ := cmd.Root().Flags().GetString("url")
-		keyFlag, _ := cmd.Root().Flags().GetString("key")
-		debugFlag, _ := cmd.Root().Flags().GetBool("debug")
-		
-		cfg, err := config.Load(urlFlag, keyFlag)
-		if err != nil {
-			return err
-		}
-
-		client := redmine.NewClient(cfg.RedmineURL, cfg.APIKey)
-		client.Debug = debugFlag
-
-		update := &redmine.IssueUpdate{}
-		hasUpdate := false
-
-		// ステータス更新
-		if status, _ := cmd.Flags().GetString("status"); status != "" {
-			if id, err := strconv.Atoi(status); err == nil {
-				statusID := id
-				update.StatusID = &statusID
+func newUpdateCmd(a *app) *cobra.Command {
+	updateCmd := &cobra.Command{
+		Use:   "update <issue-id>",
+		Short: "Update a Redmine issue",
+		Long:  `Update an existing Redmine issue with various options.`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			issueID, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid issue ID: %s", args[0])
+			}
+
+			client, err := a.client(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			update := &redmine.IssueUpdate{}
+			hasUpdate := false
+
+			// 担当者・カテゴリ・バージョン・ウォッチャー・カスタムフィールドの解決に使うプロジェクトは一度だけ取得する
+			var current *redmine.Issue
+			issueProject := func() (string, error) {
+				if current == nil {
+					issue, err := client.GetIssueWithContext(ctx, issueID)
+					if err != nil {
+						return "", fmt.Errorf("failed to get current issue: %w", err)
+					}
+					current = issue
+				}
+				return strconv.Itoa(current.Project.ID), nil
+			}
+
+			// ステータス更新（名前でも ID でも指定できる）
+			if status, _ := cmd.Flags().GetString("status"); status != "" {
+				st, err := client.ResolveStatusContext(ctx, status)
+				if err != nil {
+					return err
+				}
+				update.StatusID = &st.ID
 				hasUpdate = true
 			}
-		}
 
-		// 担当者更新
-		if assignee, _ := cmd.Flags().GetString("assign"); assignee != "" {
-			if assignee == "me" {
-				currentUser, err := client.GetCurrentUser()
+			// 担当者更新
+			if assignee, _ := cmd.Flags().GetString("assign"); assignee != "" {
+				projectID, err := issueProject()
 				if err != nil {
-					return fmt.Errorf("failed to get current user: %w", err)
-				}
-				update.AssignedToID = &currentUser.ID
-			} else if id, err := strconv.Atoi(assignee); err == nil {
-				update.AssignedToID = &id
-			}
-			hasUpdate = true
-		}
-
-		// 優先度更新
-		if priority, _ := cmd.Flags().GetInt("priority"); priority > 0 {
-			update.PriorityID = &priority
-			hasUpdate = true
-		}
-
-		// 進捗率更新
-		if doneRatio, _ := cmd.Flags().GetInt("done-ratio"); cmd.Flags().Changed("done-ratio") {
-			update.DoneRatio = &doneRatio
-			hasUpdate = true
-		}
-
-		// 開始日更新
-		if startDate, _ := cmd.Flags().GetString("start-date"); startDate != "" {
-			update.StartDate = &startDate
-			hasUpdate = true
-		}
-
-		// 期限日更新
-		if dueDate, _ := cmd.Flags().GetString("due-date"); dueDate != "" {
-			update.DueDate = &dueDate
-			hasUpdate = true
-		}
-
-		// 対象バージョン更新
-		if version, _ := cmd.Flags().GetString("version"); version != "" {
-			// まず現在のチケット情報を取得してプロジェクトIDを特定
-			currentIssue, err := client.GetIssue(issueID, false)
-			if err != nil {
-				return fmt.Errorf("failed to get current issue: %w", err)
+					return err
+				}
+				user, err := client.ResolveUserContext(ctx, projectID, assignee)
+				if err != nil {
+					return err
+				}
+				update.AssignedToID = &user.ID
+				hasUpdate = true
 			}
-			
-			projectID := fmt.Sprintf("%d", currentIssue.Project.ID)
-			versionObj, err := client.FindVersionByName(projectID, version)
-			if err != nil {
-				return fmt.Errorf("failed to find version: %w", err)
-			}
-			
-			update.FixedVersionID = &versionObj.ID
-			hasUpdate = true
-		}
-
-		// 親チケット更新
-		if parentID, _ := cmd.Flags().GetInt("parent"); cmd.Flags().Changed("parent") {
-			update.ParentIssueID = &parentID
-			hasUpdate = true
-		}
-
-		// 説明更新
-		if description, _ := cmd.Flags().GetString("description"); cmd.Flags().Changed("description") {
-			update.Description = &description
-			hasUpdate = true
-		}
-
-		// コメント追加
-		if note, _ := cmd.Flags().GetString("note"); note != "" {
-			update.Notes = note
-			hasUpdate = true
-		}
-
-		// カスタムフィールド更新
-		fields, _ := cmd.Flags().GetStringSlice("field")
-		if len(fields) > 0 {
-			customFields, err := resolveCustomFields(client, fields)
-			if err != nil {
-				return err
+
+			// トラッカー更新
+			if tracker, _ := cmd.Flags().GetString("tracker"); tracker != "" {
+				t, err := client.ResolveTrackerContext(ctx, tracker)
+				if err != nil {
+					return err
+				}
+				update.TrackerID = &t.ID
+				hasUpdate = true
 			}
-			if len(customFields) > 0 {
-				update.CustomFields = customFields
+
+			// 優先度更新
+			if priority, _ := cmd.Flags().GetString("priority"); priority != "" {
+				p, err := client.ResolvePriorityContext(ctx, priority)
+				if err != nil {
+					return err
+				}
+				update.PriorityID = &p.ID
 				hasUpdate = true
 			}
-		}
 
-		if !hasUpdate {
-			return fmt.Errorf("no updates specified")
-		}
+			// カテゴリ更新
+			if category, _ := cmd.Flags().GetString("category"); category != "" {
+				projectID, err := issueProject()
+				if err != nil {
+					return err
+				}
+				c, err := client.ResolveCategoryContext(ctx, projectID, category)
+				if err != nil {
+					return err
+				}
+				update.CategoryID = &c.ID
+				hasUpdate = true
+			}
 
-		// 更新実行
-		if err := client.UpdateIssue(issueID, update); err != nil {
-			return fmt.Errorf("failed to update issue: %w", err)
-		}
+			// 進捗率更新
+			if doneRatio, _ := cmd.Flags().GetInt("done-ratio"); cmd.Flags().Changed("done-ratio") {
+				update.DoneRatio = &doneRatio
+				hasUpdate = true
+			}
 
-		fmt.Printf("Issue #%d updated successfully\n", issueID)
-		return nil
-	},
-}
+			// 開始日更新
+			if startDate, _ := cmd.Flags().GetString("start-date"); startDate != "" {
+				update.StartDate = &startDate
+				hasUpdate = true
+			}
 
-func init() {
-	rootCmd.AddCommand(updateCmd)
+			// 期限日更新
+			if dueDate, _ := cmd.Flags().GetString("due-date"); dueDate != "" {
+				update.DueDate = &dueDate
+				hasUpdate = true
+			}
+
+			// 対象バージョン更新
+			if version, _ := cmd.Flags().GetString("version"); version != "" {
+				projectID, err := issueProject()
+				if err != nil {
+					return err
+				}
+				versionObj, err := client.FindVersionByNameContext(ctx, projectID, version)
+				if err != nil {
+					return fmt.Errorf("failed to find version: %w", err)
+				}
+
+				update.FixedVersionID = &versionObj.ID
+				hasUpdate = true
+			}
+
+			// 親チケット更新
+			if parentID, _ := cmd.Flags().GetInt("parent"); cmd.Flags().Changed("parent") {
+				update.ParentIssueID = &parentID
+				hasUpdate = true
+			}
+
+			// 説明更新
+			if description, _ := cmd.Flags().GetString("description"); cmd.Flags().Changed("description") {
+				update.Description = &description
+				hasUpdate = true
+			}
+
+			// コメント追加
+			if note, _ := cmd.Flags().GetString("note"); note != "" {
+				update.Notes = note
+				hasUpdate = true
+			}
+
+			// カスタムフィールド更新
+			fields, _ := cmd.Flags().GetStringArray("field")
+			if len(fields) > 0 {
+				projectID, err := issueProject()
+				if err != nil {
+					return err
+				}
+				customFields, err := resolveCustomFields(ctx, client, projectID, issueID, fields)
+				if err != nil {
+					return err
+				}
+				if len(customFields) > 0 {
+					update.CustomFields = customFields
+					hasUpdate = true
+				}
+			}
+
+			// 添付ファイル
+			if attach, _ := cmd.Flags().GetStringArray("attach"); len(attach) > 0 {
+				uploads, err := uploadAttachFlag(cmd, client)
+				if err != nil {
+					return err
+				}
+				update.Uploads = uploads
+				hasUpdate = true
+			}
 
-	updateCmd.Flags().String("status", "", "Update status ID")
-	updateCmd.Flags().String("assign", "", "Assign to user ID (or 'me')")
-	updateCmd.Flags().Int("priority", 0, "Update priority ID")
+			// ウォッチャー追加（更新APIでは指定できないので別に登録する）
+			var watchers []*redmine.User
+			if values, _ := cmd.Flags().GetStringArray("watcher"); len(values) > 0 {
+				projectID, err := issueProject()
+				if err != nil {
+					return err
+				}
+				if watchers, err = resolveUsers(ctx, client, projectID, values); err != nil {
+					return err
+				}
+			}
+
+			if !hasUpdate && len(watchers) == 0 {
+				return fmt.Errorf("no updates specified")
+			}
+
+			// 更新実行
+			if hasUpdate {
+				if err := client.UpdateIssueContext(ctx, issueID, update); err != nil {
+					return fmt.Errorf("failed to update issue: %w", err)
+				}
+				fmt.Fprintf(a.stdout, "Issue #%d updated successfully\n", issueID)
+			}
+			return a.addWatchers(ctx, client, issueID, watchers)
+		},
+	}
+
+	updateCmd.Flags().String("status", "", "Update status (name or ID)")
+	updateCmd.Flags().String("assign", "", "Assign to user (name, ID, 'me' or, with an admin key, login)")
+	updateCmd.Flags().String("tracker", "", "Update tracker (name or ID)")
+	updateCmd.Flags().String("priority", "", "Update priority (name or ID)")
+	updateCmd.Flags().String("category", "", "Update issue category (name or ID)")
 	updateCmd.Flags().Int("done-ratio", 0, "Update done ratio (0-100)")
 	updateCmd.Flags().String("start-date", "", "Update start date (YYYY-MM-DD)")
 	updateCmd.Flags().String("due-date", "", "Update due date (YYYY-MM-DD)")
@@ -158,6 +218,9 @@ func init() {
 	updateCmd.Flags().Int("parent", 0, "Set parent issue ID (0 to remove)")
 	updateCmd.Flags().String("description", "", "Update description")
 	updateCmd.Flags().String("note", "", "Add a note/comment")
-	updateCmd.Flags().StringSlice("field", []string{}, "Update custom field (format: name=value)")
+	updateCmd.Flags().StringArray("field", nil, "Update custom field (format: name=value, comma-separated for multi-value fields; repeatable)")
+	updateCmd.Flags().StringArray("attach", nil, attachFlagUsage)
+	updateCmd.Flags().StringArray("watcher", nil, "Add a watcher by name, ID or, with an admin key, login (repeatable)")
 	updateCmd.Flags().Bool("interactive", false, "Interactive mode")
-}
\ No newline at end of file
+	return updateCmd
+}
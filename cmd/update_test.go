@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+	"github.com/ikasamt/rd/pkg/redmine/redminetest"
+)
+
+func TestUpdateResolvesNamesAndAddsNote(t *testing.T) {
+	srv := newTestServer(t)
+	alice := srv.AddUser(redminetest.User{Login: "alice", FirstName: "Alice", LastName: "Smith", APIKey: "alice-key"})
+	srv.AddMember("demo", alice)
+	srv.AddVersion("demo", "v1.0")
+	project, _ := srv.Client().GetProject("demo")
+	issue := srv.AddIssue(redmine.IssueCreate{ProjectID: project.ID, Subject: "Crash on save"})
+
+	out := mustRun(t, srv.Client(), "update", "1",
+		"--status", "in progress",
+		"--assign", "Alice Smith",
+		"--version", "v1.0",
+		"--done-ratio", "50",
+		"--note", "Looking into it")
+	if !strings.Contains(out, "Issue #1 updated successfully") {
+		t.Errorf("output = %q", out)
+	}
+
+	got, _ := srv.Issue(issue.ID)
+	if got.Status.Name != "In Progress" {
+		t.Errorf("status = %q, want In Progress", got.Status.Name)
+	}
+	if got.AssignedTo == nil || got.AssignedTo.ID != alice.ID {
+		t.Errorf("assigned_to = %+v, want alice", got.AssignedTo)
+	}
+	if got.DoneRatio != 50 {
+		t.Errorf("done_ratio = %d, want 50", got.DoneRatio)
+	}
+	if n := len(got.Journals); n != 1 || got.Journals[0].Notes != "Looking into it" {
+		t.Errorf("journals = %+v, want one with the note", got.Journals)
+	}
+	if out := mustRun(t, srv.Client(), "list", "--project", "demo", "--version", "v1.0", "--oneline"); out != "#1 Crash on save\n" {
+		t.Errorf("issues in v1.0 = %q", out)
+	}
+}
+
+func TestUpdateWithoutChangesFails(t *testing.T) {
+	srv := newTestServer(t)
+	project, _ := srv.Client().GetProject("demo")
+	srv.AddIssue(redmine.IssueCreate{ProjectID: project.ID, Subject: "Nothing"})
+
+	if _, err := runRD(t, srv.Client(), "update", "1"); err == nil || !strings.Contains(err.Error(), "no updates specified") {
+		t.Errorf("err = %v, want no updates specified", err)
+	}
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+	"github.com/spf13/cobra"
+)
+
+func newWatchCmd(a *app) *cobra.Command {
+	watchCmd := &cobra.Command{
+		Use:   "watch <issue-id> [users...]",
+		Short: "Add watchers to a Redmine issue",
+		Long: `Add watchers to an issue. Users are given as login, name or ID; without users you watch the issue yourself.
+Names are looked up among the project members; other users' logins need an admin API key.`,
+		Example: `  rd watch 123
+  rd watch 123 alice "Bob Smith" 42`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return a.changeWatchers(cmd, args, true)
+		},
+	}
+
+	return watchCmd
+}
+
+func newUnwatchCmd(a *app) *cobra.Command {
+	unwatchCmd := &cobra.Command{
+		Use:   "unwatch <issue-id> [users...]",
+		Short: "Remove watchers from a Redmine issue",
+		Long:  `Remove watchers from an issue. Without users you stop watching the issue yourself.`,
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return a.changeWatchers(cmd, args, false)
+		},
+	}
+
+	return unwatchCmd
+}
+
+func (a *app) changeWatchers(cmd *cobra.Command, args []string, add bool) error {
+	issueID, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid issue ID: %s", args[0])
+	}
+	values := args[1:]
+	if len(values) == 0 {
+		values = []string{"me"}
+	}
+
+	client, err := a.client(cmd)
+	if err != nil {
+		return err
+	}
+
+	// 名前はチケットのプロジェクトのメンバーから探す
+	ctx := cmd.Context()
+	issue, err := client.GetIssueWithContext(ctx, issueID)
+	if err != nil {
+		return fmt.Errorf("failed to get issue: %w", err)
+	}
+	users, err := resolveUsers(ctx, client, strconv.Itoa(issue.Project.ID), values)
+	if err != nil {
+		return err
+	}
+
+	if add {
+		return a.addWatchers(ctx, client, issueID, users)
+	}
+	for _, u := range users {
+		if err := client.RemoveWatcherContext(ctx, issueID, u.ID); err != nil {
+			return fmt.Errorf("failed to remove watcher %s: %w", userLabel(u), err)
+		}
+		fmt.Fprintf(a.stdout, "Removed watcher %s from issue #%d\n", userLabel(u), issueID)
+	}
+	return nil
+}
+
+func (a *app) addWatchers(ctx context.Context, client redmine.API, issueID int, users []*redmine.User) error {
+	for _, u := range users {
+		if err := client.AddWatcherContext(ctx, issueID, u.ID); err != nil {
+			return fmt.Errorf("failed to add watcher %s: %w", userLabel(u), err)
+		}
+		fmt.Fprintf(a.stdout, "Added watcher %s to issue #%d\n", userLabel(u), issueID)
+	}
+	return nil
+}
+
+// resolveUsers はログイン名・表示名・ID・"me" で指定されたユーザーを解決する。
+func resolveUsers(ctx context.Context, client redmine.API, projectID string, values []string) ([]*redmine.User, error) {
+	var users []*redmine.User
+	for _, v := range values {
+		u, err := client.ResolveUserContext(ctx, projectID, v)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, nil
+}
+
+// userLabel は名前が分かっていれば名前を、なければ #ID を返す。
+func userLabel(u *redmine.User) string {
+	if u.Name == "" {
+		return fmt.Sprintf("#%d", u.ID)
+	}
+	return u.Name
+}
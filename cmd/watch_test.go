@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+	"github.com/ikasamt/rd/pkg/redmine/redminetest"
+)
+
+// watcherIDs はチケットのウォッチャーの ID を昇順で返す。
+func watcherIDs(t *testing.T, srv *redminetest.Server, issueID int) []int {
+	t.Helper()
+	issue, err := srv.Client().GetIssueWith(issueID, redmine.IncludeWatchers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int
+	for _, w := range issue.Watchers {
+		ids = append(ids, w.ID)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func TestWatchers(t *testing.T) {
+	srv := newTestServer(t)
+	alice := srv.AddUser(redminetest.User{Login: "alice", FirstName: "Alice", LastName: "Jones", APIKey: "alice-key"})
+	bob := srv.AddUser(redminetest.User{Login: "bob", FirstName: "Bob", LastName: "Smith", APIKey: "bob-key"})
+	srv.AddMember("demo", alice)
+	srv.AddMember("demo", bob)
+	client := srv.Client()
+
+	out := mustRun(t, client, "create", "--project", "demo", "--title", "Watched", "--watcher", "alice")
+	issues, err := client.ListIssues(&redmine.IssueFilter{ProjectID: "demo"})
+	if err != nil || len(issues.Issues) != 1 {
+		t.Fatalf("ListIssues = %+v, %v (create printed %q)", issues, err, out)
+	}
+	id := issues.Issues[0].ID
+	issue := fmt.Sprint(id)
+	if got := watcherIDs(t, srv, id); !slices.Equal(got, []int{alice.ID}) {
+		t.Fatalf("watchers after create = %v, want alice", got)
+	}
+
+	mustRun(t, client, "update", issue, "--watcher", "Bob Smith")
+	out = mustRun(t, client, "watch", issue)
+	if !strings.Contains(out, "to issue #"+issue) {
+		t.Errorf("watch printed %q", out)
+	}
+	if got, want := watcherIDs(t, srv, id), []int{srv.Admin.ID, alice.ID, bob.ID}; !slices.Equal(got, want) {
+		t.Errorf("watchers = %v, want %v", got, want)
+	}
+
+	out = mustRun(t, client, "unwatch", issue, "alice", fmt.Sprint(bob.ID))
+	if !strings.Contains(out, "Removed watcher Alice Jones from issue #"+issue) {
+		t.Errorf("unwatch printed %q", out)
+	}
+	if got := watcherIDs(t, srv, id); !slices.Equal(got, []int{srv.Admin.ID}) {
+		t.Errorf("watchers after unwatch = %v, want only admin", got)
+	}
+
+	if _, err := runRD(t, client, "watch", issue, "nobody"); exitCode(err) != exitValidation {
+		t.Errorf("unknown user: err = %v (exit %d), want exit %d", err, exitCode(err), exitValidation)
+	}
+}
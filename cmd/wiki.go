@@ -0,0 +1,354 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+	"github.com/spf13/cobra"
+)
+
+func newWikiCmd(a *app) *cobra.Command {
+	wikiCmd := &cobra.Command{
+		Use:   "wiki",
+		Short: "Read and edit Redmine wiki pages",
+	}
+
+	wikiCmd.AddCommand(
+		newWikiListCmd(a),
+		newWikiGetCmd(a),
+		newWikiPutCmd(a),
+		newWikiHistoryCmd(a),
+		newWikiDiffCmd(a),
+		newWikiSyncCmd(a),
+		newWikiPushCmd(a),
+	)
+	return wikiCmd
+}
+
+func newWikiListCmd(a *app) *cobra.Command {
+	wikiListCmd := &cobra.Command{
+		Use:   "list <project>",
+		Short: "List the wiki pages of a project",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := a.client(cmd)
+			if err != nil {
+				return err
+			}
+			pages, err := client.ListWikiPagesContext(cmd.Context(), args[0])
+			if err != nil {
+				return fmt.Errorf("failed to list wiki pages: %w", err)
+			}
+
+			if jsonFlag, _ := cmd.Root().Flags().GetBool("json"); jsonFlag {
+				return printJSON(a.stdout, pages)
+			}
+
+			w := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "Title\tParent\tVersion\tUpdated")
+			fmt.Fprintln(w, strings.Repeat("-", 80))
+			for _, p := range pages {
+				parent := "-"
+				if p.Parent != nil {
+					parent = p.Parent.Title
+				}
+				fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", p.Title, parent, p.Version, p.UpdatedOn.Format("2006-01-02 15:04"))
+			}
+			return w.Flush()
+		},
+	}
+
+	return wikiListCmd
+}
+
+func newWikiGetCmd(a *app) *cobra.Command {
+	wikiGetCmd := &cobra.Command{
+		Use:   "get <project> <page>",
+		Short: "Print the text of a wiki page",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			version, _ := cmd.Flags().GetInt("version")
+			output, _ := cmd.Flags().GetString("output")
+
+			client, err := a.client(cmd)
+			if err != nil {
+				return err
+			}
+			page, err := client.GetWikiPageContext(cmd.Context(), args[0], args[1], version)
+			if err != nil {
+				return fmt.Errorf("failed to get wiki page: %w", err)
+			}
+
+			if jsonFlag, _ := cmd.Root().Flags().GetBool("json"); jsonFlag {
+				return printJSON(a.stdout, page)
+			}
+			text := wikiFileText(page.Text)
+			if output == "" {
+				_, err := io.WriteString(a.stdout, text)
+				return err
+			}
+			if err := os.WriteFile(output, []byte(text), 0o644); err != nil {
+				return err
+			}
+			// put --version に渡せるよう、取得したバージョンを表示する
+			fmt.Fprintf(a.stdout, "Saved %s (version %d) to %s\n", page.Title, page.Version, output)
+			return nil
+		},
+	}
+
+	wikiGetCmd.Flags().Int("version", 0, "Get this version instead of the current one")
+	wikiGetCmd.Flags().StringP("output", "o", "", "Write the text to this file")
+	return wikiGetCmd
+}
+
+func newWikiPutCmd(a *app) *cobra.Command {
+	wikiPutCmd := &cobra.Command{
+		Use:   "put <project> <page> [file]",
+		Short: "Create or update a wiki page",
+		Long: `Create or update a wiki page with the text of a file, or of stdin when no file is given.
+--version is the version the edit is based on: if the page has changed on the server since then, the
+update is refused (exit code 8) unless --force is given. It is required when the page exists, except for
+files in a directory mirrored with 'rd wiki sync', whose synced version is used.`,
+		Example: `  rd wiki get myproject Spec -o spec.textile      # Saved Spec (version 5) ...
+  rd wiki put myproject Spec spec.textile --version 5 -m "Clarify errors"
+  echo "h1. Notes" | rd wiki put myproject Notes`,
+		Args: cobra.RangeArgs(2, 3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			project, title := args[0], args[1]
+			base, _ := cmd.Flags().GetInt("version")
+			force, _ := cmd.Flags().GetBool("force")
+
+			var data []byte
+			var err error
+			if len(args) == 3 && args[2] != "-" {
+				data, err = os.ReadFile(args[2])
+			} else {
+				data, err = io.ReadAll(cmd.InOrStdin())
+			}
+			if err != nil {
+				return err
+			}
+
+			update := &redmine.WikiPageUpdate{Text: string(data)}
+			update.Comments, _ = cmd.Flags().GetString("comment")
+			update.ParentTitle, _ = cmd.Flags().GetString("parent")
+
+			client, err := a.client(cmd)
+			if err != nil {
+				return err
+			}
+
+			// rd wiki sync したディレクトリのファイルなら、同期したバージョンを編集元とする
+			var manifest *wikiManifest
+			var synced *wikiManifestPage
+			if len(args) == 3 && args[2] != "-" {
+				manifest, synced = syncedWikiPage(args[2], project, title)
+			}
+			if base == 0 && synced != nil {
+				base = synced.Version
+			}
+
+			ctx := cmd.Context()
+			current, err := client.GetWikiPageContext(ctx, project, title, 0)
+			if err != nil && !errors.Is(err, redmine.ErrNotFound) {
+				return fmt.Errorf("failed to get wiki page: %w", err)
+			}
+			if current != nil {
+				if sameText(current.Text, update.Text) && update.ParentTitle == "" {
+					fmt.Fprintf(a.stdout, "Wiki page %s is unchanged (version %d)\n", current.Title, current.Version)
+					return nil
+				}
+				// 編集元が分からなければ、知らないうちにサーバー側の変更を上書きしないよう拒否する
+				if base == 0 && !force {
+					return fmt.Errorf("wiki page %s already exists (version %d); pass the version your edit is based on with --version, or --force to overwrite",
+						current.Title, current.Version)
+				}
+				// サーバー側が編集元より新しければ上書きしない
+				if current.Version > base && !force {
+					return wikiConflict(title, current.Version, base)
+				}
+				update.Version = base
+				if force {
+					update.Version = current.Version
+				}
+			}
+
+			if err := client.PutWikiPageContext(ctx, project, title, update); err != nil {
+				if errors.Is(err, redmine.ErrConflict) {
+					return fmt.Errorf("%w\n%v", wikiConflict(title, 0, update.Version), err)
+				}
+				return fmt.Errorf("failed to save wiki page: %w", err)
+			}
+
+			saved, err := client.GetWikiPageContext(ctx, project, title, 0)
+			if err != nil {
+				fmt.Fprintf(a.stdout, "Wiki page %s saved\n", title)
+				return nil
+			}
+			fmt.Fprintf(a.stdout, "Wiki page %s saved (version %d)\n", saved.Title, saved.Version)
+			// 次の put や push が自分の保存を競合と見なさないよう記録を進める
+			if synced != nil {
+				synced.Version = saved.Version
+				synced.SHA256 = textHash(saved.Text)
+				return manifest.save(filepath.Dir(args[2]))
+			}
+			return nil
+		},
+	}
+
+	wikiPutCmd.Flags().StringP("comment", "m", "", "Comment for this version")
+	wikiPutCmd.Flags().String("parent", "", "Parent page title")
+	wikiPutCmd.Flags().Int("version", 0, "Version the edit is based on (required to update an existing page; newer versions are not overwritten)")
+	wikiPutCmd.Flags().Bool("force", false, "Overwrite even if the page changed on the server")
+	return wikiPutCmd
+}
+
+// syncedWikiPage は file が rd wiki sync で同期したページのファイルなら、その記録を返す。
+func syncedWikiPage(file, project, title string) (*wikiManifest, *wikiManifestPage) {
+	m, err := loadWikiManifest(filepath.Dir(file))
+	if err != nil || !strings.EqualFold(m.Project, project) {
+		return nil, nil
+	}
+	for t, page := range m.Pages {
+		if strings.EqualFold(t, title) && page.File == filepath.Base(file) {
+			return m, page
+		}
+	}
+	return nil, nil
+}
+
+// wikiConflict はサーバー側の変更で保存できないときのエラーを作る。current が 0 なら不明。
+func wikiConflict(title string, current, base int) error {
+	if current > 0 {
+		return fmt.Errorf("%w: wiki page %s is at version %d on the server but your edit is based on version %d; review with 'rd wiki diff' or use --force to overwrite",
+			redmine.ErrConflict, title, current, base)
+	}
+	return fmt.Errorf("%w: wiki page %s changed on the server since version %d; review with 'rd wiki diff' or use --force to overwrite",
+		redmine.ErrConflict, title, base)
+}
+
+// sameText は改行コードと末尾の改行の違いを無視して比較する。
+func sameText(a, b string) bool {
+	return normalizeText(a) == normalizeText(b)
+}
+
+func normalizeText(s string) string {
+	return strings.TrimRight(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+}
+
+// wikiFileText はページの本文をファイルに保存する形（LF、末尾に改行）にする。
+func wikiFileText(text string) string {
+	text = normalizeText(text)
+	if text == "" {
+		return ""
+	}
+	return text + "\n"
+}
+
+func newWikiHistoryCmd(a *app) *cobra.Command {
+	wikiHistoryCmd := &cobra.Command{
+		Use:   "history <project> <page>",
+		Short: "Show the versions of a wiki page",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			limit, _ := cmd.Flags().GetInt("limit")
+
+			client, err := a.client(cmd)
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			history, err := client.WikiPageHistoryContext(ctx, args[0], args[1], limit)
+			if err != nil && !a.reportPartial(ctx, len(history)) {
+				return fmt.Errorf("failed to get wiki history: %w", err)
+			}
+
+			if jsonFlag, _ := cmd.Root().Flags().GetBool("json"); jsonFlag {
+				return printJSON(a.stdout, history)
+			}
+
+			w := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "Version\tUpdated\tAuthor\tComment")
+			fmt.Fprintln(w, strings.Repeat("-", 80))
+			for _, p := range history {
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", p.Version, p.UpdatedOn.Format("2006-01-02 15:04"), p.Author.Name, p.Comments)
+			}
+			return w.Flush()
+		},
+	}
+
+	wikiHistoryCmd.Flags().Int("limit", 10, "Maximum number of versions to show (0 = all)")
+	return wikiHistoryCmd
+}
+
+func newWikiDiffCmd(a *app) *cobra.Command {
+	wikiDiffCmd := &cobra.Command{
+		Use:   "diff <project> <page>",
+		Short: "Show changes between versions of a wiki page",
+		Long: `Show a unified diff between two versions of a wiki page (by default the previous and the current one),
+or between the server and a local file with --file.`,
+		Example: `  rd wiki diff myproject Spec
+  rd wiki diff myproject Spec --from 3 --to 5
+  rd wiki diff myproject Spec --file spec.textile`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			project, title := args[0], args[1]
+			from, _ := cmd.Flags().GetInt("from")
+			to, _ := cmd.Flags().GetInt("to")
+			file, _ := cmd.Flags().GetString("file")
+
+			client, err := a.client(cmd)
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+
+			var fromName, toName, fromText, toText string
+			if file != "" {
+				// サーバーの版 → ローカルファイル
+				page, err := client.GetWikiPageContext(ctx, project, title, from)
+				if err != nil {
+					return fmt.Errorf("failed to get wiki page: %w", err)
+				}
+				data, err := os.ReadFile(file)
+				if err != nil {
+					return err
+				}
+				fromName, fromText = fmt.Sprintf("%s (version %d)", page.Title, page.Version), page.Text
+				toName, toText = file, string(data)
+			} else {
+				newer, err := client.GetWikiPageContext(ctx, project, title, to)
+				if err != nil {
+					return fmt.Errorf("failed to get wiki page: %w", err)
+				}
+				if from == 0 {
+					from = newer.Version - 1
+				}
+				if from < 1 {
+					fmt.Fprintf(a.stdout, "%s has only one version\n", newer.Title)
+					return nil
+				}
+				older, err := client.GetWikiPageContext(ctx, project, title, from)
+				if err != nil {
+					return fmt.Errorf("failed to get version %d: %w", from, err)
+				}
+				fromName, fromText = fmt.Sprintf("%s (version %d)", older.Title, older.Version), older.Text
+				toName, toText = fmt.Sprintf("%s (version %d)", newer.Title, newer.Version), newer.Text
+			}
+
+			_, err = io.WriteString(a.stdout, unifiedDiff(fromName, toName, splitLines(fromText), splitLines(toText), 3))
+			return err
+		},
+	}
+
+	wikiDiffCmd.Flags().Int("from", 0, "Older version (default: the one before --to; with --file: the server version to compare)")
+	wikiDiffCmd.Flags().Int("to", 0, "Newer version (default: current)")
+	wikiDiffCmd.Flags().String("file", "", "Compare the server version with this local file")
+	return wikiDiffCmd
+}
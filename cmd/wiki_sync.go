@@ -0,0 +1,672 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+	"github.com/spf13/cobra"
+)
+
+// wikiManifestFile はミラーしたディレクトリに置く、最後に同期した版の記録
+const wikiManifestFile = ".rd-wiki.json"
+
+type wikiManifest struct {
+	Project   string                       `json:"project"`
+	Extension string                       `json:"extension"`
+	Pages     map[string]*wikiManifestPage `json:"pages"`
+}
+
+type wikiManifestPage struct {
+	File    string `json:"file"`
+	Version int    `json:"version"`
+	Parent  string `json:"parent,omitempty"`
+	// SHA256 は Version の本文のハッシュ。ローカルの変更の検出に使う
+	SHA256 string `json:"sha256"`
+	// Attachments はローカルのファイル名から添付ファイル ID への対応
+	Attachments map[string]int `json:"attachments,omitempty"`
+}
+
+func loadWikiManifest(dir string) (*wikiManifest, error) {
+	m := &wikiManifest{Pages: map[string]*wikiManifestPage{}}
+	data, err := os.ReadFile(filepath.Join(dir, wikiManifestFile))
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", filepath.Join(dir, wikiManifestFile), err)
+	}
+	if m.Pages == nil {
+		m.Pages = map[string]*wikiManifestPage{}
+	}
+	return m, nil
+}
+
+// save は途中で中断しても壊れないよう、一時ファイルに書いてから置き換える。
+func (m *wikiManifest) save(dir string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, wikiManifestFile)
+	if err := os.WriteFile(path+".tmp", append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+func (m *wikiManifest) titles() []string {
+	titles := make([]string, 0, len(m.Pages))
+	for title := range m.Pages {
+		titles = append(titles, title)
+	}
+	sort.Strings(titles)
+	return titles
+}
+
+// textHash は改行コードと末尾の改行の違いを無視したハッシュを返す。
+func textHash(text string) string {
+	sum := sha256.Sum256([]byte(normalizeText(text)))
+	return hex.EncodeToString(sum[:])
+}
+
+// wikiMirror は sync と push に共通の状態
+type wikiMirror struct {
+	*app
+	client   redmine.API
+	dir      string
+	manifest *wikiManifest
+}
+
+// entry はページの記録を返す。なければ作る。
+func (mir *wikiMirror) entry(title string) *wikiManifestPage {
+	entry := mir.manifest.Pages[title]
+	if entry == nil {
+		entry = &wikiManifestPage{File: title + "." + mir.manifest.Extension}
+		mir.manifest.Pages[title] = entry
+	}
+	return entry
+}
+
+func (mir *wikiMirror) pagePath(title string) string {
+	if entry := mir.manifest.Pages[title]; entry != nil && entry.File != "" {
+		return filepath.Join(mir.dir, entry.File)
+	}
+	return filepath.Join(mir.dir, title+"."+mir.manifest.Extension)
+}
+
+func (mir *wikiMirror) attachmentDir(title string) string {
+	return filepath.Join(mir.dir, "attachments", title)
+}
+
+func (mir *wikiMirror) writePage(title, text string) error {
+	return os.WriteFile(mir.pagePath(title), []byte(wikiFileText(text)), 0o644)
+}
+
+// readPage はローカルのファイルを読む。ファイルがなければ ok が false になる。
+func (mir *wikiMirror) readPage(title string) (text string, ok bool, err error) {
+	data, err := os.ReadFile(mir.pagePath(title))
+	if os.IsNotExist(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return string(data), true, nil
+}
+
+// merge は最後に同期した版を元に、ローカルの変更とサーバーの変更をローカルのファイルにまとめ、
+// 衝突の数を返す。記録はサーバーの版に進めるので、次の push でまとめた内容が送られる。
+func (mir *wikiMirror) merge(ctx context.Context, title, local string, page *redmine.WikiPage) (int, error) {
+	var base string
+	if entry := mir.manifest.Pages[title]; entry != nil {
+		old, err := mir.client.GetWikiPageContext(ctx, mir.manifest.Project, title, entry.Version)
+		if err != nil && !errors.Is(err, redmine.ErrNotFound) {
+			return 0, fmt.Errorf("failed to get version %d of %s: %w", entry.Version, title, err)
+		}
+		if old != nil {
+			base = old.Text
+		}
+	}
+
+	merged, conflicts := merge3(splitLines(base), splitLines(local), splitLines(page.Text),
+		"local", fmt.Sprintf("server (version %d)", page.Version))
+	if err := mir.writePage(title, strings.Join(merged, "\n")); err != nil {
+		return 0, err
+	}
+	entry := mir.entry(title)
+	entry.Version = page.Version
+	entry.SHA256 = textHash(page.Text)
+
+	if conflicts > 0 {
+		fmt.Fprintf(mir.stdout, "Conflict in %s: %d change(s) conflict with server version %d; resolve the markers in %s\n",
+			title, conflicts, page.Version, mir.pagePath(title))
+	} else {
+		fmt.Fprintf(mir.stdout, "Merged server version %d of %s into the local changes\n", page.Version, title)
+	}
+	return conflicts, nil
+}
+
+func newWikiSyncCmd(a *app) *cobra.Command {
+	wikiSyncCmd := &cobra.Command{
+		Use:   "sync <project> <dir>",
+		Short: "Mirror the wiki of a project into a local directory",
+		Long: `Download every wiki page of a project into <dir> as <title>.<ext>, the page attachments into
+<dir>/attachments/<title>/, and record the synced versions in <dir>/` + wikiManifestFile + `.
+
+Running sync again updates the pages that changed on the server and keeps local edits. When a page
+changed both locally and on the server, the server changes are merged into the local file, with
+conflict markers where both sides changed the same lines (exit code 8). Upload local edits with
+'rd wiki push'.`,
+		Example: `  rd wiki sync myproject wiki/
+  vi wiki/Spec.textile
+  rd wiki push wiki/`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			project, dir := args[0], args[1]
+			ext, _ := cmd.Flags().GetString("ext")
+			parallel, _ := cmd.Flags().GetInt("parallel")
+
+			m, err := loadWikiManifest(dir)
+			if err != nil {
+				return err
+			}
+			if m.Project != "" && m.Project != project {
+				return fmt.Errorf("%s is a mirror of project %s, not %s", dir, m.Project, project)
+			}
+			m.Project = project
+			// 既存のミラーは最初に使った拡張子のまま
+			if m.Extension == "" {
+				m.Extension = strings.TrimPrefix(ext, ".")
+			}
+
+			client, err := a.client(cmd)
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			pages, err := client.ListWikiPagesContext(ctx, project)
+			if err != nil {
+				return fmt.Errorf("failed to list wiki pages: %w", err)
+			}
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				return err
+			}
+
+			mir := &wikiMirror{app: a, client: client, dir: dir, manifest: m}
+			conflicts, err := mir.sync(ctx, pages, parallel)
+			// 途中で失敗しても、書き換えたファイルの分は記録しておく
+			if saveErr := m.save(dir); err == nil {
+				err = saveErr
+			}
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(a.stdout, "Synced %d page(s) of %s to %s\n", len(pages), project, dir)
+			if len(conflicts) > 0 {
+				return fmt.Errorf("%w: %s changed both locally and on the server; resolve the conflict markers and run 'rd wiki push'",
+					redmine.ErrConflict, strings.Join(conflicts, ", "))
+			}
+			return nil
+		},
+	}
+
+	wikiSyncCmd.Flags().String("ext", "textile", "File extension for new mirrors (e.g. md for Markdown wikis)")
+	wikiSyncCmd.Flags().Int("parallel", 4, "Attachments downloaded in parallel")
+	return wikiSyncCmd
+}
+
+// sync はページを取得してローカルに反映し、衝突が残ったページを返す。
+func (mir *wikiMirror) sync(ctx context.Context, pages []redmine.WikiPageInfo, parallel int) ([]string, error) {
+	var conflicts []string
+	var jobs []downloadJob
+	seen := map[string]bool{}
+	for _, info := range pages {
+		seen[info.Title] = true
+		page, err := mir.client.GetWikiPageContext(ctx, mir.manifest.Project, info.Title, 0)
+		if err != nil {
+			return conflicts, fmt.Errorf("failed to get wiki page %s: %w", info.Title, err)
+		}
+		n, err := mir.syncPage(ctx, page)
+		if err != nil {
+			return conflicts, err
+		}
+		if n > 0 {
+			conflicts = append(conflicts, page.Title)
+		}
+		pageJobs, err := mir.attachmentJobs(page)
+		if err != nil {
+			return conflicts, err
+		}
+		jobs = append(jobs, pageJobs...)
+	}
+
+	// サーバーで削除されたページ
+	for _, title := range mir.manifest.titles() {
+		if seen[title] {
+			continue
+		}
+		if err := mir.removePage(title); err != nil {
+			return conflicts, err
+		}
+	}
+
+	if len(jobs) > 0 {
+		d := &downloader{app: mir.app, client: mir.client}
+		if err := d.run(ctx, jobs, parallel); err != nil {
+			return conflicts, err
+		}
+	}
+	return conflicts, nil
+}
+
+// syncPage はサーバーのページをローカルに反映し、衝突の数を返す。
+func (mir *wikiMirror) syncPage(ctx context.Context, page *redmine.WikiPage) (int, error) {
+	title := page.Title
+	local, exists, err := mir.readPage(title)
+	if err != nil {
+		return 0, err
+	}
+	entry := mir.manifest.Pages[title]
+
+	switch {
+	case !exists || (entry != nil && textHash(local) == entry.SHA256):
+		// ローカルは変更されていない
+		if exists && entry.Version == page.Version && sameText(local, page.Text) {
+			break
+		}
+		if err := mir.writePage(title, page.Text); err != nil {
+			return 0, err
+		}
+		if entry == nil {
+			fmt.Fprintf(mir.stdout, "Added %s (version %d)\n", title, page.Version)
+		} else {
+			fmt.Fprintf(mir.stdout, "Updated %s (version %d)\n", title, page.Version)
+		}
+	case entry != nil && entry.Version == page.Version:
+		// ローカルだけが変更されている。push で送る
+		fmt.Fprintf(mir.stdout, "Kept local changes to %s\n", title)
+		mir.entry(title).Parent = parentTitle(page)
+		return 0, nil
+	case !sameText(local, page.Text):
+		// 両方で変更された（ローカルで作ったページがサーバーにもある場合を含む）
+		conflicts, err := mir.merge(ctx, title, local, page)
+		if err != nil {
+			return 0, err
+		}
+		mir.entry(title).Parent = parentTitle(page)
+		return conflicts, nil
+	}
+
+	entry = mir.entry(title)
+	entry.Version = page.Version
+	entry.SHA256 = textHash(page.Text)
+	entry.Parent = parentTitle(page)
+	return 0, nil
+}
+
+// attachmentJobs は取得していない添付ファイルのダウンロードを返し、サーバーで削除された添付のファイルを消す。
+func (mir *wikiMirror) attachmentJobs(page *redmine.WikiPage) ([]downloadJob, error) {
+	entry := mir.entry(page.Title)
+	dir := mir.attachmentDir(page.Title)
+	old := entry.Attachments
+	entry.Attachments = nil
+
+	var jobs []downloadJob
+	for _, job := range downloadJobs(page.Attachments, "*", dir) {
+		name := filepath.Base(job.dest)
+		if entry.Attachments == nil {
+			entry.Attachments = map[string]int{}
+		}
+		entry.Attachments[name] = job.att.ID
+		if old[name] == job.att.ID {
+			if _, err := os.Stat(job.dest); err == nil {
+				continue
+			}
+		}
+		jobs = append(jobs, job)
+	}
+	for name := range old {
+		if _, ok := entry.Attachments[name]; !ok {
+			os.Remove(filepath.Join(dir, name))
+		}
+	}
+	if len(jobs) > 0 {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+	return jobs, nil
+}
+
+// removePage はサーバーで削除されたページをローカルからも消す。ローカルで変更していれば残す。
+func (mir *wikiMirror) removePage(title string) error {
+	entry := mir.manifest.Pages[title]
+	local, exists, err := mir.readPage(title)
+	if err != nil {
+		return err
+	}
+	if exists && textHash(local) != entry.SHA256 {
+		delete(mir.manifest.Pages, title)
+		fmt.Fprintf(mir.stdout, "Kept %s: deleted on the server but changed locally (push creates it again)\n", title)
+		return nil
+	}
+
+	if exists {
+		if err := os.Remove(mir.pagePath(title)); err != nil {
+			return err
+		}
+	}
+	dir := mir.attachmentDir(title)
+	for name := range entry.Attachments {
+		os.Remove(filepath.Join(dir, name))
+	}
+	// ローカルで追加したファイルが残っていれば、ディレクトリは消えない
+	os.Remove(dir)
+	delete(mir.manifest.Pages, title)
+	fmt.Fprintf(mir.stdout, "Removed %s (deleted on the server)\n", title)
+	return nil
+}
+
+func parentTitle(page *redmine.WikiPage) string {
+	if page.Parent == nil {
+		return ""
+	}
+	return page.Parent.Title
+}
+
+func newWikiPushCmd(a *app) *cobra.Command {
+	wikiPushCmd := &cobra.Command{
+		Use:   "push <dir>",
+		Short: "Upload the locally modified pages of a mirrored wiki",
+		Long: `Upload the pages of a directory created by 'rd wiki sync' that were changed locally, new pages
+(<title>.<ext> files that are not on the server yet) and new files in attachments/<title>/.
+
+A page that changed on the server since the last sync is not overwritten: the server changes are
+merged into the local file instead, with conflict markers where both sides changed the same lines,
+and the command exits with code 8 so the result can be reviewed and pushed again.
+Pages and attachments deleted locally are not deleted on the server.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := args[0]
+			comment, _ := cmd.Flags().GetString("comment")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+			m, err := loadWikiManifest(dir)
+			if err != nil {
+				return err
+			}
+			if m.Project == "" {
+				return fmt.Errorf("%s is not a wiki mirror (run 'rd wiki sync <project> %s' first)", dir, dir)
+			}
+
+			client, err := a.client(cmd)
+			if err != nil {
+				return err
+			}
+			mir := &wikiMirror{app: a, client: client, dir: dir, manifest: m}
+			titles, err := mir.pushTitles()
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			pushed := 0
+			var pending []string
+			var errs []error
+			for _, title := range titles {
+				ok, err := mir.pushPage(ctx, title, comment, dryRun)
+				switch {
+				case errors.Is(err, redmine.ErrConflict):
+					pending = append(pending, title)
+				case err != nil:
+					errs = append(errs, err)
+				case ok:
+					pushed++
+				}
+			}
+			if !dryRun {
+				if err := m.save(dir); err != nil {
+					errs = append(errs, err)
+				}
+			}
+
+			if pushed == 0 && len(pending) == 0 && len(errs) == 0 {
+				fmt.Fprintln(a.stdout, "Nothing to push")
+			}
+			if len(pending) > 0 {
+				errs = append(errs, fmt.Errorf("%w: %s changed on the server and were not pushed; review the merged files and run 'rd wiki push' again",
+					redmine.ErrConflict, strings.Join(pending, ", ")))
+			}
+			return errors.Join(errs...)
+		},
+	}
+
+	wikiPushCmd.Flags().StringP("comment", "m", "", "Comment for the new versions")
+	wikiPushCmd.Flags().Bool("dry-run", false, "Show what would be pushed without changing anything")
+	return wikiPushCmd
+}
+
+// pushTitles は記録済みのページと、ローカルで新しく作られたページの名前を返す。
+func (mir *wikiMirror) pushTitles() ([]string, error) {
+	titles := mir.manifest.titles()
+	files := map[string]bool{}
+	for _, entry := range mir.manifest.Pages {
+		files[entry.File] = true
+	}
+
+	ext := "." + mir.manifest.Extension
+	entries, err := os.ReadDir(mir.dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if !e.Type().IsRegular() || !strings.HasSuffix(name, ext) || files[name] {
+			continue
+		}
+		if title := strings.TrimSuffix(name, ext); title != "" && mir.manifest.Pages[title] == nil {
+			titles = append(titles, title)
+		}
+	}
+	sort.Strings(titles)
+	return titles, nil
+}
+
+// newAttachments はまだ添付していないローカルのファイルを返す。
+func (mir *wikiMirror) newAttachments(title string) ([]attachSpec, error) {
+	entries, err := os.ReadDir(mir.attachmentDir(title))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var known map[string]int
+	if entry := mir.manifest.Pages[title]; entry != nil {
+		known = entry.Attachments
+	}
+	var specs []attachSpec
+	for _, e := range entries {
+		name := e.Name()
+		if !e.Type().IsRegular() || strings.HasSuffix(name, ".part") {
+			continue
+		}
+		if _, ok := known[name]; ok {
+			continue
+		}
+		specs = append(specs, attachSpec{path: filepath.Join(mir.attachmentDir(title), name)})
+	}
+	return specs, nil
+}
+
+// pushPage はローカルで変更されたページを送り、送ったかどうかを返す。サーバー側も
+// 変更されていれば送らずにローカルにまとめ、ErrConflict を返す。
+func (mir *wikiMirror) pushPage(ctx context.Context, title, comment string, dryRun bool) (bool, error) {
+	entry := mir.manifest.Pages[title]
+	local, exists, err := mir.readPage(title)
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		fmt.Fprintf(mir.stdout, "Skipped %s: deleted locally (pages are not deleted on the server)\n", title)
+		return false, nil
+	}
+	modified := entry == nil || textHash(local) != entry.SHA256
+	files, err := mir.newAttachments(title)
+	if err != nil {
+		return false, err
+	}
+	if !modified && len(files) == 0 {
+		return false, nil
+	}
+	if hasConflictMarkers(splitLines(local)) {
+		return false, fmt.Errorf("%s still has conflict markers; resolve them before pushing", mir.pagePath(title))
+	}
+
+	project := mir.manifest.Project
+	page, err := mir.client.GetWikiPageContext(ctx, project, title, 0)
+	if errors.Is(err, redmine.ErrNotFound) {
+		page = nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to get wiki page %s: %w", title, err)
+	}
+
+	switch {
+	case page == nil && entry != nil:
+		fmt.Fprintf(mir.stdout, "Conflict in %s: deleted on the server since the last sync\n", title)
+		return false, redmine.ErrConflict
+	case page != nil && (entry == nil || page.Version != entry.Version):
+		if !sameText(local, page.Text) {
+			if dryRun {
+				fmt.Fprintf(mir.stdout, "Conflict in %s: changed on the server since version %d\n", title, mir.entry(title).Version)
+				return false, redmine.ErrConflict
+			}
+			if _, err := mir.merge(ctx, title, local, page); err != nil {
+				return false, err
+			}
+			return false, redmine.ErrConflict
+		}
+		// サーバーに同じ内容が既にある
+		modified = false
+		if len(files) == 0 {
+			if !dryRun {
+				entry = mir.entry(title)
+				entry.Version = page.Version
+				entry.SHA256 = textHash(page.Text)
+			}
+			return false, nil
+		}
+	}
+
+	if dryRun {
+		switch {
+		case page == nil:
+			fmt.Fprintf(mir.stdout, "Would create %s\n", title)
+		case modified:
+			fmt.Fprintf(mir.stdout, "Would push %s\n", title)
+		}
+		if len(files) > 0 {
+			fmt.Fprintf(mir.stdout, "Would attach %d file(s) to %s\n", len(files), title)
+		}
+		return true, nil
+	}
+
+	uploads, err := uploadAttachments(ctx, mir.client, files)
+	if err != nil {
+		return false, err
+	}
+	update := &redmine.WikiPageUpdate{Text: local, Comments: comment, Uploads: uploads}
+	if page != nil {
+		update.Version = page.Version
+	}
+	if err := mir.client.PutWikiPageContext(ctx, project, title, update); err != nil {
+		if errors.Is(err, redmine.ErrConflict) {
+			fmt.Fprintf(mir.stdout, "Conflict in %s: changed on the server while pushing; run 'rd wiki sync' to merge\n", title)
+			return false, err
+		}
+		return false, fmt.Errorf("failed to save wiki page %s: %w", title, err)
+	}
+
+	saved, err := mir.client.GetWikiPageContext(ctx, project, title, 0)
+	if err != nil {
+		return true, fmt.Errorf("%s was saved but could not be read back: %w", title, err)
+	}
+	if err := mir.recordPushed(title, saved, uploads); err != nil {
+		return true, err
+	}
+
+	switch {
+	case page == nil:
+		fmt.Fprintf(mir.stdout, "Created %s (version %d)\n", saved.Title, saved.Version)
+	case modified:
+		fmt.Fprintf(mir.stdout, "Pushed %s (version %d)\n", saved.Title, saved.Version)
+	}
+	if len(files) > 0 {
+		fmt.Fprintf(mir.stdout, "Attached %d file(s) to %s\n", len(files), saved.Title)
+	}
+	return true, nil
+}
+
+// recordPushed は送ったページの版と添付したファイルを記録する。サーバーがタイトルを
+// 変えた（空白を _ にするなど）ときは、ローカルのファイルもその名前にする。
+func (mir *wikiMirror) recordPushed(title string, saved *redmine.WikiPage, uploaded []redmine.Upload) error {
+	local, _, err := mir.readPage(title)
+	if err != nil {
+		return err
+	}
+	if saved.Title != title {
+		oldPath, oldDir := mir.pagePath(title), mir.attachmentDir(title)
+		delete(mir.manifest.Pages, title)
+		if err := os.Rename(oldPath, mir.pagePath(saved.Title)); err != nil {
+			return err
+		}
+		if _, err := os.Stat(oldDir); err == nil {
+			if err := os.Rename(oldDir, mir.attachmentDir(saved.Title)); err != nil {
+				return err
+			}
+		}
+		title = saved.Title
+	}
+	// サーバー側で整形された場合も次回変更ありと判定されないよう、保存された本文に揃える
+	if !sameText(local, saved.Text) {
+		if err := mir.writePage(title, saved.Text); err != nil {
+			return err
+		}
+	}
+
+	entry := mir.entry(title)
+	entry.Version = saved.Version
+	entry.SHA256 = textHash(saved.Text)
+	entry.Parent = parentTitle(saved)
+	if entry.Attachments == nil && len(uploaded) > 0 {
+		entry.Attachments = map[string]int{}
+	}
+	known := map[int]bool{}
+	for _, id := range entry.Attachments {
+		known[id] = true
+	}
+	// 送ったファイルは、同じ名前の添付のうち新しく増えたものに対応付ける
+	for _, u := range uploaded {
+		for _, att := range saved.Attachments {
+			if att.Filename == u.Filename && !known[att.ID] && att.ID > entry.Attachments[u.Filename] {
+				entry.Attachments[u.Filename] = att.ID
+			}
+		}
+		known[entry.Attachments[u.Filename]] = true
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+)
+
+func TestWikiSyncAndPush(t *testing.T) {
+	srv := newTestServer(t)
+	client := srv.Client()
+	srv.PutWikiPage("demo", "Home", "welcome\n")
+	srv.PutWikiPage("demo", "Spec", "one\ntwo\n")
+	srv.PutWikiPage("demo", "Old", "obsolete\n")
+	dir := t.TempDir()
+
+	out := mustRun(t, client, "wiki", "sync", "demo", dir)
+	if !strings.Contains(out, "Synced 3 page(s) of demo") {
+		t.Errorf("sync printed %q", out)
+	}
+	if got := readFile(t, filepath.Join(dir, "Spec.textile")); got != "one\ntwo\n" {
+		t.Errorf("Spec.textile = %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, wikiManifestFile)); err != nil {
+		t.Fatalf("no manifest: %v", err)
+	}
+	if out := mustRun(t, client, "wiki", "push", dir); !strings.Contains(out, "Nothing to push") {
+		t.Errorf("push right after sync printed %q", out)
+	}
+
+	// サーバーの更新と削除は変更していないローカルのファイルに反映される
+	srv.PutWikiPage("demo", "Home", "welcome back\n")
+	if err := client.DeleteWikiPageContext(context.Background(), "demo", "Old"); err != nil {
+		t.Fatal(err)
+	}
+	out = mustRun(t, client, "wiki", "sync", "demo", dir)
+	if got := readFile(t, filepath.Join(dir, "Home.textile")); got != "welcome back\n" {
+		t.Errorf("Home.textile = %q after the server update", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "Old.textile")); !os.IsNotExist(err) {
+		t.Errorf("Old.textile was kept after the server deleted it (err = %v)", err)
+	}
+	if !strings.Contains(out, "Updated Home") || !strings.Contains(out, "Removed Old") {
+		t.Errorf("sync printed %q", out)
+	}
+
+	// ローカルの変更・新しいページ・新しい添付ファイルを送る
+	writeFile(t, filepath.Join(dir, "Spec.textile"), "one\ntwo\nthree\n")
+	writeFile(t, filepath.Join(dir, "New.textile"), "created locally\n")
+	if err := os.MkdirAll(filepath.Join(dir, "attachments", "Spec"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "attachments", "Spec", "diagram.txt"), "boxes\n")
+
+	out = mustRun(t, client, "wiki", "push", dir, "--dry-run")
+	for _, want := range []string{"Would push Spec", "Would create New", "Would attach 1 file(s) to Spec"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("push --dry-run output lacks %q:\n%s", want, out)
+		}
+	}
+	if got := wikiText(t, client, "Spec"); got != "one\ntwo\n" {
+		t.Fatalf("push --dry-run changed Spec to %q", got)
+	}
+
+	mustRun(t, client, "wiki", "push", dir, "-m", "from the mirror")
+	if got := wikiText(t, client, "Spec"); got != "one\ntwo\nthree\n" {
+		t.Errorf("Spec = %q after push", got)
+	}
+	if got := wikiText(t, client, "New"); got != "created locally\n" {
+		t.Errorf("New = %q after push", got)
+	}
+	if out := mustRun(t, client, "wiki", "push", dir); !strings.Contains(out, "Nothing to push") {
+		t.Errorf("second push printed %q", out)
+	}
+
+	// 別のミラーには添付ファイルも取得される
+	other := t.TempDir()
+	mustRun(t, client, "wiki", "sync", "demo", other)
+	if got := readFile(t, filepath.Join(other, "attachments", "Spec", "diagram.txt")); got != "boxes\n" {
+		t.Errorf("synced attachment = %q", got)
+	}
+}
+
+func TestWikiSyncMergesServerChanges(t *testing.T) {
+	srv := newTestServer(t)
+	client := srv.Client()
+	srv.PutWikiPage("demo", "Spec", "a\nb\nc\nd\n")
+	dir := t.TempDir()
+	file := filepath.Join(dir, "Spec.textile")
+	mustRun(t, client, "wiki", "sync", "demo", dir)
+
+	// 別々の行の変更はまとめられ、次の push で送られる
+	writeFile(t, file, "A\nb\nc\nd\n")
+	srv.PutWikiPage("demo", "Spec", "a\nb\nc\nD\n")
+	out := mustRun(t, client, "wiki", "sync", "demo", dir)
+	if !strings.Contains(out, "Merged server version") {
+		t.Errorf("sync printed %q", out)
+	}
+	if got := readFile(t, file); got != "A\nb\nc\nD\n" {
+		t.Fatalf("merged file = %q", got)
+	}
+	mustRun(t, client, "wiki", "push", dir)
+	if got := wikiText(t, client, "Spec"); got != "A\nb\nc\nD\n" {
+		t.Fatalf("Spec = %q after pushing the merge", got)
+	}
+
+	// 同じ行の変更は衝突として残し、解決するまで push しない
+	writeFile(t, file, "local\nb\nc\nD\n")
+	srv.PutWikiPage("demo", "Spec", "server\nb\nc\nD\n")
+	_, err := runRD(t, client, "wiki", "push", dir)
+	if !errors.Is(err, redmine.ErrConflict) || exitCode(err) != exitConflict {
+		t.Fatalf("push over a server change: err = %v, want exit %d", err, exitConflict)
+	}
+	if got := readFile(t, file); !strings.Contains(got, "<<<<<<< local\nlocal\n=======\nserver\n>>>>>>> server") {
+		t.Fatalf("file has no conflict markers:\n%s", got)
+	}
+	if _, err := runRD(t, client, "wiki", "push", dir); err == nil || !strings.Contains(err.Error(), "conflict markers") {
+		t.Fatalf("push with markers: err = %v", err)
+	}
+	if got := wikiText(t, client, "Spec"); got != "server\nb\nc\nD\n" {
+		t.Fatalf("server text was overwritten: %q", got)
+	}
+
+	writeFile(t, file, "resolved\nb\nc\nD\n")
+	mustRun(t, client, "wiki", "push", dir)
+	if got := wikiText(t, client, "Spec"); got != "resolved\nb\nc\nD\n" {
+		t.Errorf("Spec = %q after resolving", got)
+	}
+}
+
+func TestWikiSyncRefusesOtherProjectsMirror(t *testing.T) {
+	srv := newTestServer(t)
+	srv.AddProject("other", "Other")
+	client := srv.Client()
+	dir := t.TempDir()
+	mustRun(t, client, "wiki", "sync", "demo", dir)
+	if _, err := runRD(t, client, "wiki", "sync", "other", dir); err == nil || !strings.Contains(err.Error(), "mirror of project demo") {
+		t.Errorf("err = %v, want a mirror of project demo", err)
+	}
+	if _, err := runRD(t, client, "wiki", "push", t.TempDir()); err == nil || !strings.Contains(err.Error(), "not a wiki mirror") {
+		t.Errorf("push of a plain directory: err = %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+)
+
+func wikiText(t *testing.T, client redmine.API, title string) string {
+	t.Helper()
+	page, err := client.GetWikiPageContext(context.Background(), "demo", title, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return page.Text
+}
+
+func TestWikiPutRequiresBaseVersionForExistingPage(t *testing.T) {
+	srv := newTestServer(t)
+	client := srv.Client()
+	file := filepath.Join(t.TempDir(), "spec.textile")
+
+	writeFile(t, file, "first\n")
+	mustRun(t, client, "wiki", "put", "demo", "Spec", file) // 新規作成には不要
+	srv.PutWikiPage("demo", "Spec", "edited in the browser\n")
+
+	writeFile(t, file, "mine\n")
+	_, err := runRD(t, client, "wiki", "put", "demo", "Spec", file)
+	if err == nil || !strings.Contains(err.Error(), "--version") {
+		t.Fatalf("put without --version: err = %v, want a request for --version", err)
+	}
+	if _, err := runRD(t, client, "wiki", "put", "demo", "Spec", file, "--version", "1"); !errors.Is(err, redmine.ErrConflict) {
+		t.Fatalf("put based on version 1: err = %v, want ErrConflict", err)
+	}
+	if got := wikiText(t, client, "Spec"); got != "edited in the browser\n" {
+		t.Fatalf("server text was overwritten: %q", got)
+	}
+
+	mustRun(t, client, "wiki", "put", "demo", "Spec", file, "--version", "2")
+	if got := wikiText(t, client, "Spec"); got != "mine\n" {
+		t.Fatalf("text = %q after put --version 2", got)
+	}
+	writeFile(t, file, "forced\n")
+	mustRun(t, client, "wiki", "put", "demo", "Spec", file, "--force")
+	if got := wikiText(t, client, "Spec"); got != "forced\n" {
+		t.Fatalf("text = %q after put --force", got)
+	}
+}
+
+func TestWikiPutUsesSyncedVersion(t *testing.T) {
+	srv := newTestServer(t)
+	client := srv.Client()
+	srv.PutWikiPage("demo", "Spec", "synced\n")
+	dir := t.TempDir()
+	mustRun(t, client, "wiki", "sync", "demo", dir)
+
+	file := filepath.Join(dir, "Spec.textile")
+	writeFile(t, file, "local edit\n")
+	mustRun(t, client, "wiki", "put", "demo", "Spec", file)
+	if got := wikiText(t, client, "Spec"); got != "local edit\n" {
+		t.Fatalf("text = %q", got)
+	}
+
+	// put した版が記録されるので、続けて put できる
+	writeFile(t, file, "another local edit\n")
+	mustRun(t, client, "wiki", "put", "demo", "Spec", file)
+
+	// 同期後にサーバー側が更新されていれば上書きしない
+	srv.PutWikiPage("demo", "Spec", "browser edit\n")
+	writeFile(t, file, "second local edit\n")
+	if _, err := runRD(t, client, "wiki", "put", "demo", "Spec", file); !errors.Is(err, redmine.ErrConflict) {
+		t.Fatalf("err = %v, want ErrConflict", err)
+	}
+}
@@ -6,12 +6,42 @@ import (
     "fmt"
     "os"
     "path/filepath"
+    "strconv"
     "strings"
+    "time"
 )
 
 type Config struct {
     RedmineURL string
     APIKey     string
+    SwitchUser string // login to impersonate via X-Redmine-Switch-User
+
+    // Alternative credential sources (see resolveCredentials)
+    Login      string
+    Password   string
+    KeyCommand string
+    KeyFile    string
+
+    // HTTP transport settings
+    CAFile     string
+    ClientCert string
+    ClientKey  string
+    Proxy      string
+    Timeout    time.Duration
+    Insecure   *bool
+
+    // Retry settings (nil/zero means "use the client default")
+    Retries      *int
+    RetryMinWait time.Duration
+    RetryMaxWait time.Duration
+
+    // Client-side rate limit (0 means unlimited)
+    MaxRPS float64
+    Burst  int
+
+    // Metadata cache settings
+    NoCache   *bool
+    CacheTTLs map[string]time.Duration // keyed by lower-case resource name, "" applies to all
 }
 
 // Load resolves configuration in the following priority:
@@ -35,6 +65,7 @@ func Load(urlFlag, keyFlag string) (*Config, error) {
     applyIfEmpty(cfg, &Config{
         RedmineURL: os.Getenv("REDMINE_URL"),
         APIKey:     os.Getenv("REDMINE_API_KEY"),
+        SwitchUser: os.Getenv("REDMINE_SWITCH_USER"),
     })
 
     // 3-5) .rd files
@@ -43,9 +74,11 @@ func Load(urlFlag, keyFlag string) (*Config, error) {
             continue
         }
         if _, err := os.Stat(p); err == nil {
-            if fileCfg, err := loadFromRD(p); err == nil {
-                applyIfEmpty(cfg, fileCfg)
+            fileCfg, err := loadFromRD(p)
+            if err != nil {
+                return nil, fmt.Errorf("%s: %w", p, err)
             }
+            applyIfEmpty(cfg, fileCfg)
         }
     }
 
@@ -53,20 +86,79 @@ func Load(urlFlag, keyFlag string) (*Config, error) {
     if cfg.RedmineURL == "" {
         return nil, fmt.Errorf("Redmine URL is not set. Set via --url, REDMINE_URL, or .rd")
     }
-    if cfg.APIKey == "" {
-        return nil, fmt.Errorf("Redmine API key is not set. Set via --key, REDMINE_API_KEY, or .rd")
+    if err := resolveCredentials(cfg); err != nil {
+        return nil, err
+    }
+    if cfg.APIKey == "" && cfg.Login == "" {
+        return nil, fmt.Errorf("Redmine API key is not set. Set via --key, REDMINE_API_KEY, or .rd (key, key_file, key_command, or login/password)")
     }
     return cfg, nil
 }
 
 // applyIfEmpty copies non-empty fields from src to dst, but only when dst fields are empty.
+// Credentials are taken as a whole from the first source that names any of them,
+// so a key in ~/.rd never beats a key_command or login in a project .rd.
 func applyIfEmpty(dst, src *Config) {
     if dst.RedmineURL == "" && src.RedmineURL != "" {
         dst.RedmineURL = src.RedmineURL
     }
-    if dst.APIKey == "" && src.APIKey != "" {
+    if !hasCredentials(dst) && hasCredentials(src) {
         dst.APIKey = src.APIKey
+        dst.Login = src.Login
+        dst.Password = src.Password
+        dst.KeyCommand = src.KeyCommand
+        dst.KeyFile = src.KeyFile
+    }
+    if dst.SwitchUser == "" && src.SwitchUser != "" {
+        dst.SwitchUser = src.SwitchUser
+    }
+    if dst.CAFile == "" && src.CAFile != "" {
+        dst.CAFile = src.CAFile
+    }
+    if dst.ClientCert == "" && src.ClientCert != "" {
+        dst.ClientCert = src.ClientCert
+        dst.ClientKey = src.ClientKey
+    }
+    if dst.Proxy == "" && src.Proxy != "" {
+        dst.Proxy = src.Proxy
+    }
+    if dst.Timeout == 0 && src.Timeout != 0 {
+        dst.Timeout = src.Timeout
+    }
+    if dst.Insecure == nil && src.Insecure != nil {
+        dst.Insecure = src.Insecure
+    }
+    if dst.Retries == nil && src.Retries != nil {
+        dst.Retries = src.Retries
+    }
+    if dst.RetryMinWait == 0 && src.RetryMinWait != 0 {
+        dst.RetryMinWait = src.RetryMinWait
+    }
+    if dst.RetryMaxWait == 0 && src.RetryMaxWait != 0 {
+        dst.RetryMaxWait = src.RetryMaxWait
+    }
+    if dst.MaxRPS == 0 && src.MaxRPS != 0 {
+        dst.MaxRPS = src.MaxRPS
+    }
+    if dst.Burst == 0 && src.Burst != 0 {
+        dst.Burst = src.Burst
+    }
+    if dst.NoCache == nil && src.NoCache != nil {
+        dst.NoCache = src.NoCache
     }
+    for k, v := range src.CacheTTLs {
+        if _, ok := dst.CacheTTLs[k]; !ok {
+            if dst.CacheTTLs == nil {
+                dst.CacheTTLs = map[string]time.Duration{}
+            }
+            dst.CacheTTLs[k] = v
+        }
+    }
+}
+
+// hasCredentials reports whether c names a credential source.
+func hasCredentials(c *Config) bool {
+    return c.APIKey != "" || c.Login != "" || c.KeyCommand != "" || c.KeyFile != ""
 }
 
 // candidateConfigPaths returns .rd candidate paths in priority order (highest first)
@@ -125,6 +217,13 @@ func findGitRoot() (string, error) {
 // - Supported keys (case-insensitive):
 //     REDMINE_URL, URL
 //     REDMINE_API_KEY, API_KEY, KEY
+//     REDMINE_SWITCH_USER, SWITCH_USER, AS
+//     LOGIN, PASSWORD (HTTP basic authentication)
+//     KEY_COMMAND (command printing the API key), KEY_FILE (file holding the API key)
+//     CA_FILE, CLIENT_CERT, CLIENT_KEY, PROXY, TIMEOUT (duration), INSECURE (true/false)
+//     RETRIES, RETRY_WAIT_MIN, RETRY_WAIT_MAX (durations like 500ms, 30s)
+//     MAX_RPS or RATE (requests per second), BURST
+//     CACHE (on/off), CACHE_TTL, CACHE_TTL_<RESOURCE> (e.g. CACHE_TTL_VERSIONS=10m)
 func loadFromRD(path string) (*Config, error) {
     f, err := os.Open(path)
     if err != nil {
@@ -158,8 +257,102 @@ func loadFromRD(path string) (*Config, error) {
             if cfg.APIKey == "" {
                 cfg.APIKey = val
             }
+        case "LOGIN":
+            cfg.Login = val
+        case "PASSWORD":
+            cfg.Password = val
+        case "KEY_COMMAND":
+            cfg.KeyCommand = val
+        case "KEY_FILE":
+            cfg.KeyFile = val
+        case "REDMINE_SWITCH_USER", "SWITCH_USER", "AS":
+            if cfg.SwitchUser == "" {
+                cfg.SwitchUser = val
+            }
+        case "CA_FILE":
+            cfg.CAFile = expandHome(val)
+        case "CLIENT_CERT":
+            cfg.ClientCert = expandHome(val)
+        case "CLIENT_KEY":
+            cfg.ClientKey = expandHome(val)
+        case "PROXY":
+            cfg.Proxy = val
+        case "TIMEOUT":
+            d, err := time.ParseDuration(val)
+            if err != nil {
+                return nil, fmt.Errorf("invalid %s: %w", key, err)
+            }
+            cfg.Timeout = d
+        case "INSECURE":
+            b, err := parseBool(val)
+            if err != nil {
+                return nil, fmt.Errorf("invalid %s: %q", key, val)
+            }
+            cfg.Insecure = &b
+        case "RETRIES":
+            n, err := strconv.Atoi(val)
+            if err != nil || n < 0 {
+                return nil, fmt.Errorf("invalid %s: %q", key, val)
+            }
+            cfg.Retries = &n
+        case "RETRY_WAIT_MIN":
+            d, err := time.ParseDuration(val)
+            if err != nil {
+                return nil, fmt.Errorf("invalid %s: %w", key, err)
+            }
+            cfg.RetryMinWait = d
+        case "RETRY_WAIT_MAX":
+            d, err := time.ParseDuration(val)
+            if err != nil {
+                return nil, fmt.Errorf("invalid %s: %w", key, err)
+            }
+            cfg.RetryMaxWait = d
+        case "MAX_RPS", "RATE":
+            rps, err := strconv.ParseFloat(val, 64)
+            if err != nil || rps < 0 {
+                return nil, fmt.Errorf("invalid %s: %q", key, val)
+            }
+            cfg.MaxRPS = rps
+        case "BURST":
+            n, err := strconv.Atoi(val)
+            if err != nil || n < 1 {
+                return nil, fmt.Errorf("invalid %s: %q", key, val)
+            }
+            cfg.Burst = n
+        case "CACHE":
+            on, err := parseBool(val)
+            if err != nil {
+                return nil, fmt.Errorf("invalid %s: %q", key, val)
+            }
+            noCache := !on
+            cfg.NoCache = &noCache
+        default:
+            if lk == "CACHE_TTL" || strings.HasPrefix(lk, "CACHE_TTL_") {
+                d, err := time.ParseDuration(val)
+                if err != nil {
+                    return nil, fmt.Errorf("invalid %s: %w", key, err)
+                }
+                if cfg.CacheTTLs == nil {
+                    cfg.CacheTTLs = map[string]time.Duration{}
+                }
+                cfg.CacheTTLs[strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(lk, "CACHE_TTL"), "_"))] = d
+            }
         }
     }
     // Ignore scanner.Err() to keep robust; caller treats empty cfg as no data
+    if cfg.APIKey != "" || cfg.Password != "" {
+        warnIfExposed(path, f)
+    }
     return cfg, nil
 }
+
+// parseBool accepts the usual spellings of on/off used in .rd files.
+func parseBool(s string) (bool, error) {
+    switch strings.ToLower(s) {
+    case "1", "true", "yes", "on":
+        return true, nil
+    case "0", "false", "no", "off":
+        return false, nil
+    }
+    return false, fmt.Errorf("invalid boolean %q", s)
+}
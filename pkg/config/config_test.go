@@ -0,0 +1,144 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupRD writes ~/.rd and a project .rd (the working directory) and clears
+// the environment variables Load reads.
+func setupRD(t *testing.T, home, project string) {
+	t.Helper()
+	homeDir, projectDir := t.TempDir(), t.TempDir()
+	if err := os.WriteFile(filepath.Join(homeDir, ".rd"), []byte(home), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(projectDir, ".rd"), []byte(project), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", homeDir)
+	for _, name := range []string{"REDMINE_URL", "REDMINE_API_KEY", "REDMINE_SWITCH_USER"} {
+		t.Setenv(name, "")
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(projectDir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func TestLoadTakesCredentialsFromOneFile(t *testing.T) {
+	home := "url=https://redmine.example.com\nkey=home-key\n"
+	tests := []struct {
+		name    string
+		project string
+		key     string
+		login   string
+	}{
+		{"project key_command beats home key", "key_command=echo project-key\n", "project-key", ""},
+		{"project login beats home key", "login=alice\npassword=secret\n", "", "alice"},
+		{"home key is used when the project names none", "timeout=5s\n", "home-key", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupRD(t, home, tt.project)
+			cfg, err := Load("", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.APIKey != tt.key || cfg.Login != tt.login {
+				t.Errorf("APIKey=%q Login=%q, want %q %q", cfg.APIKey, cfg.Login, tt.key, tt.login)
+			}
+		})
+	}
+}
+
+func TestLoadProjectTurnsOffHomeBooleans(t *testing.T) {
+	setupRD(t, "url=https://redmine.example.com\nkey=k\ninsecure=true\ncache=off\n", "insecure=false\ncache=on\n")
+	cfg, err := Load("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Insecure == nil || *cfg.Insecure {
+		t.Errorf("Insecure = %v, want false from the project .rd", cfg.Insecure)
+	}
+	if cfg.NoCache == nil || *cfg.NoCache {
+		t.Errorf("NoCache = %v, want false from the project .rd", cfg.NoCache)
+	}
+}
+
+func TestKeyCommandDoesNotReadStdin(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	w.WriteString("piped-input\n")
+	w.Close()
+	stdin := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = stdin }()
+
+	key, err := runKeyCommand("read line; echo \"cmd-${line}\"")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key != "cmd-" {
+		t.Errorf("key = %q, the command read rd's stdin", key)
+	}
+	rest := make([]byte, 32)
+	n, _ := r.Read(rest)
+	if string(rest[:n]) != "piped-input\n" {
+		t.Errorf("stdin left for rd = %q", rest[:n])
+	}
+}
+
+func TestLoadReadsKeyFromKeyFileOrCommand(t *testing.T) {
+	keyDir := t.TempDir()
+	keyFile := filepath.Join(keyDir, "key")
+	if err := os.WriteFile(keyFile, []byte("file-key\nignored\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	emptyFile := filepath.Join(keyDir, "empty")
+	if err := os.WriteFile(emptyFile, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		project string
+		key     string
+		wantErr string
+	}{
+		{"key_file takes the first line", "key_file=" + keyFile + "\n", "file-key", ""},
+		{"key_file wins over key_command", "key_file=" + keyFile + "\nkey_command=echo cmd-key\n", "file-key", ""},
+		{"key_command", "key_command=printf 'cmd-key\\n'\n", "cmd-key", ""},
+		{"missing key_file", "key_file=" + filepath.Join(keyDir, "missing") + "\n", "", "failed to read key_file"},
+		{"empty key_file", "key_file=" + emptyFile + "\n", "", "is empty"},
+		{"failing key_command", "key_command=exit 1\n", "", "key_command failed"},
+		{"silent key_command", "key_command=true\n", "", "printed no key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupRD(t, "url=https://redmine.example.com\n", tt.project)
+			cfg, err := Load("", "")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.APIKey != tt.key {
+				t.Errorf("APIKey = %q, want %q", cfg.APIKey, tt.key)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+    "bytes"
+    "fmt"
+    "os"
+    "os/exec"
+    "path/filepath"
+    "runtime"
+    "strings"
+)
+
+// resolveCredentials fills APIKey from KeyFile or KeyCommand when no key was
+// given directly. All of them come from the same source (see applyIfEmpty);
+// within it an explicit key wins, then the key file, then the command, then login.
+func resolveCredentials(cfg *Config) error {
+    if cfg.APIKey != "" {
+        return nil
+    }
+    if cfg.KeyFile != "" {
+        data, err := os.ReadFile(expandHome(cfg.KeyFile))
+        if err != nil {
+            return fmt.Errorf("failed to read key_file: %w", err)
+        }
+        cfg.APIKey = firstLine(string(data))
+        if cfg.APIKey == "" {
+            return fmt.Errorf("key_file %s is empty", cfg.KeyFile)
+        }
+        return nil
+    }
+    if cfg.KeyCommand != "" {
+        key, err := runKeyCommand(cfg.KeyCommand)
+        if err != nil {
+            return err
+        }
+        cfg.APIKey = key
+    }
+    return nil
+}
+
+// runKeyCommand runs command through the shell (e.g. "pass show redmine") and
+// returns the first line of its output. stderr is passed through so that
+// prompts stay visible; stdin is not, as it may be input piped to rd itself.
+func runKeyCommand(command string) (string, error) {
+    var cmd *exec.Cmd
+    if runtime.GOOS == "windows" {
+        cmd = exec.Command("cmd", "/C", command)
+    } else {
+        cmd = exec.Command("sh", "-c", command)
+    }
+    var out bytes.Buffer
+    cmd.Stdout = &out
+    cmd.Stderr = os.Stderr
+    if err := cmd.Run(); err != nil {
+        return "", fmt.Errorf("key_command failed: %w", err)
+    }
+    key := firstLine(out.String())
+    if key == "" {
+        return "", fmt.Errorf("key_command printed no key")
+    }
+    return key, nil
+}
+
+// warnIfExposed prints a warning when a .rd holding credentials can be read
+// by group or others.
+func warnIfExposed(path string, f *os.File) {
+    if runtime.GOOS == "windows" {
+        return
+    }
+    info, err := f.Stat()
+    if err != nil {
+        return
+    }
+    if info.Mode().Perm()&0o077 != 0 {
+        fmt.Fprintf(os.Stderr, "warning: %s contains credentials but is readable by others (mode %04o); run: chmod 600 %s\n",
+            path, info.Mode().Perm(), path)
+    }
+}
+
+func firstLine(s string) string {
+    if i := strings.IndexAny(s, "\r\n"); i >= 0 {
+        s = s[:i]
+    }
+    return strings.TrimSpace(s)
+}
+
+func expandHome(path string) string {
+    if path == "~" || strings.HasPrefix(path, "~/") {
+        if home, err := os.UserHomeDir(); err == nil {
+            return filepath.Join(home, path[1:])
+        }
+    }
+    return path
+}
@@ -0,0 +1,80 @@
+package redmine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// API is the Redmine surface used by the rd commands. *Client implements it;
+// tests can substitute a fake.
+type API interface {
+	// IssueURL returns the web URL of an issue.
+	IssueURL(id int) string
+
+	ListIssuesContext(ctx context.Context, filter *IssueFilter) (*IssuesResponse, error)
+	GetIssueWithContext(ctx context.Context, id int, include ...string) (*Issue, error)
+	CreateIssueContext(ctx context.Context, issue *IssueCreate) (*Issue, error)
+	UpdateIssueContext(ctx context.Context, id int, update *IssueUpdate) error
+	IssuePaginator(filter *IssueFilter) *Paginator[Issue]
+	UploadContext(ctx context.Context, filename string, body io.ReadSeeker, size int64) (string, error)
+	ListAttachmentsContext(ctx context.Context, issueID int) ([]Attachment, error)
+	GetAttachmentContext(ctx context.Context, id int) (*Attachment, error)
+	OpenAttachmentContext(ctx context.Context, a *Attachment, offset int64) (io.ReadCloser, int64, error)
+
+	ListRelationsContext(ctx context.Context, issueID int) ([]Relation, error)
+	CreateRelationContext(ctx context.Context, issueID int, relation *RelationCreate) (*Relation, error)
+	DeleteRelationContext(ctx context.Context, id int) error
+
+	AddWatcherContext(ctx context.Context, issueID, userID int) error
+	RemoveWatcherContext(ctx context.Context, issueID, userID int) error
+
+	ListWikiPagesContext(ctx context.Context, projectID string) ([]WikiPageInfo, error)
+	GetWikiPageContext(ctx context.Context, projectID, title string, version int) (*WikiPage, error)
+	PutWikiPageContext(ctx context.Context, projectID, title string, page *WikiPageUpdate) error
+	DeleteWikiPageContext(ctx context.Context, projectID, title string) error
+	WikiPageHistoryContext(ctx context.Context, projectID, title string, limit int) ([]WikiPage, error)
+
+	ListTimeEntriesContext(ctx context.Context, filter *TimeEntryFilter) (*TimeEntriesResponse, error)
+	TimeEntryPaginator(filter *TimeEntryFilter) *Paginator[TimeEntry]
+	GetTimeEntryContext(ctx context.Context, id int) (*TimeEntry, error)
+	CreateTimeEntryContext(ctx context.Context, entry *TimeEntryCreate) (*TimeEntry, error)
+	UpdateTimeEntryContext(ctx context.Context, id int, update *TimeEntryUpdate) error
+	DeleteTimeEntryContext(ctx context.Context, id int) error
+
+	ListProjectsContext(ctx context.Context) (*ProjectsResponse, error)
+	GetProjectContext(ctx context.Context, id string) (*ProjectDetail, error)
+	ProjectPaginator() *Paginator[Project]
+
+	ListCustomFieldsContext(ctx context.Context) ([]CustomFieldDefinition, error)
+	FindCustomFieldByNameContext(ctx context.Context, name string) (*CustomFieldDefinition, error)
+	ResolveCustomFieldContext(ctx context.Context, projectID string, issueID int, name string) (*CustomFieldDefinition, error)
+	ListVersionsContext(ctx context.Context, projectID string) (*VersionsResponse, error)
+	FindVersionByNameContext(ctx context.Context, projectID, versionName string) (*Version, error)
+	ListTrackersContext(ctx context.Context) ([]Tracker, error)
+	ListIssueStatusesContext(ctx context.Context) ([]IssueStatus, error)
+	ListIssuePrioritiesContext(ctx context.Context) ([]IssuePriority, error)
+	ListIssueCategoriesContext(ctx context.Context, projectID string) ([]IssueCategory, error)
+	ResolveStatusContext(ctx context.Context, value string) (*IssueStatus, error)
+	ResolveTrackerContext(ctx context.Context, value string) (*Tracker, error)
+	ResolvePriorityContext(ctx context.Context, value string) (*IssuePriority, error)
+	ResolveCategoryContext(ctx context.Context, projectID, value string) (*IssueCategory, error)
+	ListQueriesContext(ctx context.Context) ([]Query, error)
+	ResolveQueryContext(ctx context.Context, value string) (*Query, error)
+	GetCurrentUserContext(ctx context.Context) (*UserDetail, error)
+	ListMembershipsContext(ctx context.Context, projectID string) ([]Membership, error)
+	ResolveUserContext(ctx context.Context, projectID, value string) (*User, error)
+	ListTimeEntryActivitiesContext(ctx context.Context) ([]TimeEntryActivity, error)
+	FindTimeEntryActivityByNameContext(ctx context.Context, name string) (*TimeEntryActivity, error)
+
+	SearchContext(ctx context.Context, opts *SearchOptions) (*SearchResponse, error)
+	SearchPaginator(opts *SearchOptions) *Paginator[SearchResult]
+}
+
+var _ API = (*Client)(nil)
+
+// IssueURL returns the web URL of an issue.
+func (c *Client) IssueURL(id int) string {
+	return fmt.Sprintf("%s/issues/%d", strings.TrimRight(c.BaseURL, "/"), id)
+}
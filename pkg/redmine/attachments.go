@@ -0,0 +1,179 @@
+package redmine
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type Attachment struct {
+	ID           int       `json:"id"`
+	Filename     string    `json:"filename"`
+	Filesize     int64     `json:"filesize"`
+	ContentType  string    `json:"content_type"`
+	Description  string    `json:"description"`
+	ContentURL   string    `json:"content_url"`
+	ThumbnailURL string    `json:"thumbnail_url,omitempty"`
+	Digest       string    `json:"digest"`
+	Author       User      `json:"author"`
+	CreatedOn    time.Time `json:"created_on"`
+}
+
+type AttachmentResponse struct {
+	Attachment Attachment `json:"attachment"`
+}
+
+// NewDigest returns the hash matching a Redmine attachment digest: MD5 for
+// 32 hex characters (Redmine < 4.2), SHA-256 for 64. It returns nil when the
+// digest is empty or unrecognized.
+func NewDigest(digest string) hash.Hash {
+	switch len(digest) {
+	case md5.Size * 2:
+		return md5.New()
+	case sha256.Size * 2:
+		return sha256.New()
+	}
+	return nil
+}
+
+// DigestMatches reports whether h, fed with the file content, produced digest.
+func DigestMatches(h hash.Hash, digest string) bool {
+	return strings.EqualFold(hex.EncodeToString(h.Sum(nil)), digest)
+}
+
+func (c *Client) GetAttachment(id int) (*Attachment, error) {
+	return c.GetAttachmentContext(context.Background(), id)
+}
+
+// GetAttachmentContext is like GetAttachment but uses ctx for the request.
+func (c *Client) GetAttachmentContext(ctx context.Context, id int) (*Attachment, error) {
+	var response AttachmentResponse
+	if err := c.GetContext(ctx, fmt.Sprintf("/attachments/%d.json", id), nil, &response); err != nil {
+		return nil, err
+	}
+	return &response.Attachment, nil
+}
+
+// ListAttachments returns the files attached to an issue.
+func (c *Client) ListAttachments(issueID int) ([]Attachment, error) {
+	return c.ListAttachmentsContext(context.Background(), issueID)
+}
+
+// ListAttachmentsContext is like ListAttachments but uses ctx for the request.
+func (c *Client) ListAttachmentsContext(ctx context.Context, issueID int) ([]Attachment, error) {
+	params := url.Values{}
+	params.Set("include", "attachments")
+
+	var response IssueResponse
+	if err := c.GetContext(ctx, fmt.Sprintf("/issues/%d.json", issueID), params, &response); err != nil {
+		return nil, err
+	}
+	return response.Issue.Attachments, nil
+}
+
+// OpenAttachment starts downloading the content of a. When offset > 0 the
+// download resumes from that byte; start reports where the returned body
+// actually begins (0 if the server ignored the range). The caller must close
+// the body.
+func (c *Client) OpenAttachment(a *Attachment, offset int64) (body io.ReadCloser, start int64, err error) {
+	return c.OpenAttachmentContext(context.Background(), a, offset)
+}
+
+// OpenAttachmentContext is like OpenAttachment but uses ctx for the request.
+func (c *Client) OpenAttachmentContext(ctx context.Context, a *Attachment, offset int64) (io.ReadCloser, int64, error) {
+	// content_url はサーバー設定のホスト名になるので、APIキーを別ホストに送らないよう BaseURL から組み立てる
+	rawURL, err := c.requestURL(fmt.Sprintf("/attachments/download/%d/%s", a.ID, url.PathEscape(a.Filename)), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var resp *http.Response
+	for attempt := 0; ; attempt++ {
+		resp, err = c.openDownload(ctx, rawURL, offset)
+
+		if attempt >= c.Retry.MaxRetries || ctx.Err() != nil {
+			break
+		}
+		var reason string
+		if err != nil {
+			reason = err.Error()
+		} else if isRetryableStatus(resp.StatusCode) {
+			reason = resp.Status
+			resp.Body.Close()
+		} else {
+			break
+		}
+
+		var header http.Header
+		if resp != nil {
+			header = resp.Header
+		}
+		wait := c.Retry.backoff(attempt, header)
+		c.debugf("retry %d/%d in %s: GET %s: %s", attempt+1, c.Retry.MaxRetries, wait.Round(time.Millisecond), RedactURL(rawURL), reason)
+		if err := sleepContext(ctx, wait); err != nil {
+			return nil, 0, fmt.Errorf("request failed: %w", err)
+		}
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, 0, nil
+	case http.StatusPartialContent:
+		return resp.Body, offset, nil
+	case http.StatusRequestedRangeNotSatisfiable:
+		// 既に全体を取得済み
+		resp.Body.Close()
+		return io.NopCloser(strings.NewReader("")), offset, nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+	apiErr := newAPIError("GET", rawURL, resp.StatusCode, body)
+	apiErr.SwitchUser = c.SwitchUser
+	return nil, 0, apiErr
+}
+
+// openDownload sends one download request without reading the body.
+func (c *Client) openDownload(ctx context.Context, rawURL string, offset int64) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	delay, err := c.RateLimit.Wait(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	if delay > 0 {
+		c.debugf("rate limit: delayed GET %s by %s", RedactURL(rawURL), delay.Round(time.Millisecond))
+	}
+
+	if err := c.auth().Apply(req); err != nil {
+		return nil, fmt.Errorf("failed to authenticate request: %w", err)
+	}
+	if c.SwitchUser != "" {
+		req.Header.Set("X-Redmine-Switch-User", c.SwitchUser)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	start := time.Now()
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		c.trace(req, nil, nil, nil, time.Since(start), err)
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	c.trace(req, nil, resp, nil, time.Since(start), nil)
+	return resp, nil
+}
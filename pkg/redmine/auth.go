@@ -0,0 +1,53 @@
+package redmine
+
+import "net/http"
+
+// Auth adds credentials to outgoing requests.
+type Auth interface {
+	Apply(req *http.Request) error
+}
+
+// APIKeyAuth authenticates with a Redmine REST API key.
+type APIKeyAuth struct {
+	Key string
+}
+
+func (a APIKeyAuth) Apply(req *http.Request) error {
+	req.Header.Set("X-Redmine-API-Key", a.Key)
+	return nil
+}
+
+// BasicAuth authenticates with a Redmine login and password, for users
+// whose REST API key is disabled.
+type BasicAuth struct {
+	Login    string
+	Password string
+}
+
+func (a BasicAuth) Apply(req *http.Request) error {
+	req.SetBasicAuth(a.Login, a.Password)
+	return nil
+}
+
+// NewClientWithAuth returns a client that authenticates using auth.
+func NewClientWithAuth(baseURL string, auth Auth) *Client {
+	c := NewClient(baseURL, "")
+	c.Auth = auth
+	return c
+}
+
+func (c *Client) auth() Auth {
+	if c.Auth != nil {
+		return c.Auth
+	}
+	return APIKeyAuth{Key: c.APIKey}
+}
+
+// identity returns a hash that differs between credentials, used to keep
+// per-user cache entries apart. Being a hash, it is safe to show in debug
+// output.
+func (c *Client) identity() string {
+	req := &http.Request{Header: http.Header{}}
+	c.auth().Apply(req)
+	return cacheKey(req.Header.Get("X-Redmine-API-Key") + ":" + req.Header.Get("Authorization") + ":" + c.SwitchUser)
+}
@@ -0,0 +1,39 @@
+package redmine_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+	"github.com/ikasamt/rd/pkg/redmine/redminetest"
+)
+
+func TestBasicAuth(t *testing.T) {
+	srv := redminetest.NewServer()
+	defer srv.Close()
+	alice := srv.AddUser(redminetest.User{Login: "alice", Password: "secret", FirstName: "Alice", APIKey: "alice-key"})
+
+	client := redmine.NewClientWithAuth(srv.URL, redmine.BasicAuth{Login: "alice", Password: "secret"})
+	var sentKey bool
+	next := client.HTTPClient.Transport
+	client.HTTPClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		_, sentKey = req.Header["X-Redmine-Api-Key"]
+		return next.RoundTrip(req)
+	})
+	me, err := client.GetCurrentUser()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if me.ID != alice.ID {
+		t.Errorf("current user = %d, want alice (%d)", me.ID, alice.ID)
+	}
+	if sentKey {
+		t.Error("basic auth client also sent an empty X-Redmine-API-Key")
+	}
+
+	client.Auth = redmine.BasicAuth{Login: "alice", Password: "wrong"}
+	if _, err := client.GetCurrentUser(); !errors.Is(err, redmine.ErrUnauthorized) {
+		t.Errorf("wrong password: err = %v, want ErrUnauthorized", err)
+	}
+}
@@ -0,0 +1,177 @@
+package redmine
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Cacheable metadata resources.
+const (
+	ResourceCustomFields  = "custom_fields"
+	ResourceTrackers      = "trackers"
+	ResourceIssueStatuses = "issue_statuses"
+	ResourceVersions      = "versions"
+	ResourceUsers         = "users"
+	// ResourceTimeEntryActivities is the time entry activity enumeration.
+	ResourceTimeEntryActivities = "time_entry_activities"
+	// ResourceIssuePriorities is the issue priority enumeration.
+	ResourceIssuePriorities = "issue_priorities"
+	// ResourceIssueCategories holds the issue categories of each project.
+	ResourceIssueCategories = "issue_categories"
+)
+
+// DefaultCacheTTLs returns the default time-to-live of each cached resource.
+func DefaultCacheTTLs() map[string]time.Duration {
+	return map[string]time.Duration{
+		ResourceCustomFields:        24 * time.Hour,
+		ResourceTrackers:            24 * time.Hour,
+		ResourceIssueStatuses:       24 * time.Hour,
+		ResourceVersions:            time.Hour,
+		ResourceUsers:               time.Hour,
+		ResourceTimeEntryActivities: 24 * time.Hour,
+		ResourceIssuePriorities:     24 * time.Hour,
+		ResourceIssueCategories:     time.Hour,
+	}
+}
+
+// Cache stores slow-changing Redmine metadata on disk, one directory per
+// Redmine base URL.
+type Cache struct {
+	Dir string
+	TTL map[string]time.Duration
+}
+
+type cacheEntry struct {
+	FetchedAt time.Time       `json:"fetched_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// CacheRoot returns the directory holding the caches of every Redmine instance.
+func CacheRoot() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "rd"), nil
+}
+
+// NewCache returns a cache for baseURL under the user cache directory.
+func NewCache(baseURL string) (*Cache, error) {
+	root, err := CacheRoot()
+	if err != nil {
+		return nil, err
+	}
+	return &Cache{
+		Dir: filepath.Join(root, cacheKey(strings.TrimRight(baseURL, "/"))),
+		TTL: DefaultCacheTTLs(),
+	}, nil
+}
+
+// Clear removes every cached entry.
+func (c *Cache) Clear() error {
+	return os.RemoveAll(c.Dir)
+}
+
+func (c *Cache) path(resource, key string) string {
+	name := resource
+	if key != "" {
+		name += "-" + cacheKey(key)
+	}
+	return filepath.Join(c.Dir, name+".json")
+}
+
+// load decodes a fresh entry into v and reports whether one was found.
+func (c *Cache) load(resource, key string, v interface{}) bool {
+	ttl := c.TTL[resource]
+	if ttl <= 0 {
+		return false
+	}
+	data, err := os.ReadFile(c.path(resource, key))
+	if err != nil {
+		return false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return false
+	}
+	if time.Since(entry.FetchedAt) > ttl {
+		return false
+	}
+	return json.Unmarshal(entry.Data, v) == nil
+}
+
+func (c *Cache) store(resource, key string, v interface{}) error {
+	if c.TTL[resource] <= 0 {
+		return nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(cacheEntry{FetchedAt: time.Now(), Data: raw})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.Dir, 0o700); err != nil {
+		return err
+	}
+
+	// 書き込み途中のファイルを読まないよう、一時ファイル経由で置き換える
+	tmp, err := os.CreateTemp(c.Dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(resource, key))
+}
+
+func cacheKey(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:8])
+}
+
+type cacheRefreshKey struct{}
+
+// WithCacheRefresh makes requests using the returned context bypass cached
+// entries and overwrite them with fresh data.
+func WithCacheRefresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheRefreshKey{}, true)
+}
+
+func isCacheRefresh(ctx context.Context) bool {
+	refresh, _ := ctx.Value(cacheRefreshKey{}).(bool)
+	return refresh
+}
+
+// cachedGet is GetContext backed by c.Cache. It reports whether the result
+// came from the cache so name lookups can refresh on a miss.
+func (c *Client) cachedGet(ctx context.Context, resource, key, path string, params url.Values, result interface{}) (bool, error) {
+	if c.Cache != nil && !isCacheRefresh(ctx) && c.Cache.load(resource, key, result) {
+		c.debugf("cache hit: %s %s", resource, key)
+		return true, nil
+	}
+	if err := c.GetContext(ctx, path, params, result); err != nil {
+		return false, err
+	}
+	if c.Cache != nil {
+		if err := c.Cache.store(resource, key, result); err != nil {
+			c.debugf("cache store failed: %v", err)
+		}
+	}
+	return false, nil
+}
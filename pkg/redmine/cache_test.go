@@ -0,0 +1,170 @@
+package redmine_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+	"github.com/ikasamt/rd/pkg/redmine/redminetest"
+)
+
+// カスタムフィールド一覧は管理者しか取得できないので、キャッシュを共有しても
+// 他のユーザーには見えない。
+func TestCustomFieldsCacheIsPerUser(t *testing.T) {
+	srv := redminetest.NewServer()
+	defer srv.Close()
+	srv.AddCustomField("Severity")
+	bob := srv.AddUser(redminetest.User{Login: "bob", FirstName: "Bob", APIKey: "bob-key"})
+	cache := &redmine.Cache{Dir: t.TempDir(), TTL: redmine.DefaultCacheTTLs()}
+
+	var trace bytes.Buffer
+	admin := srv.Client()
+	admin.Cache = cache
+	admin.Debug = true
+	admin.DebugOutput = &trace
+	for i := 0; i < 2; i++ {
+		if fields, err := admin.ListCustomFields(); err != nil || len(fields) != 1 {
+			t.Fatalf("admin: %v, %v", fields, err)
+		}
+	}
+	if !strings.Contains(trace.String(), "cache hit") {
+		t.Errorf("second listing was not cached:\n%s", trace.String())
+	}
+	if strings.Contains(trace.String(), srv.APIKey) {
+		t.Errorf("debug output contains the API key:\n%s", trace.String())
+	}
+
+	user := srv.ClientFor(bob)
+	user.Cache = cache
+	if fields, err := user.ListCustomFields(); !errors.Is(err, redmine.ErrForbidden) {
+		t.Errorf("non-admin got %v, %v from the admin's cache", fields, err)
+	}
+}
+
+// 管理者でない場合のフィールド一覧もユーザーごとにキャッシュする。
+func TestAvailableCustomFieldsCacheIsPerUser(t *testing.T) {
+	srv := redminetest.NewServer()
+	defer srv.Close()
+	project := srv.AddProject("demo", "Demo")
+	severity := srv.AddCustomField("Severity")
+	issue := srv.AddIssue(redmine.IssueCreate{ProjectID: project.ID, Subject: "Crash"})
+	bob := srv.AddUser(redminetest.User{Login: "bob", FirstName: "Bob", APIKey: "bob-key"})
+	carol := srv.AddUser(redminetest.User{Login: "carol", FirstName: "Carol", APIKey: "carol-key"})
+	cache := &redmine.Cache{Dir: t.TempDir(), TTL: redmine.DefaultCacheTTLs()}
+
+	clients := map[string]*redmine.Client{}
+	traces := map[string]*bytes.Buffer{}
+	for _, u := range []*redminetest.User{bob, carol} {
+		c := srv.ClientFor(u)
+		c.Cache = cache
+		c.Debug = true
+		traces[u.Login] = &bytes.Buffer{}
+		c.DebugOutput = traces[u.Login]
+		clients[u.Login] = c
+	}
+
+	for _, login := range []string{"bob", "bob", "carol"} {
+		for _, issueID := range []int{0, issue.ID} {
+			field, err := clients[login].ResolveCustomField("demo", issueID, "severity")
+			if err != nil || field.ID != severity.ID {
+				t.Fatalf("%s, issue %d: ResolveCustomField = %+v, %v", login, issueID, field, err)
+			}
+		}
+	}
+	// bob の2回目はキャッシュから、carol は bob のキャッシュを使わない
+	if hits := strings.Count(traces["bob"].String(), "cache hit: custom_fields project:"); hits != 1 {
+		t.Errorf("bob's project field lookups hit the cache %d times, want 1:\n%s", hits, traces["bob"])
+	}
+	if hits := strings.Count(traces["bob"].String(), "cache hit: custom_fields issue:"); hits != 1 {
+		t.Errorf("bob's issue field lookups hit the cache %d times, want 1:\n%s", hits, traces["bob"])
+	}
+	if strings.Contains(traces["carol"].String(), "cache hit: custom_fields project:") ||
+		strings.Contains(traces["carol"].String(), "cache hit: custom_fields issue:") {
+		t.Errorf("carol was served bob's cached fields:\n%s", traces["carol"])
+	}
+	for login, trace := range traces {
+		if strings.Contains(trace.String(), login+"-key") {
+			t.Errorf("%s's trace contains the API key", login)
+		}
+	}
+}
+
+// countRequests は client が path に送ったリクエストを数える。
+func countRequests(client *redmine.Client, path string) *atomic.Int32 {
+	var n atomic.Int32
+	next := client.HTTPClient.Transport
+	client.HTTPClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path == path {
+			n.Add(1)
+		}
+		return next.RoundTrip(req)
+	})
+	return &n
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestCacheServesMetadataUntilItExpires(t *testing.T) {
+	srv := redminetest.NewServer()
+	defer srv.Close()
+	client := srv.Client()
+	cache := &redmine.Cache{Dir: t.TempDir(), TTL: redmine.DefaultCacheTTLs()}
+	client.Cache = cache
+	requests := countRequests(client, "/trackers.json")
+	listTrackers := func(ctx context.Context) {
+		t.Helper()
+		if trackers, err := client.ListTrackersContext(ctx); err != nil || len(trackers) != 3 {
+			t.Fatalf("ListTrackers = %v, %v", trackers, err)
+		}
+	}
+
+	steps := []struct {
+		name    string
+		prepare func() context.Context
+		want    int32
+	}{
+		{"first use", context.Background, 1},
+		{"cached", context.Background, 1},
+		{"refresh", func() context.Context { return redmine.WithCacheRefresh(context.Background()) }, 2},
+		{"cached after refresh", context.Background, 2},
+		{"cleared", func() context.Context {
+			if err := cache.Clear(); err != nil {
+				t.Fatal(err)
+			}
+			return context.Background()
+		}, 3},
+		{"corrupt entry", func() context.Context {
+			files, _ := filepath.Glob(filepath.Join(cache.Dir, "*.json"))
+			for _, f := range files {
+				os.WriteFile(f, []byte("{not json"), 0o600)
+			}
+			return context.Background()
+		}, 4},
+		{"expired", func() context.Context {
+			cache.TTL[redmine.ResourceTrackers] = time.Millisecond
+			time.Sleep(5 * time.Millisecond)
+			return context.Background()
+		}, 5},
+		{"disabled", func() context.Context {
+			cache.TTL[redmine.ResourceTrackers] = 0
+			return context.Background()
+		}, 6},
+		{"still disabled", context.Background, 7},
+	}
+	for _, step := range steps {
+		listTrackers(step.prepare())
+		if got := requests.Load(); got != step.want {
+			t.Fatalf("%s: %d requests so far, want %d", step.name, got, step.want)
+		}
+	}
+}
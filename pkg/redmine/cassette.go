@@ -0,0 +1,273 @@
+package redmine
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Interaction is one recorded request/response pair stored in a cassette directory.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	// Query is normalized: sorted, with credentials removed.
+	Query string `json:"query,omitempty"`
+	Body  string `json:"body,omitempty"`
+}
+
+type RecordedResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+	// BodyBase64 holds binary bodies such as attachment downloads.
+	BodyBase64 string `json:"body_base64,omitempty"`
+}
+
+func (i *Interaction) key() string {
+	return i.Request.Method + " " + i.Request.Path + "?" + i.Request.Query
+}
+
+// normalizeQuery sorts parameters and drops the API key so that requests
+// match regardless of parameter order or credentials.
+func normalizeQuery(q url.Values) string {
+	q = cloneValues(q)
+	q.Del("key")
+	for _, values := range q {
+		sort.Strings(values)
+	}
+	return q.Encode()
+}
+
+func cloneValues(q url.Values) url.Values {
+	out := url.Values{}
+	for k, v := range q {
+		out[k] = append([]string{}, v...)
+	}
+	return out
+}
+
+var apiKeyField = regexp.MustCompile(`"api_key"\s*:\s*"[^"]*"`)
+
+// scrub removes credentials from a recorded body.
+func scrub(body string, secrets []string) string {
+	body = apiKeyField.ReplaceAllString(body, `"api_key":"`+redacted+`"`)
+	for _, s := range secrets {
+		if s != "" {
+			body = strings.ReplaceAll(body, s, redacted)
+		}
+	}
+	return body
+}
+
+// requestSecrets returns credential values sent with req.
+func requestSecrets(req *http.Request) []string {
+	secrets := []string{req.Header.Get("X-Redmine-API-Key"), req.URL.Query().Get("key")}
+	if _, password, ok := req.BasicAuth(); ok {
+		secrets = append(secrets, password)
+	}
+	return secrets
+}
+
+// Recorder is an http.RoundTripper that saves every exchange into Dir,
+// one JSON file per interaction, with credentials scrubbed.
+type Recorder struct {
+	Dir  string
+	Next http.RoundTripper
+
+	mu  sync.Mutex
+	seq int
+}
+
+// NewRecorder creates dir if needed. Recording continues after any
+// interactions already present in dir.
+func NewRecorder(dir string, next http.RoundTripper) (*Recorder, error) {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	existing, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{Dir: dir, Next: next, seq: len(existing)}, nil
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	secrets := requestSecrets(req)
+	rec := Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			Path:   req.URL.Path,
+			Query:  normalizeQuery(req.URL.Query()),
+		},
+	}
+	if req.GetBody != nil && isTextContent(req.Header.Get("Content-Type")) {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := io.ReadAll(body)
+			body.Close()
+			rec.Request.Body = scrub(string(data), secrets)
+		}
+	}
+
+	resp, err := r.Next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	data := readBody(&resp.Body)
+	rec.Response = RecordedResponse{
+		Status: resp.StatusCode,
+		Header: RedactHeader(resp.Header),
+	}
+	if isTextContent(resp.Header.Get("Content-Type")) {
+		rec.Response.Body = scrub(string(data), secrets)
+	} else {
+		rec.Response.BodyBase64 = base64.StdEncoding.EncodeToString(data)
+	}
+
+	if err := r.save(&rec); err != nil {
+		return nil, fmt.Errorf("failed to record interaction: %w", err)
+	}
+	return resp, nil
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func (r *Recorder) save(rec *Interaction) error {
+	r.mu.Lock()
+	r.seq++
+	seq := r.seq
+	r.mu.Unlock()
+
+	name := strings.Trim(unsafeFileChars.ReplaceAllString(strings.TrimSuffix(rec.Request.Path, ".json"), "_"), "_")
+	file := filepath.Join(r.Dir, fmt.Sprintf("%04d-%s-%s.json", seq, rec.Request.Method, name))
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0o644)
+}
+
+// Replayer is an http.RoundTripper that answers requests from a cassette
+// directory written by Recorder, without network access. Requests match on
+// method, path and normalized query. Identical requests are answered in
+// recorded order; the last response repeats once they are used up.
+type Replayer struct {
+	mu           sync.Mutex
+	interactions map[string][]*Interaction
+	served       map[string]int
+}
+
+// NewReplayer loads every interaction in dir.
+func NewReplayer(dir string) (*Replayer, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no recorded interactions in %s", dir)
+	}
+	sort.Strings(files)
+
+	r := &Replayer{
+		interactions: map[string][]*Interaction{},
+		served:       map[string]int{},
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var rec Interaction
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		r.interactions[rec.key()] = append(r.interactions[rec.key()], &rec)
+	}
+	return r, nil
+}
+
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := (&Interaction{Request: RecordedRequest{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  normalizeQuery(req.URL.Query()),
+	}}).key()
+
+	r.mu.Lock()
+	candidates := r.interactions[key]
+	n := r.served[key]
+	r.served[key] = n + 1
+	r.mu.Unlock()
+
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no recorded response for %s", key)
+	}
+	if n >= len(candidates) {
+		n = len(candidates) - 1
+	}
+	rec := candidates[n]
+
+	body := []byte(rec.Response.Body)
+	if rec.Response.BodyBase64 != "" {
+		decoded, err := base64.StdEncoding.DecodeString(rec.Response.BodyBase64)
+		if err != nil {
+			return nil, fmt.Errorf("corrupt recorded body for %s: %w", key, err)
+		}
+		body = decoded
+	}
+
+	header := rec.Response.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.Response.Status, http.StatusText(rec.Response.Status)),
+		StatusCode:    rec.Response.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// Record makes the client save every exchange into dir.
+func (c *Client) Record(dir string) error {
+	rec, err := NewRecorder(dir, c.HTTPClient.Transport)
+	if err != nil {
+		return err
+	}
+	c.HTTPClient.Transport = rec
+	return nil
+}
+
+// Replay makes the client answer requests from the cassette in dir instead
+// of the network.
+func (c *Client) Replay(dir string) error {
+	rep, err := NewReplayer(dir)
+	if err != nil {
+		return err
+	}
+	c.HTTPClient.Transport = rep
+	return nil
+}
@@ -0,0 +1,119 @@
+package redmine_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+	"github.com/ikasamt/rd/pkg/redmine/redminetest"
+)
+
+func TestCassetteRoundTrip(t *testing.T) {
+	srv := redminetest.NewServer()
+	srv.AddProject("demo", "Demo")
+	dir := t.TempDir()
+
+	// 記録
+	client := srv.Client()
+	if err := client.Record(dir); err != nil {
+		t.Fatal(err)
+	}
+	content := []byte("\x00\x01binary\xff")
+	token, err := client.Upload("data.bin", bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	project, _ := client.GetProject("demo")
+	issue, err := client.CreateIssue(&redmine.IssueCreate{ProjectID: project.ID, Subject: "Recorded",
+		Uploads: []redmine.Upload{{Token: token, Filename: "data.bin"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded, err := client.GetIssue(issue.ID, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetCurrentUser(); err != nil {
+		t.Fatal(err)
+	}
+	body, _, err := client.OpenAttachment(&recorded.Attachments[0], 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.Copy(io.Discard, body)
+	body.Close()
+	// クエリの key も記録しない
+	var statuses redmine.IssueStatusesResponse
+	if err := client.GetContext(context.Background(), "/issue_statuses.json", url.Values{"key": {redminetest.DefaultAPIKey}}, &statuses); err != nil {
+		t.Fatal(err)
+	}
+	subject := "Renamed"
+	if err := client.UpdateIssue(issue.ID, &redmine.IssueUpdate{Subject: &subject}); err != nil {
+		t.Fatal(err)
+	}
+	renamed, _ := client.GetIssue(issue.ID, true)
+	srv.Close()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) == 0 {
+		t.Fatal("nothing was recorded")
+	}
+	for _, file := range files {
+		data, _ := os.ReadFile(file)
+		if strings.Contains(string(data), redminetest.DefaultAPIKey) {
+			t.Errorf("%s contains the API key", filepath.Base(file))
+		}
+	}
+
+	// 再生（サーバーは停止済み、APIキーも違う）
+	replay := redmine.NewClient(srv.URL, "other-key")
+	replay.Retry = redmine.RetryPolicy{}
+	if err := replay.Replay(dir); err != nil {
+		t.Fatal(err)
+	}
+	got, err := replay.GetIssue(issue.ID, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Subject != recorded.Subject {
+		t.Errorf("first replay subject = %q, want %q", got.Subject, recorded.Subject)
+	}
+	// 同じ要求には記録順に答え、使い切ったら最後の応答を繰り返す
+	for i := 0; i < 2; i++ {
+		if got, _ := replay.GetIssue(issue.ID, true); got == nil || got.Subject != renamed.Subject {
+			t.Errorf("replay %d = %+v, want subject %q", i+2, got, renamed.Subject)
+		}
+	}
+	if me, err := replay.GetCurrentUser(); err != nil || me.Login != "admin" {
+		t.Errorf("GetCurrentUser = %+v, %v", me, err)
+	}
+	if err := replay.GetContext(context.Background(), "/issue_statuses.json", nil, &statuses); err != nil {
+		t.Errorf("request recorded with a key parameter did not match: %v", err)
+	}
+
+	body, _, err = replay.OpenAttachment(&got.Attachments[0], 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if !bytes.Equal(data, content) {
+		t.Errorf("replayed attachment = %q, want %q", data, content)
+	}
+
+	if _, err := replay.GetIssue(issue.ID+1, true); err == nil || !strings.Contains(err.Error(), "no recorded response") {
+		t.Errorf("unrecorded request: err = %v, want it to fail", err)
+	}
+}
+
+func TestReplayNeedsRecordedInteractions(t *testing.T) {
+	if err := redmine.NewClient("http://redmine.invalid", "key").Replay(t.TempDir()); err == nil {
+		t.Error("Replay of an empty directory succeeded")
+	}
+}
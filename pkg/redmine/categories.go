@@ -0,0 +1,39 @@
+package redmine
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+type IssueCategory struct {
+	ID         int     `json:"id"`
+	Name       string  `json:"name"`
+	Project    Project `json:"project"`
+	AssignedTo *User   `json:"assigned_to,omitempty"`
+}
+
+type IssueCategoriesResponse struct {
+	IssueCategories []IssueCategory `json:"issue_categories"`
+	TotalCount      int             `json:"total_count"`
+}
+
+func (c *Client) ListIssueCategories(projectID string) ([]IssueCategory, error) {
+	return c.ListIssueCategoriesContext(context.Background(), projectID)
+}
+
+// ListIssueCategoriesContext is like ListIssueCategories but uses ctx for the request.
+func (c *Client) ListIssueCategoriesContext(ctx context.Context, projectID string) ([]IssueCategory, error) {
+	categories, _, err := c.listIssueCategories(ctx, projectID)
+	return categories, err
+}
+
+func (c *Client) listIssueCategories(ctx context.Context, projectID string) ([]IssueCategory, bool, error) {
+	var response IssueCategoriesResponse
+	path := fmt.Sprintf("/projects/%s/issue_categories.json", url.PathEscape(projectID))
+	cached, err := c.cachedGet(ctx, ResourceIssueCategories, projectID, path, nil, &response)
+	if err != nil {
+		return nil, false, err
+	}
+	return response.IssueCategories, cached, nil
+}
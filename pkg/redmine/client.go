@@ -2,6 +2,7 @@ package redmine
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -12,29 +13,53 @@ import (
 )
 
 type Client struct {
-	BaseURL    string
-	APIKey     string
+	BaseURL string
+	APIKey  string
+	// Auth overrides APIKey with another authentication strategy.
+	Auth       Auth
 	HTTPClient *http.Client
-	Debug      bool
+	// Debug traces requests to DebugOutput (stderr by default).
+	Debug bool
+	// DebugBodies also traces headers and bodies. Credentials are always redacted.
+	DebugBodies bool
+	DebugOutput io.Writer
+	Retry       RetryPolicy
+	// SwitchUser is sent as X-Redmine-Switch-User so that an admin API key
+	// acts on behalf of that login.
+	SwitchUser string
+	// Cache stores slow-changing metadata. nil disables caching.
+	Cache *Cache
+	// RateLimit throttles every request, including retries. nil disables it.
+	RateLimit *RateLimiter
 }
 
 func NewClient(baseURL, apiKey string) *Client {
 	// URLの末尾のスラッシュを除去
 	baseURL = strings.TrimRight(baseURL, "/")
-	
+
 	return &Client{
 		BaseURL: baseURL,
 		APIKey:  apiKey,
 		HTTPClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Transport: newTransport(DefaultTimeout),
 		},
+		Retry: DefaultRetryPolicy(),
 	}
 }
 
-func (c *Client) doRequest(method, path string, params url.Values, body interface{}) ([]byte, error) {
+// payload is a request body. JSON bodies are kept in memory; uploads are
+// streamed from a seekable reader that is rewound before every attempt.
+type payload struct {
+	contentType string
+	data        []byte
+	stream      io.ReadSeeker
+	size        int64
+}
+
+func (c *Client) requestURL(path string, params url.Values) (string, error) {
 	u, err := url.Parse(c.BaseURL)
 	if err != nil {
-		return nil, fmt.Errorf("invalid base URL: %w", err)
+		return "", fmt.Errorf("invalid base URL: %w", err)
 	}
 
 	// パスを結合
@@ -42,63 +67,149 @@ func (c *Client) doRequest(method, path string, params url.Values, body interfac
 	if params != nil {
 		u.RawQuery = params.Encode()
 	}
+	return u.String(), nil
+}
+
+func (c *Client) doRequest(ctx context.Context, method, path string, params url.Values, body interface{}) ([]byte, error) {
+	rawURL, err := c.requestURL(path, params)
+	if err != nil {
+		return nil, err
+	}
 
-	var bodyReader io.Reader
+	var p *payload
 	if body != nil {
 		jsonBody, err := json.Marshal(body)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal request body: %w", err)
 		}
-		bodyReader = bytes.NewReader(jsonBody)
+		p = &payload{contentType: "application/json", data: jsonBody}
 	}
+	return c.execute(ctx, method, rawURL, p)
+}
+
+// execute sends the request with retries and turns error statuses into *APIError.
+func (c *Client) execute(ctx context.Context, method, rawURL string, p *payload) ([]byte, error) {
+	var err error
+	retryable := canRetry(ctx, method)
+	var resp *http.Response
+	var respBody []byte
+	for attempt := 0; ; attempt++ {
+		resp, respBody, err = c.send(ctx, method, rawURL, p)
+
+		if !retryable || attempt >= c.Retry.MaxRetries || ctx.Err() != nil {
+			break
+		}
+		var reason string
+		if err != nil {
+			reason = err.Error()
+		} else if isRetryableStatus(resp.StatusCode) {
+			reason = resp.Status
+		} else {
+			break
+		}
 
-	req, err := http.NewRequest(method, u.String(), bodyReader)
+		var header http.Header
+		if resp != nil {
+			header = resp.Header
+		}
+		wait := c.Retry.backoff(attempt, header)
+		c.debugf("retry %d/%d in %s: %s %s: %s", attempt+1, c.Retry.MaxRetries, wait.Round(time.Millisecond), method, RedactURL(rawURL), reason)
+		if err := sleepContext(ctx, wait); err != nil {
+			return nil, fmt.Errorf("request failed: %w", err)
+		}
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
 
-	req.Header.Set("X-Redmine-API-Key", c.APIKey)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
+	// エラーハンドリング
+	if resp.StatusCode >= 400 {
+		apiErr := newAPIError(method, rawURL, resp.StatusCode, respBody)
+		apiErr.SwitchUser = c.SwitchUser
+		return nil, apiErr
+	}
 
-	if c.Debug {
-		fmt.Printf("[DEBUG] %s %s\n", method, u.String())
+	// HTMLが返ってきた場合（JSONではない）
+	if strings.HasPrefix(strings.TrimSpace(string(respBody)), "<") {
+		return nil, fmt.Errorf("invalid response: expected JSON but got HTML. Please check your REDMINE_URL is correct and includes the protocol (http:// or https://)\nURL: %s", rawURL)
 	}
 
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+	return respBody, nil
+}
+
+// send performs a single HTTP attempt and reads the whole response body.
+func (c *Client) send(ctx context.Context, method, rawURL string, p *payload) (*http.Response, []byte, error) {
+	var bodyReader io.Reader
+	var traceBody []byte
+	contentType := "application/json"
+	if p != nil {
+		contentType = p.contentType
+		if p.stream != nil {
+			if _, err := p.stream.Seek(0, io.SeekStart); err != nil {
+				return nil, nil, fmt.Errorf("failed to rewind request body: %w", err)
+			}
+			// ファイルを閉じられないように NopCloser で包む
+			bodyReader = io.NopCloser(p.stream)
+		} else {
+			bodyReader = bytes.NewReader(p.data)
+			traceBody = p.data
+		}
 	}
-	defer resp.Body.Close()
 
-	respBody, err := io.ReadAll(resp.Body)
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, bodyReader)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if p != nil && p.stream != nil {
+		req.ContentLength = p.size
+		req.GetBody = func() (io.ReadCloser, error) {
+			if _, err := p.stream.Seek(0, io.SeekStart); err != nil {
+				return nil, err
+			}
+			return io.NopCloser(p.stream), nil
+		}
 	}
 
-	// エラーハンドリング
-	if resp.StatusCode == 401 {
-		return nil, fmt.Errorf("authentication failed: invalid API key or unauthorized access\nURL: %s", u.String())
+	delay, err := c.RateLimit.Wait(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("request failed: %w", err)
 	}
-	
-	if resp.StatusCode == 404 {
-		return nil, fmt.Errorf("not found: the requested resource does not exist\nURL: %s", u.String())
+	if delay > 0 {
+		c.debugf("rate limit: delayed %s %s by %s", method, RedactURL(rawURL), delay.Round(time.Millisecond))
 	}
-	
-	// HTMLが返ってきた場合（JSONではない）
-	if strings.HasPrefix(strings.TrimSpace(string(respBody)), "<") {
-		return nil, fmt.Errorf("invalid response: expected JSON but got HTML. Please check your REDMINE_URL is correct and includes the protocol (http:// or https://)\nURL: %s", u.String())
+
+	if err := c.auth().Apply(req); err != nil {
+		return nil, nil, fmt.Errorf("failed to authenticate request: %w", err)
 	}
-	
-	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("API error (status %d): %s\nURL: %s", resp.StatusCode, string(respBody), u.String())
+	if c.SwitchUser != "" {
+		req.Header.Set("X-Redmine-Switch-User", c.SwitchUser)
 	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", "application/json")
 
-	return respBody, nil
+	start := time.Now()
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		c.trace(req, traceBody, nil, nil, time.Since(start), err)
+		return nil, nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	c.trace(req, traceBody, resp, respBody, time.Since(start), err)
+	if err != nil {
+		return resp, nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return resp, respBody, nil
 }
 
 func (c *Client) Get(path string, params url.Values, result interface{}) error {
-	body, err := c.doRequest("GET", path, params, nil)
+	return c.GetContext(context.Background(), path, params, result)
+}
+
+// GetContext is like Get but uses ctx for the request.
+func (c *Client) GetContext(ctx context.Context, path string, params url.Values, result interface{}) error {
+	body, err := c.doRequest(ctx, "GET", path, params, nil)
 	if err != nil {
 		return err
 	}
@@ -111,7 +222,12 @@ func (c *Client) Get(path string, params url.Values, result interface{}) error {
 }
 
 func (c *Client) Post(path string, params url.Values, reqBody interface{}, result interface{}) error {
-	body, err := c.doRequest("POST", path, params, reqBody)
+	return c.PostContext(context.Background(), path, params, reqBody, result)
+}
+
+// PostContext is like Post but uses ctx for the request.
+func (c *Client) PostContext(ctx context.Context, path string, params url.Values, reqBody interface{}, result interface{}) error {
+	body, err := c.doRequest(ctx, "POST", path, params, reqBody)
 	if err != nil {
 		return err
 	}
@@ -126,6 +242,21 @@ func (c *Client) Post(path string, params url.Values, reqBody interface{}, resul
 }
 
 func (c *Client) Put(path string, params url.Values, reqBody interface{}) error {
-	_, err := c.doRequest("PUT", path, params, reqBody)
+	return c.PutContext(context.Background(), path, params, reqBody)
+}
+
+// PutContext is like Put but uses ctx for the request.
+func (c *Client) PutContext(ctx context.Context, path string, params url.Values, reqBody interface{}) error {
+	_, err := c.doRequest(ctx, "PUT", path, params, reqBody)
 	return err
-}
\ No newline at end of file
+}
+
+func (c *Client) Delete(path string, params url.Values) error {
+	return c.DeleteContext(context.Background(), path, params)
+}
+
+// DeleteContext is like Delete but uses ctx for the request.
+func (c *Client) DeleteContext(ctx context.Context, path string, params url.Values) error {
+	_, err := c.doRequest(ctx, "DELETE", path, params, nil)
+	return err
+}
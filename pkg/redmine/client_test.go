@@ -0,0 +1,108 @@
+package redmine_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+)
+
+// hangingServer は要求を受けたまま、クライアントが切断するまで応答しない。
+func hangingServer(t *testing.T) (*redmine.Client, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		// 本文を読み切らないとサーバーは切断に気付かない
+		io.Copy(io.Discard, r.Body)
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(release) })
+	client := redmine.NewClient(srv.URL, "key")
+	client.Retry.MaxRetries = 0
+	return client, &calls
+}
+
+func TestCancelStopsRequests(t *testing.T) {
+	client, _ := hangingServer(t)
+	subject := "Renamed"
+	calls := map[string]func(context.Context) error{
+		"get": func(ctx context.Context) error {
+			_, err := client.GetIssueContext(ctx, 1, true)
+			return err
+		},
+		"list": func(ctx context.Context) error {
+			_, err := client.ListIssuesContext(ctx, nil)
+			return err
+		},
+		"paginate": func(ctx context.Context) error {
+			_, err := client.IssuePaginator(nil).All(ctx)
+			return err
+		},
+		"create": func(ctx context.Context) error {
+			_, err := client.CreateIssueContext(ctx, &redmine.IssueCreate{ProjectID: 1, Subject: "New"})
+			return err
+		},
+		"update": func(ctx context.Context) error {
+			return client.UpdateIssueContext(ctx, 1, &redmine.IssueUpdate{Subject: &subject})
+		},
+		"upload": func(ctx context.Context) error {
+			_, err := client.UploadContext(ctx, "a.txt", bytes.NewReader([]byte("abc")), 3)
+			return err
+		},
+		"resolve": func(ctx context.Context) error {
+			_, err := client.ResolveStatusContext(ctx, "New")
+			return err
+		},
+		"delete": func(ctx context.Context) error {
+			return client.DeleteTimeEntryContext(ctx, 1)
+		},
+	}
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(20*time.Millisecond, cancel)
+			start := time.Now()
+			err := call(ctx)
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("err = %v, want context.Canceled", err)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("returned %s after cancel", elapsed)
+			}
+		})
+	}
+}
+
+func TestCancelledContextSendsNothing(t *testing.T) {
+	client, calls := hangingServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.GetIssueContext(ctx, 1, false); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if n := calls.Load(); n != 0 {
+		t.Errorf("server received %d requests", n)
+	}
+}
+
+func TestDeadlineExceeded(t *testing.T) {
+	client, _ := hangingServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.GetProjectContext(ctx, "demo"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
@@ -1,10 +1,31 @@
 package redmine
 
-import "fmt"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
 
+// CustomFieldDefinition describes a custom field. Only admins can read the
+// format and possible values; other keys get the ID and name (and Multiple
+// for fields read from an issue).
 type CustomFieldDefinition struct {
-	ID   int    `json:"id"`
-	Name string `json:"name"`
+	ID             int                        `json:"id"`
+	Name           string                     `json:"name"`
+	CustomizedType string                     `json:"customized_type,omitempty"`
+	FieldFormat    string                     `json:"field_format,omitempty"`
+	Multiple       bool                       `json:"multiple,omitempty"`
+	PossibleValues []CustomFieldPossibleValue `json:"possible_values,omitempty"`
+}
+
+// CustomFieldPossibleValue is an allowed value of a list or key/value field.
+// For key/value fields Value is the key sent to Redmine and Label its name.
+type CustomFieldPossibleValue struct {
+	Value string `json:"value"`
+	Label string `json:"label,omitempty"`
 }
 
 type CustomFieldsResponse struct {
@@ -12,23 +33,142 @@ type CustomFieldsResponse struct {
 }
 
 func (c *Client) ListCustomFields() ([]CustomFieldDefinition, error) {
+	return c.ListCustomFieldsContext(context.Background())
+}
+
+func (c *Client) ListCustomFieldsContext(ctx context.Context) ([]CustomFieldDefinition, error) {
+	fields, _, err := c.listCustomFields(ctx)
+	return fields, err
+}
+
+func (c *Client) listCustomFields(ctx context.Context) ([]CustomFieldDefinition, bool, error) {
+	// 管理者以外は 403 になるので、結果はユーザーごとにキャッシュする
 	var response CustomFieldsResponse
-	if err := c.Get("/custom_fields.json", nil, &response); err != nil {
-		return nil, fmt.Errorf("failed to list custom fields (admin permission required): %w", err)
+	cached, err := c.cachedGet(ctx, ResourceCustomFields, "all:"+c.identity(), "/custom_fields.json", nil, &response)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to list custom fields (admin permission required): %w", err)
 	}
-	return response.CustomFields, nil
+	return response.CustomFields, cached, nil
 }
 
 // FindCustomFieldByName はカスタムフィールド名からIDを解決する
 func (c *Client) FindCustomFieldByName(name string) (*CustomFieldDefinition, error) {
-	fields, err := c.ListCustomFields()
+	return c.FindCustomFieldByNameContext(context.Background(), name)
+}
+
+func (c *Client) FindCustomFieldByNameContext(ctx context.Context, name string) (*CustomFieldDefinition, error) {
+	fields, cached, err := c.listCustomFields(ctx)
 	if err != nil {
 		return nil, err
 	}
+	var issueFields []CustomFieldDefinition
 	for _, f := range fields {
-		if f.Name == name {
-			return &f, nil
+		// ユーザーや時間記録のカスタムフィールドは対象外
+		if f.CustomizedType == "" || f.CustomizedType == "issue" {
+			issueFields = append(issueFields, f)
+		}
+	}
+	field, err := findCustomField(issueFields, name, "", 0)
+	// キャッシュが古い可能性があるので取り直して再検索
+	if cached && unmatched(err) {
+		return c.FindCustomFieldByNameContext(WithCacheRefresh(ctx), name)
+	}
+	return field, err
+}
+
+// findCustomField は名前（大文字小文字を区別しない）または ID でフィールドを探す。
+func findCustomField(fields []CustomFieldDefinition, name, projectID string, issueID int) (*CustomFieldDefinition, error) {
+	var names []string
+	for i := range fields {
+		if strings.EqualFold(fields[i].Name, name) || strconv.Itoa(fields[i].ID) == name {
+			return &fields[i], nil
+		}
+		names = append(names, fields[i].Name)
+	}
+	return nil, &ResolveError{Kind: "custom field", Value: name, Project: projectID, Issue: issueID, Candidates: names}
+}
+
+// ResolveCustomField finds an issue custom field by name or ID. It uses
+// /custom_fields.json when the API key may read it, and otherwise the fields
+// available on issue issueID or, when issueID is 0, on project projectID.
+func (c *Client) ResolveCustomField(projectID string, issueID int, name string) (*CustomFieldDefinition, error) {
+	return c.ResolveCustomFieldContext(context.Background(), projectID, issueID, name)
+}
+
+// ResolveCustomFieldContext is like ResolveCustomField but uses ctx for the requests.
+func (c *Client) ResolveCustomFieldContext(ctx context.Context, projectID string, issueID int, name string) (*CustomFieldDefinition, error) {
+	scoped := projectID != "" || issueID != 0
+	if !scoped || !c.customFieldsForbidden(ctx) {
+		field, err := c.FindCustomFieldByNameContext(ctx, name)
+		if !scoped || !errors.Is(err, ErrForbidden) {
+			return field, err
+		}
+		c.rememberCustomFieldsForbidden()
+	}
+
+	// 管理者でなければチケットまたはプロジェクトで使えるフィールドから探す
+	fields, cached, err := c.availableCustomFields(ctx, projectID, issueID)
+	if err != nil {
+		return nil, err
+	}
+	field, err := findCustomField(fields, name, projectID, issueID)
+	if cached && unmatched(err) {
+		return c.ResolveCustomFieldContext(WithCacheRefresh(ctx), projectID, issueID, name)
+	}
+	return field, err
+}
+
+// customFieldsForbidden は /custom_fields.json が 403 を返すと記録済みかを返す。
+// 毎回管理者用 API を試して失敗するのを避けるため、結果はユーザーごとにキャッシュする。
+func (c *Client) customFieldsForbidden(ctx context.Context) bool {
+	var forbidden bool
+	return c.Cache != nil && !isCacheRefresh(ctx) && c.Cache.load(ResourceCustomFields, "forbidden:"+c.identity(), &forbidden) && forbidden
+}
+
+func (c *Client) rememberCustomFieldsForbidden() {
+	if c.Cache != nil {
+		if err := c.Cache.store(ResourceCustomFields, "forbidden:"+c.identity(), true); err != nil {
+			c.debugf("cache store failed: %v", err)
+		}
+	}
+}
+
+// availableCustomFields はチケット（issueID が 0 でなければ）またはプロジェクトで有効な
+// カスタムフィールドを返す。どちらも管理者権限なしで参照できる。
+func (c *Client) availableCustomFields(ctx context.Context, projectID string, issueID int) ([]CustomFieldDefinition, bool, error) {
+	// 見えるフィールドは権限によって違うのでユーザーごとに分ける
+	key := "project:" + c.identity() + ":" + projectID
+	if issueID != 0 {
+		key = "issue:" + c.identity() + ":" + strconv.Itoa(issueID)
+	}
+	var fields []CustomFieldDefinition
+	if c.Cache != nil && !isCacheRefresh(ctx) && c.Cache.load(ResourceCustomFields, key, &fields) {
+		c.debugf("cache hit: %s %s", ResourceCustomFields, key)
+		return fields, true, nil
+	}
+
+	if issueID != 0 {
+		var response IssueResponse
+		if err := c.GetContext(ctx, fmt.Sprintf("/issues/%d.json", issueID), nil, &response); err != nil {
+			return nil, false, fmt.Errorf("failed to get custom fields of issue #%d: %w", issueID, err)
+		}
+		for _, f := range response.Issue.CustomFields {
+			fields = append(fields, CustomFieldDefinition{ID: f.ID, Name: f.Name, Multiple: f.Multiple})
+		}
+	} else {
+		params := url.Values{}
+		params.Set("include", "issue_custom_fields")
+		var response ProjectResponse
+		if err := c.GetContext(ctx, fmt.Sprintf("/projects/%s.json", url.PathEscape(projectID)), params, &response); err != nil {
+			return nil, false, fmt.Errorf("failed to get custom fields of project '%s': %w", projectID, err)
+		}
+		fields = response.Project.IssueCustomFields
+	}
+
+	if c.Cache != nil {
+		if err := c.Cache.store(ResourceCustomFields, key, fields); err != nil {
+			c.debugf("cache store failed: %v", err)
 		}
 	}
-	return nil, fmt.Errorf("custom field '%s' not found", name)
+	return fields, false, nil
 }
@@ -0,0 +1,101 @@
+package redmine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Sentinel errors matched by *APIError via errors.Is.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrValidation   = errors.New("validation failed")
+	// ErrConflict means the resource was changed since the version the update was based on.
+	ErrConflict = errors.New("conflict")
+	// ErrSwitchUserNotFound means the X-Redmine-Switch-User login does not exist or is locked.
+	ErrSwitchUserNotFound = errors.New("switch user not found")
+)
+
+// APIError is returned when Redmine responds with a 4xx/5xx status.
+type APIError struct {
+	StatusCode int
+	Method     string
+	URL        string
+	// Errors holds the messages of a Redmine {"errors": [...]} response body.
+	Errors []string
+	// Body is the raw response body when it could not be parsed.
+	Body string
+	// SwitchUser is the login sent in X-Redmine-Switch-User, if any.
+	SwitchUser string
+}
+
+func newAPIError(method, u string, status int, body []byte) *APIError {
+	e := &APIError{
+		StatusCode: status,
+		Method:     method,
+		URL:        u,
+	}
+
+	var parsed struct {
+		Errors []string `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &parsed); err == nil && len(parsed.Errors) > 0 {
+		e.Errors = parsed.Errors
+	} else if text := strings.TrimSpace(string(body)); !strings.HasPrefix(text, "<") {
+		// HTMLのエラーページは本文に含めない
+		e.Body = text
+	}
+	return e
+}
+
+func (e *APIError) Error() string {
+	var msg string
+	switch e.StatusCode {
+	case 401:
+		msg = "authentication failed: invalid API key or unauthorized access"
+	case 403:
+		msg = "forbidden: you are not allowed to access this resource"
+	case 404:
+		msg = "not found: the requested resource does not exist"
+	case 412:
+		if e.SwitchUser != "" {
+			return fmt.Sprintf("cannot act as user '%s': the login does not exist or is locked\nURL: %s", e.SwitchUser, e.URL)
+		}
+		msg = "precondition failed"
+	case 409:
+		msg = "conflict: the resource was modified by someone else"
+	case 422:
+		msg = "validation failed"
+	default:
+		msg = fmt.Sprintf("API error (status %d)", e.StatusCode)
+	}
+
+	if len(e.Errors) > 0 {
+		msg += ": " + strings.Join(e.Errors, "; ")
+	} else if e.Body != "" && e.StatusCode != 401 && e.StatusCode != 404 {
+		msg += ": " + e.Body
+	}
+	return fmt.Sprintf("%s\nURL: %s", msg, e.URL)
+}
+
+// Is reports whether the error matches one of the sentinel errors.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == 404
+	case ErrUnauthorized:
+		return e.StatusCode == 401
+	case ErrForbidden:
+		return e.StatusCode == 403
+	case ErrValidation:
+		return e.StatusCode == 422
+	case ErrConflict:
+		return e.StatusCode == 409
+	case ErrSwitchUserNotFound:
+		return e.StatusCode == 412 && e.SwitchUser != ""
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package redmine
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAPIError(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		switchUser string
+		want       error
+		wantErrors []string
+		wantMsg    string
+		notInMsg   string
+	}{
+		{"validation messages", 422, `{"errors":["Subject cannot be blank","Tracker is invalid"]}`, "", ErrValidation,
+			[]string{"Subject cannot be blank", "Tracker is invalid"}, "validation failed: Subject cannot be blank; Tracker is invalid", ""},
+		{"not found", 404, "", "", ErrNotFound, nil, "not found", ""},
+		{"HTML error page", 404, "<html><body>Not Found</body></html>", "", ErrNotFound, nil, "not found", "<html>"},
+		{"unauthorized body hidden", 401, "Invalid key", "", ErrUnauthorized, nil, "authentication failed", "Invalid key"},
+		{"forbidden", 403, "", "", ErrForbidden, nil, "forbidden", ""},
+		{"conflict", 409, "", "", ErrConflict, nil, "conflict", ""},
+		{"unknown switch user", 412, "", "bob", ErrSwitchUserNotFound, nil, "cannot act as user 'bob'", ""},
+		{"plain text server error", 500, "database is locked\n", "", nil, nil, "API error (status 500): database is locked", ""},
+	}
+	sentinels := []error{ErrNotFound, ErrUnauthorized, ErrForbidden, ErrValidation, ErrConflict, ErrSwitchUserNotFound}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newAPIError("POST", "https://redmine.example.com/issues.json", tt.status, []byte(tt.body))
+			e.SwitchUser = tt.switchUser
+
+			for _, sentinel := range sentinels {
+				if got := errors.Is(e, sentinel); got != (sentinel == tt.want) {
+					t.Errorf("errors.Is(%v) = %v", sentinel, got)
+				}
+			}
+			if strings.Join(e.Errors, "|") != strings.Join(tt.wantErrors, "|") {
+				t.Errorf("Errors = %q, want %q", e.Errors, tt.wantErrors)
+			}
+			msg := e.Error()
+			if !strings.Contains(msg, tt.wantMsg) || !strings.HasSuffix(msg, "\nURL: https://redmine.example.com/issues.json") {
+				t.Errorf("Error() = %q, want it to contain %q and the URL", msg, tt.wantMsg)
+			}
+			if tt.notInMsg != "" && strings.Contains(msg, tt.notInMsg) {
+				t.Errorf("Error() = %q, should not contain %q", msg, tt.notInMsg)
+			}
+		})
+	}
+
+	// 412 は X-Redmine-Switch-User を送ったときだけ「ユーザーが見つからない」
+	if errors.Is(newAPIError("GET", "/", 412, nil), ErrSwitchUserNotFound) {
+		t.Error("412 without a switch user matches ErrSwitchUserNotFound")
+	}
+}
+
+func TestClientReturnsAPIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"errors":["Subject cannot be blank"]}`))
+	}))
+	defer srv.Close()
+	client := NewClient(srv.URL, "secret-key")
+	client.SwitchUser = "bob"
+
+	_, err := client.CreateIssue(&IssueCreate{ProjectID: 1})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err = %T %v, want *APIError", err, err)
+	}
+	if apiErr.StatusCode != 422 || apiErr.Method != "POST" || !strings.HasSuffix(apiErr.URL, "/issues.json") || apiErr.SwitchUser != "bob" {
+		t.Errorf("APIError = %+v", apiErr)
+	}
+	if strings.Contains(err.Error(), "secret-key") {
+		t.Errorf("error contains the API key: %v", err)
+	}
+}
@@ -0,0 +1,203 @@
+package redmine
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+// HARRecorder is an http.RoundTripper that records every exchange so the
+// session can be saved as a HAR file. Credentials are redacted from headers,
+// URLs and bodies.
+type HARRecorder struct {
+	Next http.RoundTripper
+
+	mu      sync.Mutex
+	entries []harEntry
+}
+
+// NewHARRecorder wraps next (http.DefaultTransport when nil).
+func NewHARRecorder(next http.RoundTripper) *HARRecorder {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &HARRecorder{Next: next}
+}
+
+type harLog struct {
+	Log struct {
+		Version string     `json:"version"`
+		Creator harCreator `json:"creator"`
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+func (h *HARRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	entry := harEntry{StartedDateTime: time.Now()}
+
+	secrets := requestSecrets(req)
+	redactedURL := RedactURL(req.URL.String())
+	entry.Request = harRequest{
+		Method:      req.Method,
+		URL:         redactedURL,
+		HTTPVersion: req.Proto,
+		Headers:     harHeaders(RedactHeader(req.Header)),
+		QueryString: harQuery(redactedURL),
+		HeadersSize: -1,
+		BodySize:    req.ContentLength,
+	}
+	// テキストのボディだけ記録する（アップロードなどのバイナリは記録しない）
+	contentType := req.Header.Get("Content-Type")
+	if req.GetBody != nil && isTextContent(contentType) {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := io.ReadAll(body)
+			body.Close()
+			entry.Request.PostData = &harPostData{MimeType: contentType, Text: scrub(string(data), secrets)}
+		}
+	}
+
+	resp, err := h.Next.RoundTrip(req)
+	elapsed := time.Since(entry.StartedDateTime)
+	entry.Time = float64(elapsed) / float64(time.Millisecond)
+	entry.Timings.Wait = entry.Time
+	if err != nil {
+		h.add(entry)
+		return nil, err
+	}
+
+	entry.Response = harResponse{
+		Status:      resp.StatusCode,
+		StatusText:  http.StatusText(resp.StatusCode),
+		HTTPVersion: resp.Proto,
+		Headers:     harHeaders(RedactHeader(resp.Header)),
+		Content: harContent{
+			Size:     resp.ContentLength,
+			MimeType: resp.Header.Get("Content-Type"),
+		},
+		HeadersSize: -1,
+		BodySize:    resp.ContentLength,
+	}
+	if isTextContent(entry.Response.Content.MimeType) {
+		data := readBody(&resp.Body)
+		entry.Response.Content.Text = scrub(string(data), secrets)
+		entry.Response.Content.Size = int64(len(data))
+		entry.Response.BodySize = int64(len(data))
+	}
+	h.add(entry)
+	return resp, nil
+}
+
+func (h *HARRecorder) add(entry harEntry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries = append(h.entries, entry)
+}
+
+// WriteFile saves the recorded session as a HAR 1.2 file.
+func (h *HARRecorder) WriteFile(path string) error {
+	h.mu.Lock()
+	var log harLog
+	log.Log.Version = "1.2"
+	log.Log.Creator = harCreator{Name: "rd", Version: "1"}
+	log.Log.Entries = append([]harEntry{}, h.entries...)
+	h.mu.Unlock()
+
+	data, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+func harHeaders(h http.Header) []harNameValue {
+	out := []harNameValue{}
+	for name, values := range h {
+		for _, v := range values {
+			out = append(out, harNameValue{Name: name, Value: v})
+		}
+	}
+	return out
+}
+
+func harQuery(rawURL string) []harNameValue {
+	out := []harNameValue{}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return out
+	}
+	for name, values := range u.Query() {
+		for _, v := range values {
+			out = append(out, harNameValue{Name: name, Value: v})
+		}
+	}
+	return out
+}
+
+// RecordHAR wraps the client's transport with a HARRecorder and returns it.
+func (c *Client) RecordHAR() *HARRecorder {
+	rec := NewHARRecorder(c.HTTPClient.Transport)
+	c.HTTPClient.Transport = rec
+	return rec
+}
@@ -1,6 +1,7 @@
 package redmine
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"strconv"
@@ -8,15 +9,61 @@ import (
 )
 
 type IssueFilter struct {
-	ProjectID  string
-	StatusID   string
-	AssignedTo string
-	ParentID   string
-	Limit      int
-	Offset     int
+	ProjectID string
+	// Subprojects includes (true) or excludes (false) issues of subprojects
+	// of ProjectID; nil leaves it to the server setting.
+	Subprojects *bool
+	// StatusID is a status ID, "open" (the default), "closed" or "*".
+	StatusID       string
+	AssignedTo     string
+	AuthorID       string
+	ParentID       string
+	TrackerID      string
+	PriorityID     string
+	CategoryID     string
+	FixedVersionID string
+	// Subject matches issues whose subject contains the text.
+	Subject   string
+	CreatedOn *DateRange
+	UpdatedOn *DateRange
+	ClosedOn  *DateRange
+	DueDate   *DateRange
+	// CustomFields filters by custom field ID (cf_N); several values are
+	// separated by "|".
+	CustomFields map[int]string
+	// QueryID applies a saved query; Redmine then ignores the other filters
+	// except ProjectID.
+	QueryID  int
+	IssueIDs []int
+	Limit    int
+	Offset   int
+}
+
+// DateRange filters a date attribute of issues. From and To are inclusive
+// dates (YYYY-MM-DD); either may be empty for an open-ended range.
+type DateRange struct {
+	From string
+	To   string
+}
+
+// param は Redmine の演算子付きの値（>=、<=、><）を返す。
+func (r *DateRange) param() string {
+	switch {
+	case r.From != "" && r.To != "":
+		return "><" + r.From + "|" + r.To
+	case r.From != "":
+		return ">=" + r.From
+	case r.To != "":
+		return "<=" + r.To
+	}
+	return ""
 }
 
 func (c *Client) ListIssues(filter *IssueFilter) (*IssuesResponse, error) {
+	return c.ListIssuesContext(context.Background(), filter)
+}
+
+func (c *Client) ListIssuesContext(ctx context.Context, filter *IssueFilter) (*IssuesResponse, error) {
 	params := url.Values{}
 	
 	if filter != nil {
@@ -32,6 +79,51 @@ func (c *Client) ListIssues(filter *IssueFilter) (*IssuesResponse, error) {
 		if filter.ParentID != "" {
 			params.Set("parent_id", filter.ParentID)
 		}
+		if filter.Subprojects != nil {
+			// subproject_id=!* でサブプロジェクトを除外する
+			if *filter.Subprojects {
+				params.Set("subproject_id", "*")
+			} else {
+				params.Set("subproject_id", "!*")
+			}
+		}
+		for name, value := range map[string]string{
+			"author_id":        filter.AuthorID,
+			"tracker_id":       filter.TrackerID,
+			"priority_id":      filter.PriorityID,
+			"category_id":      filter.CategoryID,
+			"fixed_version_id": filter.FixedVersionID,
+		} {
+			if value != "" {
+				params.Set(name, value)
+			}
+		}
+		if filter.Subject != "" {
+			params.Set("subject", "~"+filter.Subject)
+		}
+		for name, r := range map[string]*DateRange{
+			"created_on": filter.CreatedOn,
+			"updated_on": filter.UpdatedOn,
+			"closed_on":  filter.ClosedOn,
+			"due_date":   filter.DueDate,
+		} {
+			if r != nil && r.param() != "" {
+				params.Set(name, r.param())
+			}
+		}
+		for id, value := range filter.CustomFields {
+			params.Set(fmt.Sprintf("cf_%d", id), value)
+		}
+		if filter.QueryID != 0 {
+			params.Set("query_id", strconv.Itoa(filter.QueryID))
+		}
+		if len(filter.IssueIDs) > 0 {
+			ids := make([]string, len(filter.IssueIDs))
+			for i, id := range filter.IssueIDs {
+				ids[i] = strconv.Itoa(id)
+			}
+			params.Set("issue_id", strings.Join(ids, ","))
+		}
 		if filter.Limit > 0 {
 			params.Set("limit", strconv.Itoa(filter.Limit))
 		} else {
@@ -45,25 +137,54 @@ func (c *Client) ListIssues(filter *IssueFilter) (*IssuesResponse, error) {
 	}
 
 	var response IssuesResponse
-	if err := c.Get("/issues.json", params, &response); err != nil {
+	if err := c.GetContext(ctx, "/issues.json", params, &response); err != nil {
 		return nil, err
 	}
 
 	return &response, nil
 }
 
+// Associations GetIssueWith can include in the issue.
+const (
+	IncludeChildren    = "children"
+	IncludeAttachments = "attachments"
+	IncludeRelations   = "relations"
+	IncludeWatchers    = "watchers"
+	IncludeJournals    = "journals"
+)
+
+// GetIssue returns an issue with its children, attachments, relations,
+// watchers and, if includeJournals is set, its journals.
 func (c *Client) GetIssue(id int, includeJournals bool) (*Issue, error) {
+	return c.GetIssueContext(context.Background(), id, includeJournals)
+}
+
+// GetIssueContext is like GetIssue but uses ctx for the request.
+func (c *Client) GetIssueContext(ctx context.Context, id int, includeJournals bool) (*Issue, error) {
+	include := []string{IncludeChildren, IncludeAttachments, IncludeRelations, IncludeWatchers}
+	if includeJournals {
+		include = append(include, IncludeJournals)
+	}
+	return c.GetIssueWithContext(ctx, id, include...)
+}
+
+// GetIssueWith returns an issue with only the given associations (Include*).
+// Without any, only the issue's own attributes are fetched.
+func (c *Client) GetIssueWith(id int, include ...string) (*Issue, error) {
+	return c.GetIssueWithContext(context.Background(), id, include...)
+}
+
+// GetIssueWithContext is like GetIssueWith but uses ctx for the request.
+func (c *Client) GetIssueWithContext(ctx context.Context, id int, include ...string) (*Issue, error) {
 	path := fmt.Sprintf("/issues/%d.json", id)
 	
 	params := url.Values{}
-	includes := []string{"children"}
-	if includeJournals {
-		includes = append(includes, "journals")
+	if len(include) > 0 {
+		params.Set("include", strings.Join(include, ","))
 	}
-	params.Set("include", strings.Join(includes, ","))
 
 	var response IssueResponse
-	if err := c.Get(path, params, &response); err != nil {
+	if err := c.GetContext(ctx, path, params, &response); err != nil {
 		return nil, err
 	}
 
@@ -71,10 +192,14 @@ func (c *Client) GetIssue(id int, includeJournals bool) (*Issue, error) {
 }
 
 func (c *Client) CreateIssue(issue *IssueCreate) (*Issue, error) {
+	return c.CreateIssueContext(context.Background(), issue)
+}
+
+func (c *Client) CreateIssueContext(ctx context.Context, issue *IssueCreate) (*Issue, error) {
 	req := IssueCreateRequest{Issue: *issue}
 	
 	var response IssueResponse
-	if err := c.Post("/issues.json", nil, req, &response); err != nil {
+	if err := c.PostContext(ctx, "/issues.json", nil, req, &response); err != nil {
 		return nil, err
 	}
 
@@ -82,8 +207,12 @@ func (c *Client) CreateIssue(issue *IssueCreate) (*Issue, error) {
 }
 
 func (c *Client) UpdateIssue(id int, update *IssueUpdate) error {
+	return c.UpdateIssueContext(context.Background(), id, update)
+}
+
+func (c *Client) UpdateIssueContext(ctx context.Context, id int, update *IssueUpdate) error {
 	path := fmt.Sprintf("/issues/%d.json", id)
 	req := IssueUpdateRequest{Issue: *update}
 	
-	return c.Put(path, nil, req)
+	return c.PutContext(ctx, path, nil, req)
 }
\ No newline at end of file
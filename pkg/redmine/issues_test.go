@@ -0,0 +1,55 @@
+package redmine_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+	"github.com/ikasamt/rd/pkg/redmine/redminetest"
+)
+
+func TestGetIssueWithRequestsOnlyGivenIncludes(t *testing.T) {
+	srv := redminetest.NewServer()
+	defer srv.Close()
+	project := srv.AddProject("demo", "Demo")
+	issue := srv.AddIssue(redmine.IssueCreate{ProjectID: project.ID, Subject: "Hello"})
+
+	var trace bytes.Buffer
+	client := srv.Client()
+	client.Debug = true
+	client.DebugOutput = &trace
+
+	if _, err := client.GetIssueWith(issue.ID); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(trace.String(), "include") {
+		t.Errorf("GetIssueWith without includes requested some:\n%s", trace.String())
+	}
+
+	trace.Reset()
+	if _, err := client.GetIssueWith(issue.ID, redmine.IncludeJournals, redmine.IncludeWatchers); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(trace.String(), "include=journals%2Cwatchers") {
+		t.Errorf("GetIssueWith did not request journals and watchers:\n%s", trace.String())
+	}
+}
+
+func TestGetIssueIncludesEverythingShown(t *testing.T) {
+	srv := redminetest.NewServer()
+	defer srv.Close()
+	project := srv.AddProject("demo", "Demo")
+	issue := srv.AddIssue(redmine.IssueCreate{ProjectID: project.ID, Subject: "Hello"})
+
+	var trace bytes.Buffer
+	client := srv.Client()
+	client.Debug = true
+	client.DebugOutput = &trace
+	if _, err := client.GetIssue(issue.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(trace.String(), "include=children%2Cattachments%2Crelations%2Cwatchers%2Cjournals") {
+		t.Errorf("GetIssue did not request every association:\n%s", trace.String())
+	}
+}
@@ -0,0 +1,82 @@
+package redmine
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+type Role struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// Membership is a user or group that is a member of a project.
+type Membership struct {
+	ID      int     `json:"id"`
+	Project Project `json:"project"`
+	User    *User   `json:"user,omitempty"`
+	Group   *User   `json:"group,omitempty"`
+	Roles   []Role  `json:"roles"`
+}
+
+// Principal returns the member user or group.
+func (m *Membership) Principal() *User {
+	if m.User != nil {
+		return m.User
+	}
+	return m.Group
+}
+
+type MembershipsResponse struct {
+	Memberships []Membership `json:"memberships"`
+	TotalCount  int          `json:"total_count"`
+	Offset      int          `json:"offset"`
+	Limit       int          `json:"limit"`
+}
+
+// ListMemberships returns every member of a project. Unlike /users.json it
+// does not need admin rights.
+func (c *Client) ListMemberships(projectID string) ([]Membership, error) {
+	return c.ListMembershipsContext(context.Background(), projectID)
+}
+
+// ListMembershipsContext is like ListMemberships but uses ctx for the request.
+func (c *Client) ListMembershipsContext(ctx context.Context, projectID string) ([]Membership, error) {
+	memberships, _, err := c.listMemberships(ctx, projectID)
+	return memberships, err
+}
+
+// listMemberships は全ページを取得し、まとめてキャッシュする
+func (c *Client) listMemberships(ctx context.Context, projectID string) ([]Membership, bool, error) {
+	key := "memberships:" + projectID
+	var memberships []Membership
+	if c.Cache != nil && !isCacheRefresh(ctx) && c.Cache.load(ResourceUsers, key, &memberships) {
+		c.debugf("cache hit: %s %s", ResourceUsers, key)
+		return memberships, true, nil
+	}
+
+	path := fmt.Sprintf("/projects/%s/memberships.json", url.PathEscape(projectID))
+	pager := NewPaginator(func(ctx context.Context, offset, limit int) (*Page[Membership], error) {
+		params := url.Values{}
+		params.Set("limit", strconv.Itoa(limit))
+		params.Set("offset", strconv.Itoa(offset))
+		var response MembershipsResponse
+		if err := c.GetContext(ctx, path, params, &response); err != nil {
+			return nil, err
+		}
+		return &Page[Membership]{Items: response.Memberships, TotalCount: response.TotalCount, Offset: response.Offset, Limit: response.Limit}, nil
+	})
+	memberships, err := pager.All(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to list members of project '%s': %w", projectID, err)
+	}
+
+	if c.Cache != nil {
+		if err := c.Cache.store(ResourceUsers, key, memberships); err != nil {
+			c.debugf("cache store failed: %v", err)
+		}
+	}
+	return memberships, false, nil
+}
@@ -0,0 +1,263 @@
+package redmine
+
+import (
+	"context"
+	"sync"
+)
+
+// maxPageSize is the largest limit Redmine accepts for collection endpoints.
+const maxPageSize = 100
+
+// Page is a single page of a paginated Redmine collection.
+type Page[T any] struct {
+	Items      []T
+	TotalCount int
+	Offset     int
+	Limit      int
+}
+
+// PageFunc fetches up to limit items starting at offset.
+type PageFunc[T any] func(ctx context.Context, offset, limit int) (*Page[T], error)
+
+// Paginator walks a Redmine collection by following total_count/offset.
+type Paginator[T any] struct {
+	fetch PageFunc[T]
+
+	// PageSize is the number of items requested per page (at most 100).
+	PageSize int
+	// Offset is the index of the first item to fetch.
+	Offset int
+	// Max limits the number of items fetched. 0 fetches everything.
+	Max int
+	// Concurrency is the number of pages fetched in parallel once the
+	// first page has reported total_count. Values below 2 fetch sequentially.
+	Concurrency int
+
+	total int
+}
+
+// NewPaginator returns a sequential paginator using the largest page size.
+func NewPaginator[T any](fetch PageFunc[T]) *Paginator[T] {
+	return &Paginator[T]{
+		fetch:    fetch,
+		PageSize: maxPageSize,
+	}
+}
+
+// TotalCount returns the total_count reported by the last fetched page.
+func (p *Paginator[T]) TotalCount() int {
+	return p.total
+}
+
+// All fetches every item. On error it returns the items fetched so far
+// together with the error, so callers can report partial results.
+func (p *Paginator[T]) All(ctx context.Context) ([]T, error) {
+	var items []T
+	err := p.Pages(ctx, func(page *Page[T]) error {
+		items = append(items, page.Items...)
+		return nil
+	})
+	return items, err
+}
+
+// Each calls fn for every item in order. Iteration stops at the first error.
+func (p *Paginator[T]) Each(ctx context.Context, fn func(T) error) error {
+	return p.Pages(ctx, func(page *Page[T]) error {
+		for _, item := range page.Items {
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Pages calls fn for every page in order, even when pages are fetched concurrently.
+func (p *Paginator[T]) Pages(ctx context.Context, fn func(*Page[T]) error) error {
+	size := p.PageSize
+	if size <= 0 || size > maxPageSize {
+		size = maxPageSize
+	}
+	if p.Max > 0 && p.Max < size {
+		size = p.Max
+	}
+
+	first, err := p.fetch(ctx, p.Offset, size)
+	if err != nil {
+		return err
+	}
+	p.total = first.TotalCount
+	if err := fn(first); err != nil {
+		return err
+	}
+
+	fetched := len(first.Items)
+	end := p.total
+	if p.Max > 0 && p.Offset+p.Max < end {
+		end = p.Offset + p.Max
+	}
+	if fetched == 0 || p.Offset+fetched >= end {
+		return nil
+	}
+
+	// サーバーが limit を小さく制限していると最初のページが短くなる。
+	// 要求した件数で区切ると間の項目を飛ばすので、実際に返った件数で区切る
+	if fetched < size {
+		size = fetched
+	}
+
+	// 残りのページのoffsetを列挙
+	var offsets []int
+	for off := p.Offset + fetched; off < end; off += size {
+		offsets = append(offsets, off)
+	}
+	limitAt := func(off int) int {
+		if off+size > end {
+			return end - off
+		}
+		return size
+	}
+
+	if p.Concurrency < 2 {
+		for _, off := range offsets {
+			page, err := p.fetch(ctx, off, limitAt(off))
+			if err != nil {
+				return err
+			}
+			p.total = page.TotalCount
+			if len(page.Items) == 0 {
+				return nil
+			}
+			if err := fn(page); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return p.concurrentPages(ctx, offsets, limitAt, fn)
+}
+
+type pageResult[T any] struct {
+	page *Page[T]
+	err  error
+}
+
+// concurrentPages fetches pages with at most p.Concurrency pages in flight or
+// waiting to be consumed, and delivers them to fn in offset order.
+func (p *Paginator[T]) concurrentPages(ctx context.Context, offsets []int, limitAt func(int) int, fn func(*Page[T]) error) error {
+	// cancel は wg.Wait より先に実行され、途中で抜けた場合に残りの取得を止める
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]chan pageResult[T], len(offsets))
+	for i := range results {
+		results[i] = make(chan pageResult[T], 1)
+	}
+	sem := make(chan struct{}, p.Concurrency)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i, off := range offsets {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				for _, ch := range results[i:] {
+					ch <- pageResult[T]{err: ctx.Err()}
+				}
+				return
+			}
+			wg.Add(1)
+			go func(ch chan pageResult[T], off int) {
+				defer wg.Done()
+				page, err := p.fetch(ctx, off, limitAt(off))
+				ch <- pageResult[T]{page: page, err: err}
+			}(results[i], off)
+		}
+	}()
+
+	for _, ch := range results {
+		r := <-ch
+		if r.err != nil {
+			return r.err
+		}
+		// 受け取ってから枠を空けるので、先読みは p.Concurrency ページまでに限られる
+		<-sem
+		p.total = r.page.TotalCount
+		if len(r.page.Items) == 0 {
+			continue
+		}
+		if err := fn(r.page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IssuePaginator returns a paginator over issues matching filter.
+// filter.Limit and filter.Offset are ignored; use the paginator fields instead.
+func (c *Client) IssuePaginator(filter *IssueFilter) *Paginator[Issue] {
+	var base IssueFilter
+	if filter != nil {
+		base = *filter
+	}
+	return NewPaginator(func(ctx context.Context, offset, limit int) (*Page[Issue], error) {
+		f := base
+		f.Offset = offset
+		f.Limit = limit
+		resp, err := c.ListIssuesContext(ctx, &f)
+		if err != nil {
+			return nil, err
+		}
+		return &Page[Issue]{Items: resp.Issues, TotalCount: resp.TotalCount, Offset: resp.Offset, Limit: resp.Limit}, nil
+	})
+}
+
+// ProjectPaginator returns a paginator over all visible projects.
+func (c *Client) ProjectPaginator() *Paginator[Project] {
+	return NewPaginator(func(ctx context.Context, offset, limit int) (*Page[Project], error) {
+		resp, err := c.listProjectsPage(ctx, offset, limit)
+		if err != nil {
+			return nil, err
+		}
+		return &Page[Project]{Items: resp.Projects, TotalCount: resp.TotalCount, Offset: resp.Offset, Limit: resp.Limit}, nil
+	})
+}
+
+// SearchPaginator returns a paginator over search results.
+// opts.Limit and opts.Offset are ignored; use the paginator fields instead.
+func (c *Client) SearchPaginator(opts *SearchOptions) *Paginator[SearchResult] {
+	base := *opts
+	return NewPaginator(func(ctx context.Context, offset, limit int) (*Page[SearchResult], error) {
+		o := base
+		o.Offset = offset
+		o.Limit = limit
+		resp, err := c.SearchContext(ctx, &o)
+		if err != nil {
+			return nil, err
+		}
+		return &Page[SearchResult]{Items: resp.Results, TotalCount: resp.TotalCount, Offset: resp.Offset, Limit: resp.Limit}, nil
+	})
+}
+
+// TimeEntryPaginator returns a paginator over time entries matching filter.
+// filter.Limit and filter.Offset are ignored; use the paginator fields instead.
+func (c *Client) TimeEntryPaginator(filter *TimeEntryFilter) *Paginator[TimeEntry] {
+	var base TimeEntryFilter
+	if filter != nil {
+		base = *filter
+	}
+	return NewPaginator(func(ctx context.Context, offset, limit int) (*Page[TimeEntry], error) {
+		f := base
+		f.Offset = offset
+		f.Limit = limit
+		resp, err := c.ListTimeEntriesContext(ctx, &f)
+		if err != nil {
+			return nil, err
+		}
+		return &Page[TimeEntry]{Items: resp.TimeEntries, TotalCount: resp.TotalCount, Offset: resp.Offset, Limit: resp.Limit}, nil
+	})
+}
@@ -0,0 +1,150 @@
+package redmine_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+)
+
+// fakeCollection は 0..total-1 の整数を返す。limit は maxLimit までに切り詰め、
+// failAt の offset から始まるページは失敗させる。
+type fakeCollection struct {
+	total    int
+	maxLimit int
+	failAt   int
+	calls    int32
+}
+
+var errPage = errors.New("page failed")
+
+func (f *fakeCollection) fetch(ctx context.Context, offset, limit int) (*redmine.Page[int], error) {
+	atomic.AddInt32(&f.calls, 1)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if f.failAt > 0 && offset == f.failAt {
+		return nil, errPage
+	}
+	if f.maxLimit > 0 && limit > f.maxLimit {
+		limit = f.maxLimit
+	}
+	page := &redmine.Page[int]{TotalCount: f.total, Offset: offset, Limit: limit}
+	for i := offset; i < offset+limit && i < f.total; i++ {
+		page.Items = append(page.Items, i)
+	}
+	return page, nil
+}
+
+func span(from, to int) []int {
+	var items []int
+	for i := from; i < to; i++ {
+		items = append(items, i)
+	}
+	return items
+}
+
+func TestPaginatorAll(t *testing.T) {
+	tests := []struct {
+		name     string
+		total    int
+		maxLimit int
+		pageSize int
+		offset   int
+		max      int
+		want     []int
+	}{
+		{"empty", 0, 0, 0, 0, 0, nil},
+		{"single page", 30, 0, 0, 0, 0, span(0, 30)},
+		{"several pages", 250, 0, 0, 0, 0, span(0, 250)},
+		{"small page size", 25, 0, 10, 0, 0, span(0, 25)},
+		{"offset", 250, 0, 0, 120, 0, span(120, 250)},
+		{"max", 250, 0, 0, 0, 130, span(0, 130)},
+		{"offset and max", 250, 0, 50, 10, 95, span(10, 105)},
+		{"offset past the end", 10, 0, 0, 20, 0, nil},
+		{"server caps the limit", 250, 30, 0, 0, 0, span(0, 250)},
+		{"server caps the limit with offset and max", 250, 30, 0, 5, 100, span(5, 105)},
+	}
+	for _, tt := range tests {
+		for _, concurrency := range []int{1, 4} {
+			f := &fakeCollection{total: tt.total, maxLimit: tt.maxLimit}
+			p := redmine.NewPaginator(f.fetch)
+			if tt.pageSize > 0 {
+				p.PageSize = tt.pageSize
+			}
+			p.Offset, p.Max, p.Concurrency = tt.offset, tt.max, concurrency
+			got, err := p.All(context.Background())
+			if err != nil {
+				t.Fatalf("%s (concurrency %d): %v", tt.name, concurrency, err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("%s (concurrency %d): got %d items %v..., want %d", tt.name, concurrency, len(got), head(got), len(tt.want))
+			}
+			if p.TotalCount() != tt.total {
+				t.Errorf("%s (concurrency %d): TotalCount = %d, want %d", tt.name, concurrency, p.TotalCount(), tt.total)
+			}
+		}
+	}
+}
+
+func head(items []int) []int {
+	if len(items) > 5 {
+		return items[:5]
+	}
+	return items
+}
+
+func TestPaginatorStopsAtFailedPage(t *testing.T) {
+	for _, concurrency := range []int{1, 4} {
+		f := &fakeCollection{total: 1000, failAt: 300}
+		p := redmine.NewPaginator(f.fetch)
+		p.Concurrency = concurrency
+		got, err := p.All(context.Background())
+		if !errors.Is(err, errPage) {
+			t.Fatalf("concurrency %d: err = %v, want the page error", concurrency, err)
+		}
+		// 失敗したページより前の項目だけが順に返る
+		if !slices.Equal(got, span(0, 300)) {
+			t.Errorf("concurrency %d: got %d items, want the 300 before the failure", concurrency, len(got))
+		}
+	}
+}
+
+func TestPaginatorStopsWhenCallbackFailsOrContextIsCancelled(t *testing.T) {
+	for _, concurrency := range []int{1, 4} {
+		f := &fakeCollection{total: 10000}
+		p := redmine.NewPaginator(f.fetch)
+		p.Concurrency = concurrency
+		stop := errors.New("stop")
+		var seen []int
+		err := p.Each(context.Background(), func(i int) error {
+			seen = append(seen, i)
+			if i == 150 {
+				return stop
+			}
+			return nil
+		})
+		if !errors.Is(err, stop) || !slices.Equal(seen, span(0, 151)) {
+			t.Errorf("concurrency %d: err = %v after %d items", concurrency, err, len(seen))
+		}
+		// 残りのページを最後まで取りに行かない
+		if calls := atomic.LoadInt32(&f.calls); calls > int32(2+concurrency) {
+			t.Errorf("concurrency %d: %d pages fetched after stopping", concurrency, calls)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		f = &fakeCollection{total: 10000}
+		p = redmine.NewPaginator(f.fetch)
+		p.Concurrency = concurrency
+		err = p.Pages(ctx, func(page *redmine.Page[int]) error {
+			cancel()
+			return nil
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("concurrency %d: err = %v, want context.Canceled", concurrency, err)
+		}
+	}
+}
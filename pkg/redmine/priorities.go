@@ -0,0 +1,33 @@
+package redmine
+
+import "context"
+
+type IssuePriority struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	IsDefault bool   `json:"is_default"`
+	Active    bool   `json:"active"`
+}
+
+type IssuePrioritiesResponse struct {
+	IssuePriorities []IssuePriority `json:"issue_priorities"`
+}
+
+func (c *Client) ListIssuePriorities() ([]IssuePriority, error) {
+	return c.ListIssuePrioritiesContext(context.Background())
+}
+
+// ListIssuePrioritiesContext is like ListIssuePriorities but uses ctx for the request.
+func (c *Client) ListIssuePrioritiesContext(ctx context.Context) ([]IssuePriority, error) {
+	priorities, _, err := c.listIssuePriorities(ctx)
+	return priorities, err
+}
+
+func (c *Client) listIssuePriorities(ctx context.Context) ([]IssuePriority, bool, error) {
+	var response IssuePrioritiesResponse
+	cached, err := c.cachedGet(ctx, ResourceIssuePriorities, "", "/enumerations/issue_priorities.json", nil, &response)
+	if err != nil {
+		return nil, false, err
+	}
+	return response.IssuePriorities, cached, nil
+}
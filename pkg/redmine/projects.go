@@ -1,8 +1,10 @@
 package redmine
 
 import (
+	"context"
 	"fmt"
 	"net/url"
+	"strconv"
 )
 
 type ProjectsResponse struct {
@@ -17,21 +19,44 @@ type ProjectResponse struct {
 }
 
 type ProjectDetail struct {
-	ID          int      `json:"id"`
-	Name        string   `json:"name"`
-	Identifier  string   `json:"identifier"`
-	Description string   `json:"description"`
-	Status      int      `json:"status"`
-	IsPublic    bool     `json:"is_public"`
-	Trackers    []Tracker `json:"trackers,omitempty"`
+	ID                int                     `json:"id"`
+	Name              string                  `json:"name"`
+	Identifier        string                  `json:"identifier"`
+	Description       string                  `json:"description"`
+	Status            int                     `json:"status"`
+	IsPublic          bool                    `json:"is_public"`
+	Trackers          []Tracker               `json:"trackers,omitempty"`
+	IssueCustomFields []CustomFieldDefinition `json:"issue_custom_fields,omitempty"`
 }
 
 func (c *Client) ListProjects() (*ProjectsResponse, error) {
+	return c.ListProjectsContext(context.Background())
+}
+
+// ListProjectsContext fetches every visible project, following pagination.
+func (c *Client) ListProjectsContext(ctx context.Context) (*ProjectsResponse, error) {
+	p := c.ProjectPaginator()
+	projects, err := p.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProjectsResponse{
+		Projects:   projects,
+		TotalCount: p.TotalCount(),
+		Limit:      len(projects),
+	}, nil
+}
+
+func (c *Client) listProjectsPage(ctx context.Context, offset, limit int) (*ProjectsResponse, error) {
 	params := url.Values{}
-	params.Set("limit", "100")
+	params.Set("limit", strconv.Itoa(limit))
+	if offset > 0 {
+		params.Set("offset", strconv.Itoa(offset))
+	}
 	
 	var response ProjectsResponse
-	if err := c.Get("/projects.json", params, &response); err != nil {
+	if err := c.GetContext(ctx, "/projects.json", params, &response); err != nil {
 		return nil, err
 	}
 
@@ -39,10 +64,14 @@ func (c *Client) ListProjects() (*ProjectsResponse, error) {
 }
 
 func (c *Client) GetProject(id string) (*ProjectDetail, error) {
+	return c.GetProjectContext(context.Background(), id)
+}
+
+func (c *Client) GetProjectContext(ctx context.Context, id string) (*ProjectDetail, error) {
 	path := fmt.Sprintf("/projects/%s.json", id)
 	
 	var response ProjectResponse
-	if err := c.Get(path, nil, &response); err != nil {
+	if err := c.GetContext(ctx, path, nil, &response); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,63 @@
+package redmine
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// Query is a saved issue query (custom query) visible to the user.
+// ProjectID is nil for queries available in every project.
+type Query struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	IsPublic  bool   `json:"is_public"`
+	ProjectID *int   `json:"project_id,omitempty"`
+}
+
+type QueriesResponse struct {
+	Queries    []Query `json:"queries"`
+	TotalCount int     `json:"total_count"`
+	Offset     int     `json:"offset"`
+	Limit      int     `json:"limit"`
+}
+
+// ListQueries returns every saved issue query visible to the user.
+func (c *Client) ListQueries() ([]Query, error) {
+	return c.ListQueriesContext(context.Background())
+}
+
+// ListQueriesContext is like ListQueries but uses ctx for the request.
+func (c *Client) ListQueriesContext(ctx context.Context) ([]Query, error) {
+	var queries []Query
+	for {
+		params := url.Values{}
+		params.Set("limit", strconv.Itoa(maxPageSize))
+		params.Set("offset", strconv.Itoa(len(queries)))
+		var response QueriesResponse
+		if err := c.GetContext(ctx, "/queries.json", params, &response); err != nil {
+			return nil, fmt.Errorf("failed to list queries: %w", err)
+		}
+		queries = append(queries, response.Queries...)
+		if len(response.Queries) == 0 || len(queries) >= response.TotalCount {
+			break
+		}
+	}
+	return queries, nil
+}
+
+// ResolveQuery accepts the ID, name or unambiguous name prefix of a saved
+// query.
+func (c *Client) ResolveQuery(value string) (*Query, error) {
+	return c.ResolveQueryContext(context.Background(), value)
+}
+
+// ResolveQueryContext is like ResolveQuery but uses ctx for the request.
+func (c *Client) ResolveQueryContext(ctx context.Context, value string) (*Query, error) {
+	queries, err := c.ListQueriesContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return resolveNamed("query", "", value, queries, func(q *Query) (int, string) { return q.ID, q.Name })
+}
@@ -0,0 +1,46 @@
+package redmine_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+	"github.com/ikasamt/rd/pkg/redmine/redminetest"
+)
+
+func TestResolveQuery(t *testing.T) {
+	srv := redminetest.NewServer()
+	defer srv.Close()
+	srv.AddProject("demo", "Demo")
+	// 1ページ（100件）に収まらない数を登録する
+	for i := 1; i <= 120; i++ {
+		srv.AddQuery("", fmt.Sprintf("Report %03d", i), nil)
+	}
+	openBugs := srv.AddQuery("demo", "Open bugs", nil)
+	srv.AddQuery("", "Open tasks", nil)
+	client := srv.Client()
+
+	queries, err := client.ListQueries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queries) != 122 {
+		t.Errorf("ListQueries returned %d queries, want 122", len(queries))
+	}
+
+	for _, value := range []string{"Open bugs", "open BUGS", "open b", fmt.Sprint(openBugs.ID)} {
+		q, err := client.ResolveQuery(value)
+		if err != nil || q.ID != openBugs.ID || q.ProjectID == nil {
+			t.Errorf("ResolveQuery(%q) = %+v, %v, want #%d", value, q, err, openBugs.ID)
+		}
+	}
+
+	for _, value := range []string{"Open", "Weekly"} {
+		_, err := client.ResolveQuery(value)
+		var resolveErr *redmine.ResolveError
+		if !errors.As(err, &resolveErr) || resolveErr.Ambiguous != (value == "Open") {
+			t.Errorf("ResolveQuery(%q): err = %v", value, err)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package redmine
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket that caps the request rate. It is safe for
+// concurrent use, so one limiter throttles every goroutine sharing a client.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter allows rps requests per second on average and bursts of up
+// to burst requests. A burst below 1 is treated as 1.
+func NewRateLimiter(rps float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:   rps,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until a request may be sent and returns how long it was delayed.
+func (l *RateLimiter) Wait(ctx context.Context) (time.Duration, error) {
+	if l == nil || l.rate <= 0 {
+		return 0, nil
+	}
+
+	// トークンを先に予約し、不足分だけ待つ（待機中の他のgoroutineとも順番が保たれる）
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	l.tokens--
+	var wait time.Duration
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if wait == 0 {
+		return 0, nil
+	}
+	if err := sleepContext(ctx, wait); err != nil {
+		// 送らなかったリクエストの分は返却する
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return 0, err
+	}
+	return wait, nil
+}
@@ -0,0 +1,114 @@
+package redmine_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+	"github.com/ikasamt/rd/pkg/redmine/redminetest"
+)
+
+func TestRateLimiterAllowsBurst(t *testing.T) {
+	ctx := context.Background()
+	limiter := redmine.NewRateLimiter(20, 3)
+	for i := 0; i < 3; i++ {
+		if delay, err := limiter.Wait(ctx); err != nil || delay != 0 {
+			t.Fatalf("request %d: delay %s, %v, want none within the burst", i+1, delay, err)
+		}
+	}
+	start := time.Now()
+	delay, err := limiter.Wait(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 20 件/秒なので次のトークンまで 50ms
+	if delay <= 0 || delay > 50*time.Millisecond {
+		t.Errorf("delay after burst = %s, want about 50ms", delay)
+	}
+	if elapsed := time.Since(start); elapsed < delay {
+		t.Errorf("Wait returned after %s, before its reported delay %s", elapsed, delay)
+	}
+}
+
+func TestRateLimiterRefills(t *testing.T) {
+	ctx := context.Background()
+	limiter := redmine.NewRateLimiter(20, 2)
+	limiter.Wait(ctx)
+	limiter.Wait(ctx)
+
+	// 100ms で2トークン戻る
+	time.Sleep(100 * time.Millisecond)
+	for i := 0; i < 2; i++ {
+		if delay, _ := limiter.Wait(ctx); delay != 0 {
+			t.Errorf("request %d after refill: delay %s, want none", i+1, delay)
+		}
+	}
+	if delay, _ := limiter.Wait(ctx); delay == 0 {
+		t.Error("third request after refill was not delayed")
+	}
+
+	// 長く空いてもバースト以上は貯まらない
+	limiter = redmine.NewRateLimiter(100, 1)
+	time.Sleep(50 * time.Millisecond)
+	limiter.Wait(ctx)
+	if delay, _ := limiter.Wait(ctx); delay == 0 {
+		t.Error("tokens accumulated beyond the burst")
+	}
+}
+
+func TestRateLimiterStopsWhenContextIsCancelled(t *testing.T) {
+	limiter := redmine.NewRateLimiter(10, 1)
+	limiter.Wait(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := limiter.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want the context error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 80*time.Millisecond {
+		t.Errorf("Wait blocked %s after the context ended", elapsed)
+	}
+
+	// 取り消した分のトークンは返却され、次の待ち時間は1件分だけ
+	delay, err := limiter.Wait(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delay > 100*time.Millisecond {
+		t.Errorf("delay after a cancelled wait = %s, want at most 100ms", delay)
+	}
+}
+
+func TestRateLimiterDisabled(t *testing.T) {
+	for name, limiter := range map[string]*redmine.RateLimiter{
+		"nil":       nil,
+		"zero rate": redmine.NewRateLimiter(0, 1),
+	} {
+		for i := 0; i < 5; i++ {
+			if delay, err := limiter.Wait(context.Background()); delay != 0 || err != nil {
+				t.Errorf("%s: delay %s, %v, want none", name, delay, err)
+			}
+		}
+	}
+}
+
+func TestClientWaitsForRateLimit(t *testing.T) {
+	srv := redminetest.NewServer()
+	defer srv.Close()
+	srv.AddProject("demo", "Demo")
+	client := srv.Client()
+	client.RateLimit = redmine.NewRateLimiter(20, 1)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := client.GetProject("demo"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("3 requests at 20/s with burst 1 took %s, want at least 100ms", elapsed)
+	}
+}
@@ -0,0 +1,735 @@
+package redminetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+)
+
+// idName is the {"id":..,"name":..} reference Redmine embeds in issues.
+type idName struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type issue struct {
+	redmine.Issue
+	FixedVersion *idName `json:"fixed_version,omitempty"`
+	Category     *idName `json:"category,omitempty"`
+
+	attachments []*Attachment
+	watchers    []int
+}
+
+// AddIssue stores an issue as if it had been created by the admin user.
+func (s *Server) AddIssue(create redmine.IssueCreate) redmine.Issue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	is, errs := s.newIssue(&create, s.Admin)
+	if len(errs) > 0 {
+		panic("redminetest: " + strings.Join(errs, ", "))
+	}
+	return is.Issue
+}
+
+// Issue returns the stored issue, including journals, or false when it does
+// not exist.
+func (s *Server) Issue(id int) (redmine.Issue, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	is := s.findIssue(id)
+	if is == nil {
+		return redmine.Issue{}, false
+	}
+	return is.Issue, true
+}
+
+func (s *Server) findIssue(id int) *issue {
+	for _, is := range s.issues {
+		if is.ID == id {
+			return is
+		}
+	}
+	return nil
+}
+
+func (s *Server) findStatus(id int) *redmine.IssueStatus {
+	for i := range s.statuses {
+		if s.statuses[i].ID == id {
+			return &s.statuses[i]
+		}
+	}
+	return nil
+}
+
+func (s *Server) findTracker(id int) *redmine.Tracker {
+	for i := range s.trackers {
+		if s.trackers[i].ID == id {
+			return &s.trackers[i]
+		}
+	}
+	return nil
+}
+
+func (s *Server) findPriority(id int) *redmine.Priority {
+	for i := range s.priorities {
+		if s.priorities[i].ID == id {
+			return &s.priorities[i]
+		}
+	}
+	return nil
+}
+
+// newIssue validates create and stores the issue, returning Redmine style
+// validation messages on failure.
+func (s *Server) newIssue(create *redmine.IssueCreate, author *User) (*issue, []string) {
+	var errs []string
+	p := s.findProject(strconv.Itoa(create.ProjectID))
+	if p == nil {
+		errs = append(errs, "Project cannot be blank")
+	}
+	if strings.TrimSpace(create.Subject) == "" {
+		errs = append(errs, "Subject cannot be blank")
+	}
+
+	// 未指定の項目は Redmine の既定値 (Bug / New / Normal) にする
+	trackerID, statusID, priorityID := create.TrackerID, create.StatusID, create.PriorityID
+	if trackerID == 0 {
+		trackerID = 1
+	}
+	if statusID == 0 {
+		statusID = 1
+	}
+	if priorityID == 0 {
+		priorityID = 2
+	}
+	tracker, status, priority := s.findTracker(trackerID), s.findStatus(statusID), s.findPriority(priorityID)
+	if tracker == nil {
+		errs = append(errs, "Tracker is not included in the list")
+	}
+	if status == nil {
+		errs = append(errs, "Status is not included in the list")
+	}
+	if priority == nil {
+		errs = append(errs, "Priority is not included in the list")
+	}
+
+	var category *redmine.IssueCategory
+	if create.CategoryID != 0 && p != nil {
+		if category = s.findCategory(p.ID, create.CategoryID); category == nil {
+			errs = append(errs, "Category is not included in the list")
+		}
+	}
+	var assignee *User
+	if create.AssignedToID != 0 {
+		if assignee = s.userByID(create.AssignedToID); assignee == nil {
+			errs = append(errs, "Assignee is invalid")
+		}
+	}
+	if create.ParentIssueID != 0 && s.findIssue(create.ParentIssueID) == nil {
+		errs = append(errs, "Parent task is invalid")
+	}
+	for _, id := range create.WatcherUserIDs {
+		if s.userByID(id) == nil {
+			errs = append(errs, "Watchers is invalid")
+			break
+		}
+	}
+	errs = append(errs, s.validateCustomFields(create.CustomFields)...)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	files, errs := s.takeUploads(create.Uploads)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	now := s.now()
+	is := &issue{Issue: redmine.Issue{
+		ID:          s.id("issue"),
+		Project:     redmine.Project{ID: p.ID, Name: p.Name},
+		Tracker:     *tracker,
+		Status:      redmine.Status{ID: status.ID, Name: status.Name},
+		Priority:    *priority,
+		Author:      redmine.User{ID: author.ID, Name: author.Name()},
+		Subject:     create.Subject,
+		Description: create.Description,
+		DoneRatio:   create.DoneRatio,
+		CreatedOn:   now,
+		UpdatedOn:   now,
+	}, attachments: files}
+	for _, id := range create.WatcherUserIDs {
+		if !containsInt(is.watchers, id) {
+			is.watchers = append(is.watchers, id)
+		}
+	}
+	if assignee != nil {
+		is.AssignedTo = &redmine.User{ID: assignee.ID, Name: assignee.Name()}
+	}
+	if category != nil {
+		is.Category = &idName{ID: category.ID, Name: category.Name}
+	}
+	if status.IsClosed {
+		is.ClosedOn = &now
+	}
+	if create.ParentIssueID != 0 {
+		is.Parent = &redmine.IssueParent{ID: create.ParentIssueID}
+	}
+	if create.StartDate != "" {
+		is.StartDate = &create.StartDate
+	}
+	if create.DueDate != "" {
+		is.DueDate = &create.DueDate
+	}
+	if create.EstimatedHours != 0 {
+		hours := create.EstimatedHours
+		is.EstimatedHours = &hours
+	}
+	// Redmine は値が未設定でも全カスタムフィールドを返す
+	for _, cf := range s.customFields {
+		var value interface{} = ""
+		if cf.Multiple {
+			value = []string{}
+		}
+		is.CustomFields = append(is.CustomFields, redmine.CustomField{ID: cf.ID, Name: cf.Name, Multiple: cf.Multiple, Value: value})
+	}
+	for _, v := range create.CustomFields {
+		s.setCustomField(is, v)
+	}
+	s.issues = append(s.issues, is)
+	return is, nil
+}
+
+// validateCustomFields checks the values of list fields against their
+// possible values, as Redmine does.
+func (s *Server) validateCustomFields(values []redmine.CustomFieldValue) []string {
+	var errs []string
+	for _, v := range values {
+		for _, cf := range s.customFields {
+			if cf.ID != v.ID {
+				continue
+			}
+			var items []string
+			switch value := v.Value.(type) {
+			case []interface{}:
+				for _, item := range value {
+					items = append(items, fmt.Sprint(item))
+				}
+			case []string:
+				items = value
+			default:
+				items = []string{fmt.Sprint(value)}
+			}
+			if _, ok := v.Value.(string); !ok && !cf.Multiple {
+				errs = append(errs, cf.Name+" is invalid")
+				continue
+			}
+			for _, item := range items {
+				if item == "" || len(cf.PossibleValues) == 0 {
+					continue
+				}
+				allowed := false
+				for _, pv := range cf.PossibleValues {
+					allowed = allowed || pv.Value == item
+				}
+				if !allowed {
+					errs = append(errs, cf.Name+" is not included in the list")
+					break
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// setCustomField stores v and returns the previous value as a string.
+// Unknown fields are ignored, as Redmine does.
+func (s *Server) setCustomField(is *issue, v redmine.CustomFieldValue) (string, bool) {
+	for i := range is.CustomFields {
+		if is.CustomFields[i].ID == v.ID {
+			old := fmt.Sprint(is.CustomFields[i].Value)
+			is.CustomFields[i].Value = v.Value
+			return old, true
+		}
+	}
+	return "", false
+}
+
+// matchStatus implements the status_id filter: open (default), closed, * or an ID.
+func (s *Server) matchStatus(is *issue, filter string) bool {
+	switch filter {
+	case "*":
+		return true
+	case "", "open", "o":
+		status := s.findStatus(is.Status.ID)
+		return status == nil || !status.IsClosed
+	case "closed", "c":
+		status := s.findStatus(is.Status.ID)
+		return status != nil && status.IsClosed
+	}
+	return containsID(filter, is.Status.ID)
+}
+
+// isSubproject reports whether project id is below project ancestorID.
+func (s *Server) isSubproject(id, ancestorID int) bool {
+	for p := s.findProject(strconv.Itoa(id)); p != nil && p.Parent != nil; p = s.findProject(strconv.Itoa(p.Parent.ID)) {
+		if p.Parent.ID == ancestorID {
+			return true
+		}
+	}
+	return false
+}
+
+// matchOptionalID implements filters on optional attributes such as the
+// category: "*" (any), "!*" (none) or a list of IDs.
+func matchOptionalID(filter string, v *idName) bool {
+	switch filter {
+	case "":
+		return true
+	case "*":
+		return v != nil
+	case "!*":
+		return v == nil
+	}
+	return v != nil && containsID(filter, v.ID)
+}
+
+// matchText implements text filters: "~text" (contains, case-insensitive) or
+// an exact value.
+func matchText(filter, text string) bool {
+	if strings.HasPrefix(filter, "~") {
+		return strings.Contains(strings.ToLower(text), strings.ToLower(filter[1:]))
+	}
+	return filter == text
+}
+
+// matchDate implements date filters: ">=date", "<=date", "><from|to", "*",
+// "!*" or an exact date. date is empty when the attribute is not set.
+func matchDate(filter, date string) bool {
+	switch {
+	case filter == "":
+		return true
+	case filter == "*":
+		return date != ""
+	case filter == "!*":
+		return date == ""
+	case date == "":
+		return false
+	case strings.HasPrefix(filter, ">="):
+		return date >= filter[2:]
+	case strings.HasPrefix(filter, "<="):
+		return date <= filter[2:]
+	case strings.HasPrefix(filter, "><"):
+		from, to, _ := strings.Cut(filter[2:], "|")
+		return date >= from && date <= to
+	}
+	return date == filter
+}
+
+// matchCustomFields implements the cf_N filters. Several values are
+// separated by "|"; a multi-value field matches if any value matches.
+func matchCustomFields(q url.Values, is *issue) bool {
+	for key := range q {
+		id, err := strconv.Atoi(strings.TrimPrefix(key, "cf_"))
+		if !strings.HasPrefix(key, "cf_") || err != nil {
+			continue
+		}
+		var values []string
+		for _, cf := range is.CustomFields {
+			if cf.ID != id {
+				continue
+			}
+			switch v := cf.Value.(type) {
+			case []interface{}:
+				for _, item := range v {
+					values = append(values, fmt.Sprint(item))
+				}
+			case []string:
+				values = v
+			case nil:
+			default:
+				values = []string{fmt.Sprint(v)}
+			}
+		}
+		matched := false
+		for _, want := range strings.Split(q.Get(key), "|") {
+			for _, v := range values {
+				matched = matched || matchText(want, v)
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+func formatDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format("2006-01-02")
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// containsID reports whether id appears in a comma or pipe separated list.
+func containsID(list string, id int) bool {
+	for _, part := range strings.FieldsFunc(list, func(r rune) bool { return r == ',' || r == '|' }) {
+		if n, err := strconv.Atoi(strings.TrimSpace(part)); err == nil && n == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) listIssues(w http.ResponseWriter, r *http.Request, user *User, _ []string) {
+	q := r.URL.Query()
+	if q.Get("query_id") != "" {
+		var ok bool
+		if q, ok = s.applyQuery(q); !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+	}
+
+	var projectID int
+	if v := q.Get("project_id"); v != "" {
+		p := s.findProject(v)
+		if p == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		projectID = p.ID
+	}
+	// Redmine の既定の設定と同じく、サブプロジェクトのチケットも含める
+	subprojects := q.Get("subproject_id") != "!*"
+
+	matched := []redmine.Issue{}
+	// Redmine の既定の並び順は ID の降順
+	for i := len(s.issues) - 1; i >= 0; i-- {
+		is := s.issues[i]
+		if projectID != 0 && is.Project.ID != projectID && (!subprojects || !s.isSubproject(is.Project.ID, projectID)) {
+			continue
+		}
+		if !s.matchStatus(is, q.Get("status_id")) {
+			continue
+		}
+		if v := q.Get("assigned_to_id"); v != "" {
+			if is.AssignedTo == nil {
+				continue
+			}
+			if v == "me" {
+				if is.AssignedTo.ID != user.ID {
+					continue
+				}
+			} else if !containsID(v, is.AssignedTo.ID) {
+				continue
+			}
+		}
+		if v := q.Get("parent_id"); v != "" && (is.Parent == nil || !containsID(v, is.Parent.ID)) {
+			continue
+		}
+		if v := q.Get("tracker_id"); v != "" && !containsID(v, is.Tracker.ID) {
+			continue
+		}
+		if v := q.Get("issue_id"); v != "" && !containsID(v, is.ID) {
+			continue
+		}
+		if v := q.Get("author_id"); v != "" && !(v == "me" && is.Author.ID == user.ID) && !containsID(v, is.Author.ID) {
+			continue
+		}
+		if v := q.Get("priority_id"); v != "" && !containsID(v, is.Priority.ID) {
+			continue
+		}
+		if !matchOptionalID(q.Get("category_id"), is.Category) || !matchOptionalID(q.Get("fixed_version_id"), is.FixedVersion) {
+			continue
+		}
+		if v := q.Get("subject"); v != "" && !matchText(v, is.Subject) {
+			continue
+		}
+		if !matchDate(q.Get("created_on"), formatDate(&is.CreatedOn)) ||
+			!matchDate(q.Get("updated_on"), formatDate(&is.UpdatedOn)) ||
+			!matchDate(q.Get("closed_on"), formatDate(is.ClosedOn)) ||
+			!matchDate(q.Get("due_date"), derefString(is.DueDate)) {
+			continue
+		}
+		if !matchCustomFields(q, is) {
+			continue
+		}
+		summary := is.Issue
+		summary.Journals = nil
+		summary.Children = nil
+		matched = append(matched, summary)
+	}
+
+	offset, limit := paginate(r, len(matched))
+	writeJSON(w, http.StatusOK, redmine.IssuesResponse{
+		Issues:     matched[offset:pageEnd(offset, limit, len(matched))],
+		TotalCount: len(matched),
+		Offset:     offset,
+		Limit:      limit,
+	})
+}
+
+func (s *Server) createIssue(w http.ResponseWriter, r *http.Request, user *User, _ []string) {
+	var req redmine.IssueCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	is, errs := s.newIssue(&req.Issue, user)
+	if len(errs) > 0 {
+		writeErrors(w, errs...)
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"issue": is})
+}
+
+func (s *Server) getIssue(w http.ResponseWriter, r *http.Request, _ *User, params []string) {
+	id, _ := strconv.Atoi(params[0])
+	is := s.findIssue(id)
+	if is == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	include := r.URL.Query().Get("include")
+	out := *is
+	out.Journals = nil
+	if strings.Contains(include, "journals") {
+		out.Journals = append([]redmine.Journal{}, is.Journals...)
+	}
+	if strings.Contains(include, "attachments") {
+		for _, a := range is.attachments {
+			out.Attachments = append(out.Attachments, s.attachmentJSON(a))
+		}
+	}
+	if strings.Contains(include, "watchers") {
+		out.Watchers = s.watcherJSON(is)
+	}
+	if strings.Contains(include, "relations") {
+		out.Relations = s.issueRelations(id)
+	}
+	if strings.Contains(include, "children") {
+		for _, child := range s.issues {
+			if child.Parent != nil && child.Parent.ID == id {
+				out.Children = append(out.Children, redmine.IssueChild{ID: child.ID, Tracker: child.Tracker, Subject: child.Subject})
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"issue": out})
+}
+
+func (s *Server) updateIssue(w http.ResponseWriter, r *http.Request, user *User, params []string) {
+	id, _ := strconv.Atoi(params[0])
+	is := s.findIssue(id)
+	if is == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	var req redmine.IssueUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	u := req.Issue
+
+	// 先に全項目を検証し、エラーがあれば何も変更しない
+	var errs []string
+	if u.Subject != nil && strings.TrimSpace(*u.Subject) == "" {
+		errs = append(errs, "Subject cannot be blank")
+	}
+	if u.StatusID != nil && s.findStatus(*u.StatusID) == nil {
+		errs = append(errs, "Status is not included in the list")
+	}
+	if u.TrackerID != nil && s.findTracker(*u.TrackerID) == nil {
+		errs = append(errs, "Tracker is not included in the list")
+	}
+	if u.PriorityID != nil && s.findPriority(*u.PriorityID) == nil {
+		errs = append(errs, "Priority is not included in the list")
+	}
+	if u.CategoryID != nil && *u.CategoryID != 0 && s.findCategory(is.Project.ID, *u.CategoryID) == nil {
+		errs = append(errs, "Category is not included in the list")
+	}
+	if u.AssignedToID != nil && *u.AssignedToID != 0 && s.userByID(*u.AssignedToID) == nil {
+		errs = append(errs, "Assignee is invalid")
+	}
+	if u.ParentIssueID != nil && *u.ParentIssueID != 0 && (*u.ParentIssueID == id || s.findIssue(*u.ParentIssueID) == nil) {
+		errs = append(errs, "Parent task is invalid")
+	}
+	if u.FixedVersionID != nil && *u.FixedVersionID != 0 && s.findVersion(*u.FixedVersionID) == nil {
+		errs = append(errs, "Target version is not included in the list")
+	}
+	errs = append(errs, s.validateCustomFields(u.CustomFields)...)
+	if len(errs) > 0 {
+		writeErrors(w, errs...)
+		return
+	}
+	files, errs := s.takeUploads(u.Uploads)
+	if len(errs) > 0 {
+		writeErrors(w, errs...)
+		return
+	}
+	is.attachments = append(is.attachments, files...)
+
+	details := attachmentDetails(files)
+	change := func(name, old, new string) {
+		if old != new {
+			details = append(details, redmine.Detail{Property: "attr", Name: name, OldValue: old, NewValue: new})
+		}
+	}
+	if u.Subject != nil {
+		change("subject", is.Subject, *u.Subject)
+		is.Subject = *u.Subject
+	}
+	if u.Description != nil {
+		change("description", is.Description, *u.Description)
+		is.Description = *u.Description
+	}
+	if u.StatusID != nil {
+		change("status_id", strconv.Itoa(is.Status.ID), strconv.Itoa(*u.StatusID))
+		status := s.findStatus(*u.StatusID)
+		// closed_on は閉じたときに記録され、再オープンしても残る
+		if old := s.findStatus(is.Status.ID); status.IsClosed && (old == nil || !old.IsClosed) {
+			closedOn := s.now()
+			is.ClosedOn = &closedOn
+		}
+		is.Status = redmine.Status{ID: status.ID, Name: status.Name}
+	}
+	if u.TrackerID != nil {
+		change("tracker_id", strconv.Itoa(is.Tracker.ID), strconv.Itoa(*u.TrackerID))
+		is.Tracker = *s.findTracker(*u.TrackerID)
+	}
+	if u.PriorityID != nil {
+		change("priority_id", strconv.Itoa(is.Priority.ID), strconv.Itoa(*u.PriorityID))
+		is.Priority = *s.findPriority(*u.PriorityID)
+	}
+	if u.AssignedToID != nil {
+		old := ""
+		if is.AssignedTo != nil {
+			old = strconv.Itoa(is.AssignedTo.ID)
+		}
+		is.AssignedTo = nil
+		new := ""
+		if assignee := s.userByID(*u.AssignedToID); assignee != nil {
+			is.AssignedTo = &redmine.User{ID: assignee.ID, Name: assignee.Name()}
+			new = strconv.Itoa(assignee.ID)
+		}
+		change("assigned_to_id", old, new)
+	}
+	if u.CategoryID != nil {
+		old := ""
+		if is.Category != nil {
+			old = strconv.Itoa(is.Category.ID)
+		}
+		is.Category = nil
+		new := ""
+		if c := s.findCategory(is.Project.ID, *u.CategoryID); c != nil {
+			is.Category = &idName{ID: c.ID, Name: c.Name}
+			new = strconv.Itoa(c.ID)
+		}
+		change("category_id", old, new)
+	}
+	if u.ParentIssueID != nil {
+		old := ""
+		if is.Parent != nil {
+			old = strconv.Itoa(is.Parent.ID)
+		}
+		is.Parent = nil
+		new := ""
+		if *u.ParentIssueID != 0 {
+			is.Parent = &redmine.IssueParent{ID: *u.ParentIssueID}
+			new = strconv.Itoa(*u.ParentIssueID)
+		}
+		change("parent_id", old, new)
+	}
+	if u.FixedVersionID != nil {
+		old := ""
+		if is.FixedVersion != nil {
+			old = strconv.Itoa(is.FixedVersion.ID)
+		}
+		is.FixedVersion = nil
+		new := ""
+		if v := s.findVersion(*u.FixedVersionID); v != nil {
+			is.FixedVersion = &idName{ID: v.ID, Name: v.Name}
+			new = strconv.Itoa(v.ID)
+		}
+		change("fixed_version_id", old, new)
+	}
+	if u.StartDate != nil {
+		old := ""
+		if is.StartDate != nil {
+			old = *is.StartDate
+		}
+		change("start_date", old, *u.StartDate)
+		is.StartDate = u.StartDate
+	}
+	if u.DueDate != nil {
+		old := ""
+		if is.DueDate != nil {
+			old = *is.DueDate
+		}
+		change("due_date", old, *u.DueDate)
+		is.DueDate = u.DueDate
+	}
+	if u.EstimatedHours != nil {
+		old := ""
+		if is.EstimatedHours != nil {
+			old = strconv.FormatFloat(*is.EstimatedHours, 'f', -1, 64)
+		}
+		change("estimated_hours", old, strconv.FormatFloat(*u.EstimatedHours, 'f', -1, 64))
+		is.EstimatedHours = u.EstimatedHours
+	}
+	if u.DoneRatio != nil {
+		change("done_ratio", strconv.Itoa(is.DoneRatio), strconv.Itoa(*u.DoneRatio))
+		is.DoneRatio = *u.DoneRatio
+	}
+	for _, v := range u.CustomFields {
+		if old, ok := s.setCustomField(is, v); ok && old != fmt.Sprint(v.Value) {
+			details = append(details, redmine.Detail{Property: "cf", Name: strconv.Itoa(v.ID), OldValue: old, NewValue: fmt.Sprint(v.Value)})
+		}
+	}
+
+	if u.Notes != "" || len(details) > 0 {
+		is.UpdatedOn = s.now()
+		is.Journals = append(is.Journals, redmine.Journal{
+			ID:        s.id("journal"),
+			User:      redmine.User{ID: user.ID, Name: user.Name()},
+			Notes:     u.Notes,
+			CreatedOn: is.UpdatedOn,
+			Details:   details,
+		})
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) deleteIssue(w http.ResponseWriter, r *http.Request, _ *User, params []string) {
+	id, _ := strconv.Atoi(params[0])
+	for i, is := range s.issues {
+		if is.ID == id {
+			s.issues = append(s.issues[:i], s.issues[i+1:]...)
+			// 関連も一緒に削除される
+			kept := s.relations[:0]
+			for _, rel := range s.relations {
+				if rel.IssueID != id && rel.IssueToID != id {
+					kept = append(kept, rel)
+				}
+			}
+			s.relations = kept
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNotFound)
+}
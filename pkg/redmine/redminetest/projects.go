@@ -0,0 +1,251 @@
+package redminetest
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+)
+
+type project struct {
+	redmine.ProjectDetail
+	Parent *redmine.Project `json:"parent,omitempty"`
+}
+
+// AddProject registers a public project with every tracker enabled.
+func (s *Server) AddProject(identifier, name string) redmine.ProjectDetail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := &project{ProjectDetail: redmine.ProjectDetail{
+		ID:         s.id("project"),
+		Name:       name,
+		Identifier: identifier,
+		Status:     1,
+		IsPublic:   true,
+		Trackers:   append([]redmine.Tracker{}, s.trackers...),
+	}}
+	s.projects = append(s.projects, p)
+	return p.ProjectDetail
+}
+
+// AddSubproject registers a project below the parent with the given identifier.
+func (s *Server) AddSubproject(parentIdentifier, identifier, name string) redmine.ProjectDetail {
+	detail := s.AddProject(identifier, name)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	parent := s.findProject(parentIdentifier)
+	if parent == nil {
+		panic("redminetest: unknown project " + parentIdentifier)
+	}
+	s.findProject(identifier).Parent = &redmine.Project{ID: parent.ID, Name: parent.Name}
+	return detail
+}
+
+// findProject accepts a numeric ID or an identifier.
+func (s *Server) findProject(idOrIdentifier string) *project {
+	id, _ := strconv.Atoi(idOrIdentifier)
+	for _, p := range s.projects {
+		if p.Identifier == idOrIdentifier || (id > 0 && p.ID == id) {
+			return p
+		}
+	}
+	return nil
+}
+
+func (s *Server) listProjects(w http.ResponseWriter, r *http.Request, _ *User, _ []string) {
+	offset, limit := paginate(r, len(s.projects))
+	page := []redmine.Project{}
+	for _, p := range s.projects[offset:pageEnd(offset, limit, len(s.projects))] {
+		page = append(page, redmine.Project{ID: p.ID, Name: p.Name})
+	}
+	writeJSON(w, http.StatusOK, redmine.ProjectsResponse{
+		Projects:   page,
+		TotalCount: len(s.projects),
+		Offset:     offset,
+		Limit:      limit,
+	})
+}
+
+func (s *Server) getProject(w http.ResponseWriter, r *http.Request, _ *User, params []string) {
+	p := s.findProject(params[0])
+	if p == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	detail := *p
+	// 管理者でなくてもプロジェクトで使えるカスタムフィールドは参照できる（すべて全プロジェクトで有効）。
+	// Redmine と同じく ID と名前だけを返す
+	if strings.Contains(r.URL.Query().Get("include"), "issue_custom_fields") {
+		detail.IssueCustomFields = []redmine.CustomFieldDefinition{}
+		for _, cf := range s.customFields {
+			detail.IssueCustomFields = append(detail.IssueCustomFields, redmine.CustomFieldDefinition{ID: cf.ID, Name: cf.Name})
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"project": detail})
+}
+
+func (s *Server) listVersions(w http.ResponseWriter, r *http.Request, _ *User, params []string) {
+	p := s.findProject(params[0])
+	if p == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	versions := append([]redmine.Version{}, s.versions[p.ID]...)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"versions":    versions,
+		"total_count": len(versions),
+	})
+}
+
+func (s *Server) listCategories(w http.ResponseWriter, r *http.Request, _ *User, params []string) {
+	p := s.findProject(params[0])
+	if p == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	categories := []redmine.IssueCategory{}
+	for _, c := range s.categories {
+		if c.Project.ID == p.ID {
+			categories = append(categories, c)
+		}
+	}
+	writeJSON(w, http.StatusOK, redmine.IssueCategoriesResponse{IssueCategories: categories, TotalCount: len(categories)})
+}
+
+// findCategory は projectID のカテゴリだけを探す
+func (s *Server) findCategory(projectID, id int) *redmine.IssueCategory {
+	for i := range s.categories {
+		if s.categories[i].ID == id && s.categories[i].Project.ID == projectID {
+			return &s.categories[i]
+		}
+	}
+	return nil
+}
+
+func (s *Server) findVersion(id int) *redmine.Version {
+	for _, versions := range s.versions {
+		for i := range versions {
+			if versions[i].ID == id {
+				return &versions[i]
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Server) listCustomFields(w http.ResponseWriter, r *http.Request, user *User, _ []string) {
+	// Redmine では管理者のみ参照できる
+	if !user.Admin {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	writeJSON(w, http.StatusOK, redmine.CustomFieldsResponse{CustomFields: append([]redmine.CustomFieldDefinition{}, s.customFields...)})
+}
+
+func (s *Server) listTrackers(w http.ResponseWriter, r *http.Request, _ *User, _ []string) {
+	writeJSON(w, http.StatusOK, redmine.TrackersResponse{Trackers: s.trackers})
+}
+
+func (s *Server) listStatuses(w http.ResponseWriter, r *http.Request, _ *User, _ []string) {
+	writeJSON(w, http.StatusOK, redmine.IssueStatusesResponse{IssueStatuses: s.statuses})
+}
+
+func (s *Server) listPriorities(w http.ResponseWriter, r *http.Request, _ *User, _ []string) {
+	priorities := []redmine.IssuePriority{}
+	for _, p := range s.priorities {
+		// 既定値は Normal (newIssue と同じ)
+		priorities = append(priorities, redmine.IssuePriority{ID: p.ID, Name: p.Name, IsDefault: p.ID == 2, Active: true})
+	}
+	writeJSON(w, http.StatusOK, redmine.IssuePrioritiesResponse{IssuePriorities: priorities})
+}
+
+func (s *Server) currentUser(w http.ResponseWriter, r *http.Request, user *User, _ []string) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"user": map[string]interface{}{
+			"id":         user.ID,
+			"login":      user.Login,
+			"admin":      user.Admin,
+			"firstname":  user.FirstName,
+			"lastname":   user.LastName,
+			"mail":       user.Login + "@example.com",
+			"created_on": s.now().Format(time.RFC3339),
+			"api_key":    user.APIKey,
+		},
+	})
+}
+
+func (s *Server) search(w http.ResponseWriter, r *http.Request, _ *User, _ []string) {
+	q := r.URL.Query()
+	words := strings.Fields(strings.ToLower(q.Get("q")))
+	if len(words) == 0 {
+		writeJSON(w, http.StatusOK, redmine.SearchResponse{Results: []redmine.SearchResult{}})
+		return
+	}
+	allWords := q.Get("all_words") != "" && q.Get("all_words") != "0"
+	titlesOnly := q.Get("titles_only") != "" && q.Get("titles_only") != "0"
+	// 種別の指定がなければ全種別を検索する
+	typed := q.Get("issues") != "" || q.Get("projects") != ""
+	match := func(texts ...string) bool {
+		text := strings.ToLower(strings.Join(texts, " "))
+		hits := 0
+		for _, w := range words {
+			if strings.Contains(text, w) {
+				hits++
+			}
+		}
+		if allWords {
+			return hits == len(words)
+		}
+		return hits > 0
+	}
+
+	results := []redmine.SearchResult{}
+	if !typed || q.Get("issues") != "" {
+		for i := len(s.issues) - 1; i >= 0; i-- {
+			is := s.issues[i]
+			texts := []string{is.Subject}
+			if !titlesOnly {
+				texts = append(texts, is.Description)
+			}
+			if !match(texts...) {
+				continue
+			}
+			results = append(results, redmine.SearchResult{
+				ID:          is.ID,
+				Title:       is.Tracker.Name + " #" + strconv.Itoa(is.ID) + " (" + is.Status.Name + "): " + is.Subject,
+				Type:        "issue",
+				URL:         s.URL + "/issues/" + strconv.Itoa(is.ID),
+				Description: is.Description,
+				Datetime:    is.CreatedOn.Format(time.RFC3339),
+			})
+		}
+	}
+	if !typed || q.Get("projects") != "" {
+		for _, p := range s.projects {
+			texts := []string{p.Name}
+			if !titlesOnly {
+				texts = append(texts, p.Description)
+			}
+			if !match(texts...) {
+				continue
+			}
+			results = append(results, redmine.SearchResult{
+				ID:          p.ID,
+				Title:       "Project: " + p.Name,
+				Type:        "project",
+				URL:         s.URL + "/projects/" + p.Identifier,
+				Description: p.Description,
+			})
+		}
+	}
+
+	offset, limit := paginate(r, len(results))
+	writeJSON(w, http.StatusOK, redmine.SearchResponse{
+		Results:    results[offset:pageEnd(offset, limit, len(results))],
+		TotalCount: len(results),
+		Offset:     offset,
+		Limit:      limit,
+	})
+}
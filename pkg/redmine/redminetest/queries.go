@@ -0,0 +1,75 @@
+package redminetest
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+)
+
+// savedQuery is a public issue query with the filters it applies, given as
+// /issues.json parameters.
+type savedQuery struct {
+	redmine.Query
+	filters url.Values
+}
+
+// AddQuery registers a public saved query. filters are /issues.json filter
+// parameters such as status_id or tracker_id. An empty projectIdentifier
+// makes the query available in every project.
+func (s *Server) AddQuery(projectIdentifier, name string, filters url.Values) redmine.Query {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q := &savedQuery{Query: redmine.Query{ID: s.id("query"), Name: name, IsPublic: true}, filters: filters}
+	if projectIdentifier != "" {
+		p := s.findProject(projectIdentifier)
+		if p == nil {
+			panic("redminetest: unknown project " + projectIdentifier)
+		}
+		q.ProjectID = &p.ID
+	}
+	s.queries = append(s.queries, q)
+	return q.Query
+}
+
+func (s *Server) listQueries(w http.ResponseWriter, r *http.Request, _ *User, _ []string) {
+	queries := []redmine.Query{}
+	for _, q := range s.queries {
+		queries = append(queries, q.Query)
+	}
+	offset, limit := paginate(r, len(queries))
+	writeJSON(w, http.StatusOK, redmine.QueriesResponse{
+		Queries:    queries[offset:pageEnd(offset, limit, len(queries))],
+		TotalCount: len(queries),
+		Offset:     offset,
+		Limit:      limit,
+	})
+}
+
+// applyQuery replaces the filters of an /issues.json request with those of
+// the saved query query_id, as Redmine does. It returns false for an
+// unknown query.
+func (s *Server) applyQuery(q url.Values) (url.Values, bool) {
+	id, _ := strconv.Atoi(q.Get("query_id"))
+	for _, saved := range s.queries {
+		if saved.ID != id {
+			continue
+		}
+		applied := url.Values{}
+		for key, values := range saved.filters {
+			applied[key] = values
+		}
+		// ページングと指定されたプロジェクトはリクエストのものを使う
+		for _, key := range []string{"offset", "limit", "project_id"} {
+			if v := q.Get(key); v != "" {
+				applied.Set(key, v)
+			}
+		}
+		if applied.Get("project_id") == "" && saved.ProjectID != nil {
+			applied.Set("project_id", strconv.Itoa(*saved.ProjectID))
+		}
+		return applied, true
+	}
+	return nil, false
+}
@@ -0,0 +1,118 @@
+package redminetest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+)
+
+// Redmine は逆向きの種類を、チケットを入れ替えた正方向の種類として保存する
+var reverseRelations = map[string]string{
+	redmine.RelationDuplicated: redmine.RelationDuplicates,
+	redmine.RelationBlocked:    redmine.RelationBlocks,
+	redmine.RelationFollows:    redmine.RelationPrecedes,
+	redmine.RelationCopiedFrom: redmine.RelationCopiedTo,
+}
+
+// issueRelations returns the relations involving issueID.
+func (s *Server) issueRelations(issueID int) []redmine.Relation {
+	out := []redmine.Relation{}
+	for _, rel := range s.relations {
+		if rel.IssueID == issueID || rel.IssueToID == issueID {
+			out = append(out, rel)
+		}
+	}
+	return out
+}
+
+func (s *Server) listRelations(w http.ResponseWriter, r *http.Request, _ *User, params []string) {
+	id, _ := strconv.Atoi(params[0])
+	if s.findIssue(id) == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, redmine.RelationsResponse{Relations: s.issueRelations(id)})
+}
+
+func (s *Server) createRelation(w http.ResponseWriter, r *http.Request, _ *User, params []string) {
+	id, _ := strconv.Atoi(params[0])
+	if s.findIssue(id) == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	var req struct {
+		Relation redmine.RelationCreate `json:"relation"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	c := req.Relation
+
+	rel := redmine.Relation{IssueID: id, IssueToID: c.IssueToID, RelationType: c.RelationType}
+	if forward, ok := reverseRelations[rel.RelationType]; ok {
+		rel.IssueID, rel.IssueToID, rel.RelationType = rel.IssueToID, rel.IssueID, forward
+	}
+
+	var errs []string
+	if !redmine.IsRelationType(c.RelationType) {
+		errs = append(errs, "Relation type is not included in the list")
+	}
+	if s.findIssue(c.IssueToID) == nil || c.IssueToID == id {
+		errs = append(errs, "Related issue is invalid")
+	}
+	for _, existing := range s.relations {
+		if existing.IssueID == rel.IssueID && existing.IssueToID == rel.IssueToID ||
+			existing.IssueID == rel.IssueToID && existing.IssueToID == rel.IssueID {
+			errs = append(errs, "Related issue has already been taken")
+			break
+		}
+	}
+	if len(errs) > 0 {
+		writeErrors(w, errs...)
+		return
+	}
+	if rel.RelationType == redmine.RelationPrecedes {
+		delay := 0
+		if c.Delay != nil {
+			delay = *c.Delay
+		}
+		rel.Delay = &delay
+	}
+
+	rel.ID = s.id("relation")
+	s.relations = append(s.relations, rel)
+	writeJSON(w, http.StatusCreated, redmine.RelationResponse{Relation: rel})
+}
+
+func (s *Server) findRelation(id int) int {
+	for i, rel := range s.relations {
+		if rel.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Server) getRelation(w http.ResponseWriter, r *http.Request, _ *User, params []string) {
+	id, _ := strconv.Atoi(params[0])
+	i := s.findRelation(id)
+	if i < 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, redmine.RelationResponse{Relation: s.relations[i]})
+}
+
+func (s *Server) deleteRelation(w http.ResponseWriter, r *http.Request, _ *User, params []string) {
+	id, _ := strconv.Atoi(params[0])
+	i := s.findRelation(id)
+	if i < 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	s.relations = append(s.relations[:i], s.relations[i+1:]...)
+	w.WriteHeader(http.StatusNoContent)
+}
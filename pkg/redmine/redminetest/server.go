@@ -0,0 +1,373 @@
+// Package redminetest provides an in-memory fake Redmine server for tests.
+//
+// The server implements the REST endpoints used by package redmine with
+// realistic JSON shapes: issues (with pagination, journals and filters),
+// projects, memberships, versions, issue categories, custom fields, trackers,
+// statuses, priorities, users, search, relations, watchers, uploads,
+// attachment downloads, time entries, saved queries and wiki pages (with
+// versions and 409 conflicts).
+// It enforces API keys (401), admin-only endpoints (403), missing resources
+// (404) and validation errors (422).
+//
+//	srv := redminetest.NewServer()
+//	defer srv.Close()
+//	project := srv.AddProject("demo", "Demo")
+//	client := srv.Client()
+//	issue, err := client.CreateIssue(&redmine.IssueCreate{ProjectID: project.ID, Subject: "Hello"})
+package redminetest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+)
+
+// DefaultAPIKey is the API key of the admin user created by NewServer.
+const DefaultAPIKey = "redminetest-admin-key"
+
+// User is a Redmine account known to the fake server.
+type User struct {
+	ID        int
+	Login     string
+	Password  string
+	FirstName string
+	LastName  string
+	APIKey    string
+	Admin     bool
+	Locked    bool
+}
+
+// Name returns the display name used in issue JSON.
+func (u *User) Name() string {
+	return strings.TrimSpace(u.FirstName + " " + u.LastName)
+}
+
+// Server is an in-memory fake Redmine.
+type Server struct {
+	*httptest.Server
+
+	// APIKey is the key of the default admin user.
+	APIKey string
+	// Admin is the default admin user.
+	Admin *User
+
+	mu           sync.Mutex
+	now          func() time.Time
+	nextID       map[string]int
+	users        []*User
+	projects     []*project
+	issues       []*issue
+	customFields []redmine.CustomFieldDefinition
+	trackers     []redmine.Tracker
+	statuses     []redmine.IssueStatus
+	priorities   []redmine.Priority
+	categories   []redmine.IssueCategory
+	versions     map[int][]redmine.Version
+	uploads      map[string]*Attachment
+	activities   []redmine.TimeEntryActivity
+	timeEntries  []redmine.TimeEntry
+	relations    []redmine.Relation
+	members      map[int][]int
+	wiki         []*wikiPage
+	queries      []*savedQuery
+}
+
+// NewServer starts a fake Redmine with one admin user, the default
+// trackers, statuses, priorities and time entry activities, and no projects.
+func NewServer() *Server {
+	s := &Server{
+		APIKey:   DefaultAPIKey,
+		now:      func() time.Time { return time.Now().UTC().Truncate(time.Second) },
+		nextID:   map[string]int{},
+		versions: map[int][]redmine.Version{},
+		uploads:  map[string]*Attachment{},
+		members:  map[int][]int{},
+		trackers: []redmine.Tracker{
+			{ID: 1, Name: "Bug"},
+			{ID: 2, Name: "Feature"},
+			{ID: 3, Name: "Support"},
+		},
+		statuses: []redmine.IssueStatus{
+			{ID: 1, Name: "New"},
+			{ID: 2, Name: "In Progress"},
+			{ID: 3, Name: "Resolved"},
+			{ID: 4, Name: "Feedback"},
+			{ID: 5, Name: "Closed", IsClosed: true},
+			{ID: 6, Name: "Rejected", IsClosed: true},
+		},
+		priorities: []redmine.Priority{
+			{ID: 1, Name: "Low"},
+			{ID: 2, Name: "Normal"},
+			{ID: 3, Name: "High"},
+			{ID: 4, Name: "Urgent"},
+			{ID: 5, Name: "Immediate"},
+		},
+		activities: []redmine.TimeEntryActivity{
+			{ID: 8, Name: "Design", Active: true},
+			{ID: 9, Name: "Development", IsDefault: true, Active: true},
+			{ID: 10, Name: "Testing", Active: true},
+		},
+	}
+	s.Admin = s.AddUser(User{Login: "admin", Password: "admin", FirstName: "Redmine", LastName: "Admin", APIKey: DefaultAPIKey, Admin: true})
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns a redmine.Client authenticated as the admin user, with
+// retries and caching disabled.
+func (s *Server) Client() *redmine.Client {
+	return s.ClientFor(s.Admin)
+}
+
+// ClientFor returns a client authenticated with u's API key.
+func (s *Server) ClientFor(u *User) *redmine.Client {
+	c := redmine.NewClient(s.URL, u.APIKey)
+	c.Retry.MaxRetries = 0
+	return c
+}
+
+func (s *Server) id(kind string) int {
+	s.nextID[kind]++
+	return s.nextID[kind]
+}
+
+// AddUser registers a user. A missing API key is generated.
+func (s *Server) AddUser(u User) *User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u.ID = s.id("user")
+	if u.APIKey == "" {
+		u.APIKey = "key-" + u.Login
+	}
+	user := &u
+	s.users = append(s.users, user)
+	return user
+}
+
+// AddCustomField registers an issue custom field.
+func (s *Server) AddCustomField(name string) redmine.CustomFieldDefinition {
+	return s.AddCustomFieldDefinition(redmine.CustomFieldDefinition{Name: name})
+}
+
+// AddCustomFieldDefinition registers an issue custom field with a format,
+// e.g. a multi-value list. The ID is assigned by the server.
+func (s *Server) AddCustomFieldDefinition(cf redmine.CustomFieldDefinition) redmine.CustomFieldDefinition {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cf.ID = s.id("custom_field")
+	cf.CustomizedType = "issue"
+	if cf.FieldFormat == "" {
+		cf.FieldFormat = "string"
+	}
+	s.customFields = append(s.customFields, cf)
+	return cf
+}
+
+// AddVersion registers a version in the project with the given identifier.
+func (s *Server) AddVersion(projectIdentifier, name string) redmine.Version {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.findProject(projectIdentifier)
+	if p == nil {
+		panic("redminetest: unknown project " + projectIdentifier)
+	}
+	now := s.now().Format(time.RFC3339)
+	v := redmine.Version{ID: s.id("version"), Name: name, Status: "open", CreatedOn: now, UpdatedOn: now}
+	s.versions[p.ID] = append(s.versions[p.ID], v)
+	return v
+}
+
+// AddCategory registers an issue category in the project with the given identifier.
+func (s *Server) AddCategory(projectIdentifier, name string) redmine.IssueCategory {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.findProject(projectIdentifier)
+	if p == nil {
+		panic("redminetest: unknown project " + projectIdentifier)
+	}
+	c := redmine.IssueCategory{ID: s.id("category"), Name: name, Project: redmine.Project{ID: p.ID, Name: p.Name}}
+	s.categories = append(s.categories, c)
+	return c
+}
+
+func (s *Server) userByKey(key string) *User {
+	for _, u := range s.users {
+		if u.APIKey == key {
+			return u
+		}
+	}
+	return nil
+}
+
+func (s *Server) userByLogin(login string) *User {
+	for _, u := range s.users {
+		if u.Login == login {
+			return u
+		}
+	}
+	return nil
+}
+
+func (s *Server) userByID(id int) *User {
+	for _, u := range s.users {
+		if u.ID == id {
+			return u
+		}
+	}
+	return nil
+}
+
+// authenticate resolves the acting user from the API key header, the key
+// query parameter or basic auth, honoring X-Redmine-Switch-User for admins.
+func (s *Server) authenticate(w http.ResponseWriter, r *http.Request) (*User, bool) {
+	var user *User
+	if key := r.Header.Get("X-Redmine-API-Key"); key != "" {
+		user = s.userByKey(key)
+	} else if key := r.URL.Query().Get("key"); key != "" {
+		user = s.userByKey(key)
+	} else if login, password, ok := r.BasicAuth(); ok {
+		if u := s.userByLogin(login); u != nil && u.Password == password {
+			user = u
+		} else {
+			// Redmine はAPIキーをBasic認証のユーザー名として受け付ける
+			user = s.userByKey(login)
+		}
+	}
+	if user == nil || user.Locked {
+		w.Header().Set("WWW-Authenticate", `Basic realm="Redmine API"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		return nil, false
+	}
+
+	if login := r.Header.Get("X-Redmine-Switch-User"); login != "" && user.Admin {
+		switched := s.userByLogin(login)
+		if switched == nil || switched.Locked {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return nil, false
+		}
+		user = switched
+	}
+	return user, true
+}
+
+type route struct {
+	method  string
+	pattern *regexp.Regexp
+	handler func(w http.ResponseWriter, r *http.Request, user *User, params []string)
+}
+
+func (s *Server) routes() []route {
+	return []route{
+		{"GET", regexp.MustCompile(`^/issues\.json$`), s.listIssues},
+		{"POST", regexp.MustCompile(`^/issues\.json$`), s.createIssue},
+		{"GET", regexp.MustCompile(`^/issues/(\d+)\.json$`), s.getIssue},
+		{"PUT", regexp.MustCompile(`^/issues/(\d+)\.json$`), s.updateIssue},
+		{"DELETE", regexp.MustCompile(`^/issues/(\d+)\.json$`), s.deleteIssue},
+		{"GET", regexp.MustCompile(`^/issues/(\d+)/relations\.json$`), s.listRelations},
+		{"POST", regexp.MustCompile(`^/issues/(\d+)/relations\.json$`), s.createRelation},
+		{"GET", regexp.MustCompile(`^/relations/(\d+)\.json$`), s.getRelation},
+		{"DELETE", regexp.MustCompile(`^/relations/(\d+)\.json$`), s.deleteRelation},
+		{"POST", regexp.MustCompile(`^/issues/(\d+)/watchers\.json$`), s.addWatcher},
+		{"DELETE", regexp.MustCompile(`^/issues/(\d+)/watchers/(\d+)\.json$`), s.removeWatcher},
+		{"GET", regexp.MustCompile(`^/projects\.json$`), s.listProjects},
+		{"GET", regexp.MustCompile(`^/projects/([^/]+)\.json$`), s.getProject},
+		{"GET", regexp.MustCompile(`^/projects/([^/]+)/versions\.json$`), s.listVersions},
+		{"GET", regexp.MustCompile(`^/projects/([^/]+)/memberships\.json$`), s.listMemberships},
+		{"GET", regexp.MustCompile(`^/projects/([^/]+)/issue_categories\.json$`), s.listCategories},
+		{"GET", regexp.MustCompile(`^/projects/([^/]+)/wiki/index\.json$`), s.listWikiPages},
+		{"GET", regexp.MustCompile(`^/projects/([^/]+)/wiki/([^/]+)\.json$`), s.getWikiPage},
+		{"GET", regexp.MustCompile(`^/projects/([^/]+)/wiki/([^/]+)/(\d+)\.json$`), s.getWikiPage},
+		{"PUT", regexp.MustCompile(`^/projects/([^/]+)/wiki/([^/]+)\.json$`), s.putWikiPage},
+		{"DELETE", regexp.MustCompile(`^/projects/([^/]+)/wiki/([^/]+)\.json$`), s.deleteWikiPage},
+		{"GET", regexp.MustCompile(`^/users\.json$`), s.listUsers},
+		{"GET", regexp.MustCompile(`^/custom_fields\.json$`), s.listCustomFields},
+		{"GET", regexp.MustCompile(`^/trackers\.json$`), s.listTrackers},
+		{"GET", regexp.MustCompile(`^/issue_statuses\.json$`), s.listStatuses},
+		{"GET", regexp.MustCompile(`^/users/current\.json$`), s.currentUser},
+		{"GET", regexp.MustCompile(`^/search\.json$`), s.search},
+		{"GET", regexp.MustCompile(`^/queries\.json$`), s.listQueries},
+		{"POST", regexp.MustCompile(`^/uploads\.json$`), s.createUpload},
+		{"GET", regexp.MustCompile(`^/attachments/(\d+)\.json$`), s.getAttachment},
+		{"GET", regexp.MustCompile(`^/attachments/download/(\d+)/[^/]*$`), s.downloadAttachment},
+		{"GET", regexp.MustCompile(`^/enumerations/time_entry_activities\.json$`), s.listActivities},
+		{"GET", regexp.MustCompile(`^/enumerations/issue_priorities\.json$`), s.listPriorities},
+		{"GET", regexp.MustCompile(`^/time_entries\.json$`), s.listTimeEntries},
+		{"POST", regexp.MustCompile(`^/time_entries\.json$`), s.createTimeEntry},
+		{"GET", regexp.MustCompile(`^/time_entries/(\d+)\.json$`), s.getTimeEntry},
+		{"PUT", regexp.MustCompile(`^/time_entries/(\d+)\.json$`), s.updateTimeEntry},
+		{"DELETE", regexp.MustCompile(`^/time_entries/(\d+)\.json$`), s.deleteTimeEntry},
+	}
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	methodMismatch := false
+	for _, rt := range s.routes() {
+		m := rt.pattern.FindStringSubmatch(r.URL.Path)
+		if m == nil {
+			continue
+		}
+		if rt.method != r.Method {
+			methodMismatch = true
+			continue
+		}
+		rt.handler(w, r, user, m[1:])
+		return
+	}
+	if methodMismatch {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	w.WriteHeader(http.StatusNotFound)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeErrors(w http.ResponseWriter, messages ...string) {
+	writeJSON(w, http.StatusUnprocessableEntity, map[string][]string{"errors": messages})
+}
+
+// paginate applies Redmine's offset/limit parameters (default 25, max 100).
+func paginate(r *http.Request, total int) (offset, limit int) {
+	q := r.URL.Query()
+	offset, _ = strconv.Atoi(q.Get("offset"))
+	limit, err := strconv.Atoi(q.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 25
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > total {
+		offset = total
+	}
+	return offset, limit
+}
+
+func pageEnd(offset, limit, total int) int {
+	if offset+limit > total {
+		return total
+	}
+	return offset + limit
+}
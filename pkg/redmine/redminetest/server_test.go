@@ -0,0 +1,185 @@
+package redminetest_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+	"github.com/ikasamt/rd/pkg/redmine/redminetest"
+)
+
+func TestIssueLifecycle(t *testing.T) {
+	srv := redminetest.NewServer()
+	defer srv.Close()
+	project := srv.AddProject("demo", "Demo")
+	client := srv.Client()
+
+	created, err := client.CreateIssue(&redmine.IssueCreate{ProjectID: project.ID, Subject: "Crash on save"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Status.Name != "New" || created.Author.ID != srv.Admin.ID {
+		t.Errorf("created issue status=%q author=%d", created.Status.Name, created.Author.ID)
+	}
+
+	status := 2
+	if err := client.UpdateIssue(created.ID, &redmine.IssueUpdate{StatusID: &status, Notes: "Looking into it"}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := client.GetIssue(created.ID, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status.Name != "In Progress" {
+		t.Errorf("status = %q, want In Progress", got.Status.Name)
+	}
+	if len(got.Journals) != 1 {
+		t.Fatalf("journals = %+v, want one", got.Journals)
+	}
+	j := got.Journals[0]
+	if j.Notes != "Looking into it" || j.User.ID != srv.Admin.ID {
+		t.Errorf("journal = %+v", j)
+	}
+	want := redmine.Detail{Property: "attr", Name: "status_id", OldValue: "1", NewValue: "2"}
+	if len(j.Details) != 1 || j.Details[0] != want {
+		t.Errorf("details = %+v, want %+v", j.Details, want)
+	}
+
+	// journals は include=journals のときだけ返る
+	if plain, err := client.GetIssue(created.ID, false); err != nil || len(plain.Journals) != 0 {
+		t.Errorf("without journals: %+v, %v", plain.Journals, err)
+	}
+}
+
+func TestListIssuesPagination(t *testing.T) {
+	srv := redminetest.NewServer()
+	defer srv.Close()
+	project := srv.AddProject("demo", "Demo")
+	for i := 0; i < 5; i++ {
+		srv.AddIssue(redmine.IssueCreate{ProjectID: project.ID, Subject: "Issue"})
+	}
+
+	tests := []struct {
+		offset, limit int
+		wantIDs       []int
+	}{
+		{0, 2, []int{5, 4}},
+		{2, 2, []int{3, 2}},
+		{4, 2, []int{1}},
+		{5, 2, nil},
+	}
+	for _, tt := range tests {
+		resp, err := srv.Client().ListIssues(&redmine.IssueFilter{ProjectID: "demo", Offset: tt.offset, Limit: tt.limit})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []int
+		for _, is := range resp.Issues {
+			ids = append(ids, is.ID)
+		}
+		if !equalInts(ids, tt.wantIDs) || resp.TotalCount != 5 || resp.Offset != tt.offset || resp.Limit != tt.limit {
+			t.Errorf("offset=%d limit=%d: ids=%v total_count=%d offset=%d limit=%d",
+				tt.offset, tt.limit, ids, resp.TotalCount, resp.Offset, resp.Limit)
+		}
+	}
+}
+
+func TestErrorStatuses(t *testing.T) {
+	srv := redminetest.NewServer()
+	defer srv.Close()
+	project := srv.AddProject("demo", "Demo")
+	bob := srv.AddUser(redminetest.User{Login: "bob", FirstName: "Bob", APIKey: "bob-key"})
+
+	tests := []struct {
+		name   string
+		call   func() error
+		want   error
+		status int
+	}{
+		{"unknown key", func() error {
+			c := redmine.NewClient(srv.URL, "wrong")
+			c.Retry.MaxRetries = 0
+			_, err := c.GetCurrentUser()
+			return err
+		}, redmine.ErrUnauthorized, 401},
+		{"admin-only endpoint", func() error {
+			_, err := srv.ClientFor(bob).ListCustomFields()
+			return err
+		}, redmine.ErrForbidden, 403},
+		{"missing issue", func() error {
+			_, err := srv.Client().GetIssue(999, false)
+			return err
+		}, redmine.ErrNotFound, 404},
+		{"blank subject", func() error {
+			_, err := srv.Client().CreateIssue(&redmine.IssueCreate{ProjectID: project.ID})
+			return err
+		}, redmine.ErrValidation, 422},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			var apiErr *redmine.APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.status {
+				t.Errorf("err = %#v, want status %d", err, tt.status)
+			}
+		})
+	}
+
+	_, err := srv.Client().CreateIssue(&redmine.IssueCreate{ProjectID: project.ID})
+	var apiErr *redmine.APIError
+	if errors.As(err, &apiErr) && (len(apiErr.Errors) != 1 || apiErr.Errors[0] != "Subject cannot be blank") {
+		t.Errorf("errors = %q", apiErr.Errors)
+	}
+}
+
+func TestSwitchUser(t *testing.T) {
+	srv := redminetest.NewServer()
+	defer srv.Close()
+	project := srv.AddProject("demo", "Demo")
+	alice := srv.AddUser(redminetest.User{Login: "alice", FirstName: "Alice", APIKey: "alice-key"})
+	bob := srv.AddUser(redminetest.User{Login: "bob", FirstName: "Bob", APIKey: "bob-key"})
+
+	// 管理者キーなら切り替えたユーザーとして作成される
+	admin := srv.Client()
+	admin.SwitchUser = "alice"
+	issue, err := admin.CreateIssue(&redmine.IssueCreate{ProjectID: project.ID, Subject: "As alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issue.Author.ID != alice.ID {
+		t.Errorf("author = %d, want alice (%d)", issue.Author.ID, alice.ID)
+	}
+
+	// 存在しないユーザーは 412
+	admin.SwitchUser = "nobody"
+	if _, err := admin.GetCurrentUser(); !errors.Is(err, redmine.ErrSwitchUserNotFound) {
+		t.Errorf("unknown user: err = %v, want ErrSwitchUserNotFound", err)
+	}
+
+	// 管理者以外のキーではヘッダーは無視される
+	nonAdmin := srv.ClientFor(bob)
+	nonAdmin.SwitchUser = "alice"
+	me, err := nonAdmin.GetCurrentUser()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if me.ID != bob.ID {
+		t.Errorf("current user = %d, want bob (%d)", me.ID, bob.ID)
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,230 @@
+package redminetest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+)
+
+// TimeEntries returns copies of every logged time entry, oldest first.
+func (s *Server) TimeEntries() []redmine.TimeEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]redmine.TimeEntry(nil), s.timeEntries...)
+}
+
+func (s *Server) listActivities(w http.ResponseWriter, r *http.Request, _ *User, _ []string) {
+	writeJSON(w, http.StatusOK, redmine.TimeEntryActivitiesResponse{TimeEntryActivities: s.activities})
+}
+
+func (s *Server) findActivity(id int) *redmine.TimeEntryActivity {
+	for i, a := range s.activities {
+		if a.ID == id || (id == 0 && a.IsDefault) {
+			return &s.activities[i]
+		}
+	}
+	return nil
+}
+
+func (s *Server) findTimeEntry(id int) int {
+	for i, e := range s.timeEntries {
+		if e.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Server) listTimeEntries(w http.ResponseWriter, r *http.Request, user *User, _ []string) {
+	q := r.URL.Query()
+
+	var projectID int
+	if v := q.Get("project_id"); v != "" {
+		p := s.findProject(v)
+		if p == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		projectID = p.ID
+	}
+
+	matched := []redmine.TimeEntry{}
+	// 新しく登録したものから返す
+	for i := len(s.timeEntries) - 1; i >= 0; i-- {
+		e := s.timeEntries[i]
+		if projectID != 0 && e.Project.ID != projectID {
+			continue
+		}
+		if v := q.Get("user_id"); v != "" {
+			if v == "me" {
+				if e.User.ID != user.ID {
+					continue
+				}
+			} else if !containsID(v, e.User.ID) {
+				continue
+			}
+		}
+		if v := q.Get("issue_id"); v != "" && (e.Issue == nil || !containsID(v, e.Issue.ID)) {
+			continue
+		}
+		if v := q.Get("activity_id"); v != "" && !containsID(v, e.Activity.ID) {
+			continue
+		}
+		// 日付は YYYY-MM-DD なので文字列比較でよい
+		if v := q.Get("from"); v != "" && e.SpentOn < v {
+			continue
+		}
+		if v := q.Get("to"); v != "" && e.SpentOn > v {
+			continue
+		}
+		matched = append(matched, e)
+	}
+
+	offset, limit := paginate(r, len(matched))
+	writeJSON(w, http.StatusOK, redmine.TimeEntriesResponse{
+		TimeEntries: matched[offset:pageEnd(offset, limit, len(matched))],
+		TotalCount:  len(matched),
+		Offset:      offset,
+		Limit:       limit,
+	})
+}
+
+func (s *Server) getTimeEntry(w http.ResponseWriter, r *http.Request, _ *User, params []string) {
+	id, _ := strconv.Atoi(params[0])
+	i := s.findTimeEntry(id)
+	if i < 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, redmine.TimeEntryResponse{TimeEntry: s.timeEntries[i]})
+}
+
+func (s *Server) createTimeEntry(w http.ResponseWriter, r *http.Request, user *User, _ []string) {
+	var req struct {
+		TimeEntry redmine.TimeEntryCreate `json:"time_entry"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	c := req.TimeEntry
+
+	now := s.now()
+	e := redmine.TimeEntry{Hours: c.Hours, Comments: c.Comments, SpentOn: c.SpentOn, CreatedOn: now, UpdatedOn: now}
+	if e.SpentOn == "" {
+		e.SpentOn = now.Format("2006-01-02")
+	}
+
+	var errs []string
+	if c.IssueID > 0 {
+		is := s.findIssue(c.IssueID)
+		if is == nil {
+			errs = append(errs, "Issue is invalid")
+		} else {
+			e.Issue = &redmine.IssueParent{ID: is.ID}
+			e.Project = is.Project
+		}
+	} else if p := s.findProject(c.ProjectID); p != nil {
+		e.Project = redmine.Project{ID: p.ID, Name: p.Name}
+	} else {
+		errs = append(errs, "Project cannot be blank")
+	}
+	if c.Hours <= 0 {
+		errs = append(errs, "Hours is invalid")
+	}
+	if _, err := time.Parse("2006-01-02", e.SpentOn); err != nil {
+		errs = append(errs, "Date is invalid")
+	}
+	if a := s.findActivity(c.ActivityID); a == nil {
+		errs = append(errs, "Activity cannot be blank")
+	} else {
+		e.Activity = redmine.Activity{ID: a.ID, Name: a.Name}
+	}
+	author := user
+	if c.UserID > 0 {
+		if author = s.userByID(c.UserID); author == nil {
+			errs = append(errs, "User is invalid")
+		}
+	}
+	if len(errs) > 0 {
+		writeErrors(w, errs...)
+		return
+	}
+	e.User = redmine.User{ID: author.ID, Name: author.Name()}
+
+	e.ID = s.id("time_entry")
+	s.timeEntries = append(s.timeEntries, e)
+	writeJSON(w, http.StatusCreated, redmine.TimeEntryResponse{TimeEntry: e})
+}
+
+func (s *Server) updateTimeEntry(w http.ResponseWriter, r *http.Request, _ *User, params []string) {
+	id, _ := strconv.Atoi(params[0])
+	i := s.findTimeEntry(id)
+	if i < 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	var req struct {
+		TimeEntry redmine.TimeEntryUpdate `json:"time_entry"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	u := req.TimeEntry
+
+	// 先に全項目を検証し、エラーがあれば何も変更しない
+	e := s.timeEntries[i]
+	var errs []string
+	if u.Hours != nil {
+		if *u.Hours <= 0 {
+			errs = append(errs, "Hours is invalid")
+		}
+		e.Hours = *u.Hours
+	}
+	if u.SpentOn != nil {
+		if _, err := time.Parse("2006-01-02", *u.SpentOn); err != nil {
+			errs = append(errs, "Date is invalid")
+		}
+		e.SpentOn = *u.SpentOn
+	}
+	if u.ActivityID != nil {
+		if a := s.findActivity(*u.ActivityID); a == nil || *u.ActivityID == 0 {
+			errs = append(errs, "Activity cannot be blank")
+		} else {
+			e.Activity = redmine.Activity{ID: a.ID, Name: a.Name}
+		}
+	}
+	if u.IssueID != nil {
+		if is := s.findIssue(*u.IssueID); is == nil {
+			errs = append(errs, "Issue is invalid")
+		} else {
+			e.Issue = &redmine.IssueParent{ID: is.ID}
+			e.Project = is.Project
+		}
+	}
+	if u.Comments != nil {
+		e.Comments = *u.Comments
+	}
+	if len(errs) > 0 {
+		writeErrors(w, errs...)
+		return
+	}
+	e.UpdatedOn = s.now()
+	s.timeEntries[i] = e
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) deleteTimeEntry(w http.ResponseWriter, r *http.Request, _ *User, params []string) {
+	id, _ := strconv.Atoi(params[0])
+	i := s.findTimeEntry(id)
+	if i < 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	s.timeEntries = append(s.timeEntries[:i], s.timeEntries[i+1:]...)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,158 @@
+package redminetest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+)
+
+// Attachment is a file attached to an issue of the fake server.
+type Attachment struct {
+	ID          int
+	Filename    string
+	Description string
+	ContentType string
+	Content     []byte
+	AuthorID    int
+	CreatedOn   time.Time
+}
+
+// Attachments returns copies of the files attached to an issue.
+func (s *Server) Attachments(issueID int) []Attachment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	is := s.findIssue(issueID)
+	if is == nil {
+		return nil
+	}
+	out := make([]Attachment, 0, len(is.attachments))
+	for _, a := range is.attachments {
+		out = append(out, *a)
+	}
+	return out
+}
+
+func (s *Server) createUpload(w http.ResponseWriter, r *http.Request, user *User, _ []string) {
+	// Redmine は application/octet-stream 以外を 406 で拒否する
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/octet-stream" {
+		w.WriteHeader(http.StatusNotAcceptable)
+		return
+	}
+	content, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	a := &Attachment{
+		ID:        s.id("attachment"),
+		Filename:  r.URL.Query().Get("filename"),
+		Content:   content,
+		AuthorID:  user.ID,
+		CreatedOn: s.now(),
+	}
+	token := fmt.Sprintf("%d.%x", a.ID, sha256.Sum256(content))
+	s.uploads[token] = a
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"upload": map[string]interface{}{"id": a.ID, "token": token},
+	})
+}
+
+// takeUploads validates upload tokens and claims the pending files. Nothing
+// is claimed unless every token is valid.
+func (s *Server) takeUploads(uploads []redmine.Upload) ([]*Attachment, []string) {
+	for _, u := range uploads {
+		if _, ok := s.uploads[u.Token]; !ok {
+			return nil, []string{"Attachments is invalid"}
+		}
+	}
+	var files []*Attachment
+	for _, u := range uploads {
+		a := s.uploads[u.Token]
+		delete(s.uploads, u.Token)
+		if u.Filename != "" {
+			a.Filename = u.Filename
+		}
+		a.Description = u.Description
+		a.ContentType = u.ContentType
+		files = append(files, a)
+	}
+	return files, nil
+}
+
+// attachmentJSON renders a as Redmine's attachment JSON.
+func (s *Server) attachmentJSON(a *Attachment) redmine.Attachment {
+	out := redmine.Attachment{
+		ID:          a.ID,
+		Filename:    a.Filename,
+		Filesize:    int64(len(a.Content)),
+		ContentType: a.ContentType,
+		Description: a.Description,
+		ContentURL:  fmt.Sprintf("%s/attachments/download/%d/%s", s.URL, a.ID, url.PathEscape(a.Filename)),
+		Digest:      fmt.Sprintf("%x", sha256.Sum256(a.Content)),
+		CreatedOn:   a.CreatedOn,
+	}
+	if author := s.userByID(a.AuthorID); author != nil {
+		out.Author = redmine.User{ID: author.ID, Name: author.Name()}
+	}
+	return out
+}
+
+func (s *Server) findAttachment(id int) *Attachment {
+	for _, is := range s.issues {
+		for _, a := range is.attachments {
+			if a.ID == id {
+				return a
+			}
+		}
+	}
+	for _, page := range s.wiki {
+		for _, a := range page.attachments {
+			if a.ID == id {
+				return a
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Server) getAttachment(w http.ResponseWriter, r *http.Request, _ *User, params []string) {
+	id, _ := strconv.Atoi(params[0])
+	a := s.findAttachment(id)
+	if a == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, redmine.AttachmentResponse{Attachment: s.attachmentJSON(a)})
+}
+
+// downloadAttachment serves the content with Range support, like Redmine.
+func (s *Server) downloadAttachment(w http.ResponseWriter, r *http.Request, _ *User, params []string) {
+	id, _ := strconv.Atoi(params[0])
+	a := s.findAttachment(id)
+	if a == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	contentType := a.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	http.ServeContent(w, r, a.Filename, a.CreatedOn, bytes.NewReader(a.Content))
+}
+
+func attachmentDetails(files []*Attachment) []redmine.Detail {
+	var details []redmine.Detail
+	for _, a := range files {
+		details = append(details, redmine.Detail{Property: "attachment", Name: strconv.Itoa(a.ID), NewValue: a.Filename})
+	}
+	return details
+}
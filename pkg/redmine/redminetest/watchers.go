@@ -0,0 +1,146 @@
+package redminetest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+)
+
+// AddMember makes u a member of the project with the given identifier, so
+// that it appears in the project's memberships.
+func (s *Server) AddMember(projectIdentifier string, u *User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.findProject(projectIdentifier)
+	if p == nil {
+		panic("redminetest: unknown project " + projectIdentifier)
+	}
+	s.members[p.ID] = append(s.members[p.ID], u.ID)
+}
+
+// Watchers returns the IDs of the users watching an issue.
+func (s *Server) Watchers(issueID int) []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	is := s.findIssue(issueID)
+	if is == nil {
+		return nil
+	}
+	return append([]int(nil), is.watchers...)
+}
+
+func (s *Server) listMemberships(w http.ResponseWriter, r *http.Request, _ *User, params []string) {
+	p := s.findProject(params[0])
+	if p == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	all := []redmine.Membership{}
+	for i, id := range s.members[p.ID] {
+		u := s.userByID(id)
+		all = append(all, redmine.Membership{
+			ID:      p.ID*1000 + i + 1,
+			Project: redmine.Project{ID: p.ID, Name: p.Name},
+			User:    &redmine.User{ID: u.ID, Name: u.Name()},
+			Roles:   []redmine.Role{{ID: 4, Name: "Developer"}},
+		})
+	}
+	offset, limit := paginate(r, len(all))
+	writeJSON(w, http.StatusOK, redmine.MembershipsResponse{
+		Memberships: all[offset:pageEnd(offset, limit, len(all))],
+		TotalCount:  len(all),
+		Offset:      offset,
+		Limit:       limit,
+	})
+}
+
+func (s *Server) listUsers(w http.ResponseWriter, r *http.Request, user *User, _ []string) {
+	// Redmine では管理者のみ参照できる
+	if !user.Admin {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	name := strings.ToLower(r.URL.Query().Get("name"))
+	matched := []redmine.UserDetail{}
+	for _, u := range s.users {
+		if u.Locked {
+			continue
+		}
+		if name != "" && !strings.Contains(strings.ToLower(u.Login+" "+u.FirstName+" "+u.LastName), name) {
+			continue
+		}
+		matched = append(matched, redmine.UserDetail{ID: u.ID, Login: u.Login, Name: u.FirstName, LastName: u.LastName})
+	}
+	offset, limit := paginate(r, len(matched))
+	writeJSON(w, http.StatusOK, redmine.UsersResponse{
+		Users:      matched[offset:pageEnd(offset, limit, len(matched))],
+		TotalCount: len(matched),
+		Offset:     offset,
+		Limit:      limit,
+	})
+}
+
+func (s *Server) addWatcher(w http.ResponseWriter, r *http.Request, _ *User, params []string) {
+	id, _ := strconv.Atoi(params[0])
+	is := s.findIssue(id)
+	if is == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	var req struct {
+		UserID int `json:"user_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if s.userByID(req.UserID) == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if !containsInt(is.watchers, req.UserID) {
+		is.watchers = append(is.watchers, req.UserID)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) removeWatcher(w http.ResponseWriter, r *http.Request, _ *User, params []string) {
+	id, _ := strconv.Atoi(params[0])
+	userID, _ := strconv.Atoi(params[1])
+	is := s.findIssue(id)
+	if is == nil || s.userByID(userID) == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	kept := is.watchers[:0]
+	for _, wid := range is.watchers {
+		if wid != userID {
+			kept = append(kept, wid)
+		}
+	}
+	is.watchers = kept
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// watcherJSON renders the watchers of an issue as Redmine's include=watchers.
+func (s *Server) watcherJSON(is *issue) []redmine.User {
+	var out []redmine.User
+	for _, id := range is.watchers {
+		if u := s.userByID(id); u != nil {
+			out = append(out, redmine.User{ID: u.ID, Name: u.Name()})
+		}
+	}
+	return out
+}
+
+func containsInt(list []int, n int) bool {
+	for _, v := range list {
+		if v == n {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,186 @@
+package redminetest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+)
+
+type wikiPage struct {
+	projectID   int
+	parent      string
+	versions    []redmine.WikiPage
+	attachments []*Attachment
+}
+
+func (p *wikiPage) current() *redmine.WikiPage {
+	return &p.versions[len(p.versions)-1]
+}
+
+// PutWikiPage saves a new version of a wiki page as the admin user, as if
+// someone edited it in the browser, and returns the new version number.
+func (s *Server) PutWikiPage(projectIdentifier, title, text string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.findProject(projectIdentifier)
+	if p == nil {
+		panic("redminetest: unknown project " + projectIdentifier)
+	}
+	return s.saveWikiPage(p.ID, title, text, "", "", s.Admin, nil).current().Version
+}
+
+func (s *Server) findWikiPage(projectID int, title string) *wikiPage {
+	for _, p := range s.wiki {
+		if p.projectID == projectID && p.current().Title == title {
+			return p
+		}
+	}
+	return nil
+}
+
+// saveWikiPage は新しい版を追加する（ページがなければ作る）
+func (s *Server) saveWikiPage(projectID int, title, text, comments, parent string, author *User, files []*Attachment) *wikiPage {
+	now := s.now()
+	page := s.findWikiPage(projectID, title)
+	version := redmine.WikiPage{
+		Title:     title,
+		Text:      text,
+		Version:   1,
+		Author:    redmine.User{ID: author.ID, Name: author.Name()},
+		Comments:  comments,
+		CreatedOn: now,
+		UpdatedOn: now,
+	}
+	if page == nil {
+		page = &wikiPage{projectID: projectID}
+		s.wiki = append(s.wiki, page)
+	} else {
+		version.Version = page.current().Version + 1
+		version.CreatedOn = page.versions[0].CreatedOn
+	}
+	if parent != "" {
+		page.parent = parent
+	}
+	page.versions = append(page.versions, version)
+	page.attachments = append(page.attachments, files...)
+	return page
+}
+
+// wikiPageJSON renders one version of a page with the page's parent and attachments.
+func (s *Server) wikiPageJSON(page *wikiPage, version redmine.WikiPage) redmine.WikiPage {
+	if page.parent != "" {
+		version.Parent = &redmine.WikiPageRef{Title: page.parent}
+	}
+	for _, a := range page.attachments {
+		version.Attachments = append(version.Attachments, s.attachmentJSON(a))
+	}
+	return version
+}
+
+func (s *Server) listWikiPages(w http.ResponseWriter, r *http.Request, _ *User, params []string) {
+	p := s.findProject(params[0])
+	if p == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	pages := []redmine.WikiPageInfo{}
+	for _, page := range s.wiki {
+		if page.projectID != p.ID {
+			continue
+		}
+		cur := page.current()
+		info := redmine.WikiPageInfo{Title: cur.Title, Version: cur.Version, CreatedOn: cur.CreatedOn, UpdatedOn: cur.UpdatedOn}
+		if page.parent != "" {
+			info.Parent = &redmine.WikiPageRef{Title: page.parent}
+		}
+		pages = append(pages, info)
+	}
+	writeJSON(w, http.StatusOK, redmine.WikiPagesResponse{WikiPages: pages})
+}
+
+func (s *Server) getWikiPage(w http.ResponseWriter, r *http.Request, _ *User, params []string) {
+	p := s.findProject(params[0])
+	if p == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	page := s.findWikiPage(p.ID, params[1])
+	if page == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	version := *page.current()
+	if len(params) > 2 {
+		n, _ := strconv.Atoi(params[2])
+		if n < 1 || n > len(page.versions) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		version = page.versions[n-1]
+	}
+	writeJSON(w, http.StatusOK, redmine.WikiPageResponse{WikiPage: s.wikiPageJSON(page, version)})
+}
+
+func (s *Server) putWikiPage(w http.ResponseWriter, r *http.Request, user *User, params []string) {
+	p := s.findProject(params[0])
+	if p == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	var req struct {
+		WikiPage redmine.WikiPageUpdate `json:"wiki_page"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	u := req.WikiPage
+
+	page := s.findWikiPage(p.ID, params[1])
+	// 編集元の版より新しい版があれば 409 を返す
+	if page != nil && u.Version != 0 && u.Version != page.current().Version {
+		w.WriteHeader(http.StatusConflict)
+		return
+	}
+	if u.ParentTitle != "" && s.findWikiPage(p.ID, u.ParentTitle) == nil {
+		writeErrors(w, "Parent page is invalid")
+		return
+	}
+	files, errs := s.takeUploads(u.Uploads)
+	if len(errs) > 0 {
+		writeErrors(w, errs...)
+		return
+	}
+
+	// 内容が変わらなければ新しい版は作らない
+	if page != nil && page.current().Text == u.Text && u.ParentTitle == "" {
+		page.attachments = append(page.attachments, files...)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	created := page == nil
+	page = s.saveWikiPage(p.ID, params[1], u.Text, u.Comments, u.ParentTitle, user, files)
+	if created {
+		writeJSON(w, http.StatusCreated, redmine.WikiPageResponse{WikiPage: s.wikiPageJSON(page, *page.current())})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) deleteWikiPage(w http.ResponseWriter, r *http.Request, _ *User, params []string) {
+	p := s.findProject(params[0])
+	if p == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	for i, page := range s.wiki {
+		if page.projectID == p.ID && page.current().Title == params[1] {
+			s.wiki = append(s.wiki[:i], s.wiki[i+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNotFound)
+}
@@ -0,0 +1,143 @@
+package redmine
+
+import (
+	"context"
+	"fmt"
+)
+
+// Relation types accepted by Redmine. The reverse forms (blocked, follows,
+// duplicated, copied_from) are stored by Redmine as the forward type with the
+// issues swapped.
+const (
+	RelationRelates    = "relates"
+	RelationDuplicates = "duplicates"
+	RelationDuplicated = "duplicated"
+	RelationBlocks     = "blocks"
+	RelationBlocked    = "blocked"
+	RelationPrecedes   = "precedes"
+	RelationFollows    = "follows"
+	RelationCopiedTo   = "copied_to"
+	RelationCopiedFrom = "copied_from"
+)
+
+// RelationTypes lists every relation type in Redmine's order.
+var RelationTypes = []string{
+	RelationRelates,
+	RelationDuplicates,
+	RelationDuplicated,
+	RelationBlocks,
+	RelationBlocked,
+	RelationPrecedes,
+	RelationFollows,
+	RelationCopiedTo,
+	RelationCopiedFrom,
+}
+
+// reverseRelation maps each relation type to the type seen from the other issue.
+var reverseRelation = map[string]string{
+	RelationRelates:    RelationRelates,
+	RelationDuplicates: RelationDuplicated,
+	RelationDuplicated: RelationDuplicates,
+	RelationBlocks:     RelationBlocked,
+	RelationBlocked:    RelationBlocks,
+	RelationPrecedes:   RelationFollows,
+	RelationFollows:    RelationPrecedes,
+	RelationCopiedTo:   RelationCopiedFrom,
+	RelationCopiedFrom: RelationCopiedTo,
+}
+
+// IsRelationType reports whether t is a relation type Redmine accepts.
+func IsRelationType(t string) bool {
+	_, ok := reverseRelation[t]
+	return ok
+}
+
+type Relation struct {
+	ID           int    `json:"id"`
+	IssueID      int    `json:"issue_id"`
+	IssueToID    int    `json:"issue_to_id"`
+	RelationType string `json:"relation_type"`
+	Delay        *int   `json:"delay"`
+}
+
+// From returns the relation type and the other issue as seen from issueID,
+// e.g. "blocked" for the target of a "blocks" relation.
+func (r *Relation) From(issueID int) (relationType string, otherID int) {
+	if r.IssueID == issueID {
+		return r.RelationType, r.IssueToID
+	}
+	return reverseRelation[r.RelationType], r.IssueID
+}
+
+type RelationsResponse struct {
+	Relations []Relation `json:"relations"`
+}
+
+type RelationResponse struct {
+	Relation Relation `json:"relation"`
+}
+
+// RelationCreate links an issue to IssueToID. Delay is only meaningful for
+// precedes/follows.
+type RelationCreate struct {
+	IssueToID    int    `json:"issue_to_id"`
+	RelationType string `json:"relation_type"`
+	Delay        *int   `json:"delay,omitempty"`
+}
+
+type relationCreateRequest struct {
+	Relation RelationCreate `json:"relation"`
+}
+
+func (c *Client) ListRelations(issueID int) ([]Relation, error) {
+	return c.ListRelationsContext(context.Background(), issueID)
+}
+
+// ListRelationsContext is like ListRelations but uses ctx for the request.
+func (c *Client) ListRelationsContext(ctx context.Context, issueID int) ([]Relation, error) {
+	var response RelationsResponse
+	if err := c.GetContext(ctx, fmt.Sprintf("/issues/%d/relations.json", issueID), nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Relations, nil
+}
+
+func (c *Client) GetRelation(id int) (*Relation, error) {
+	return c.GetRelationContext(context.Background(), id)
+}
+
+// GetRelationContext is like GetRelation but uses ctx for the request.
+func (c *Client) GetRelationContext(ctx context.Context, id int) (*Relation, error) {
+	var response RelationResponse
+	if err := c.GetContext(ctx, fmt.Sprintf("/relations/%d.json", id), nil, &response); err != nil {
+		return nil, err
+	}
+	return &response.Relation, nil
+}
+
+func (c *Client) CreateRelation(issueID int, relation *RelationCreate) (*Relation, error) {
+	return c.CreateRelationContext(context.Background(), issueID, relation)
+}
+
+// CreateRelationContext is like CreateRelation but uses ctx for the request.
+func (c *Client) CreateRelationContext(ctx context.Context, issueID int, relation *RelationCreate) (*Relation, error) {
+	if !IsRelationType(relation.RelationType) {
+		return nil, fmt.Errorf("unknown relation type '%s'", relation.RelationType)
+	}
+
+	var response RelationResponse
+	path := fmt.Sprintf("/issues/%d/relations.json", issueID)
+	if err := c.PostContext(ctx, path, nil, relationCreateRequest{Relation: *relation}, &response); err != nil {
+		return nil, err
+	}
+	return &response.Relation, nil
+}
+
+func (c *Client) DeleteRelation(id int) error {
+	return c.DeleteRelationContext(context.Background(), id)
+}
+
+// DeleteRelationContext is like DeleteRelation but uses ctx for the request.
+func (c *Client) DeleteRelationContext(ctx context.Context, id int) error {
+	return c.DeleteContext(ctx, fmt.Sprintf("/relations/%d.json", id), nil)
+}
@@ -0,0 +1,82 @@
+package redmine_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+	"github.com/ikasamt/rd/pkg/redmine/redminetest"
+)
+
+func TestRelationLifecycle(t *testing.T) {
+	srv := redminetest.NewServer()
+	defer srv.Close()
+	project := srv.AddProject("demo", "Demo")
+	for _, subject := range []string{"One", "Two", "Three"} {
+		srv.AddIssue(redmine.IssueCreate{ProjectID: project.ID, Subject: subject})
+	}
+	client := srv.Client()
+
+	blocks, err := client.CreateRelation(1, &redmine.RelationCreate{IssueToID: 2, RelationType: redmine.RelationBlocks})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 逆向きの種類は向きを入れ替えて保存される
+	delay := 3
+	follows, err := client.CreateRelation(1, &redmine.RelationCreate{IssueToID: 3, RelationType: redmine.RelationFollows, Delay: &delay})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if follows.IssueID != 3 || follows.IssueToID != 1 || follows.RelationType != redmine.RelationPrecedes || follows.Delay == nil || *follows.Delay != 3 {
+		t.Errorf("follows relation = %+v", follows)
+	}
+
+	relations, err := client.ListRelations(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(relations) != 2 {
+		t.Fatalf("relations of #1 = %+v", relations)
+	}
+	got, err := client.GetRelation(blocks.ID)
+	if err != nil || got.IssueID != 1 || got.IssueToID != 2 || got.RelationType != redmine.RelationBlocks {
+		t.Errorf("GetRelation = %+v, %v", got, err)
+	}
+
+	if err := client.DeleteRelation(blocks.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetRelation(blocks.ID); !errors.Is(err, redmine.ErrNotFound) {
+		t.Errorf("after delete: err = %v, want ErrNotFound", err)
+	}
+	if relations, _ := client.ListRelations(2); len(relations) != 0 {
+		t.Errorf("relations of #2 after delete = %+v", relations)
+	}
+
+	// 同じ組み合わせは向きを問わず重複できない
+	if _, err := client.CreateRelation(3, &redmine.RelationCreate{IssueToID: 1, RelationType: redmine.RelationRelates}); !errors.Is(err, redmine.ErrValidation) {
+		t.Errorf("duplicate relation: err = %v, want ErrValidation", err)
+	}
+}
+
+func TestRelationFrom(t *testing.T) {
+	tests := []struct {
+		relation  redmine.Relation
+		issueID   int
+		wantType  string
+		wantOther int
+	}{
+		{redmine.Relation{IssueID: 1, IssueToID: 2, RelationType: redmine.RelationBlocks}, 1, redmine.RelationBlocks, 2},
+		{redmine.Relation{IssueID: 1, IssueToID: 2, RelationType: redmine.RelationBlocks}, 2, redmine.RelationBlocked, 1},
+		{redmine.Relation{IssueID: 1, IssueToID: 2, RelationType: redmine.RelationPrecedes}, 2, redmine.RelationFollows, 1},
+		{redmine.Relation{IssueID: 1, IssueToID: 2, RelationType: redmine.RelationDuplicates}, 2, redmine.RelationDuplicated, 1},
+		{redmine.Relation{IssueID: 1, IssueToID: 2, RelationType: redmine.RelationCopiedTo}, 2, redmine.RelationCopiedFrom, 1},
+		{redmine.Relation{IssueID: 1, IssueToID: 2, RelationType: redmine.RelationRelates}, 2, redmine.RelationRelates, 1},
+	}
+	for _, tt := range tests {
+		gotType, gotOther := tt.relation.From(tt.issueID)
+		if gotType != tt.wantType || gotOther != tt.wantOther {
+			t.Errorf("%s from #%d = %s #%d, want %s #%d", tt.relation.RelationType, tt.issueID, gotType, gotOther, tt.wantType, tt.wantOther)
+		}
+	}
+}
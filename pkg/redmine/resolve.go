@@ -0,0 +1,176 @@
+package redmine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ResolveError reports a value that matches none or several of the known
+// names. It matches ErrValidation: the value given is wrong, not the
+// resource that was requested.
+type ResolveError struct {
+	Kind       string // "status", "tracker", ...
+	Value      string
+	Project    string
+	Issue      int
+	Candidates []string
+	Ambiguous  bool
+	// Hint explains how to get a match, e.g. when logins cannot be looked up.
+	Hint string
+}
+
+func (e *ResolveError) Error() string {
+	if e.Hint != "" {
+		return e.message() + "; " + e.Hint
+	}
+	return e.message()
+}
+
+func (e *ResolveError) message() string {
+	where := ""
+	if e.Project != "" {
+		where = fmt.Sprintf(" in project '%s'", e.Project)
+	}
+	if e.Issue != 0 {
+		where = fmt.Sprintf(" on issue #%d", e.Issue)
+	}
+	if e.Ambiguous {
+		return fmt.Sprintf("%s '%s' is ambiguous%s (matches: %s)", e.Kind, e.Value, where, strings.Join(e.Candidates, ", "))
+	}
+	if len(e.Candidates) == 0 {
+		return fmt.Sprintf("%s '%s' not found%s", e.Kind, e.Value, where)
+	}
+	return fmt.Sprintf("%s '%s' not found%s (available: %s)", e.Kind, e.Value, where, strings.Join(e.Candidates, ", "))
+}
+
+func (e *ResolveError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// unmatched は err が「どの名前にも一致しなかった」ResolveError かを返す。
+// 曖昧な場合はキャッシュを取り直しても解決しないので含めない。
+func unmatched(err error) bool {
+	var resolveErr *ResolveError
+	return errors.As(err, &resolveErr) && !resolveErr.Ambiguous
+}
+
+// resolveNamed は ID、名前（大文字小文字を区別しない）、名前の前方一致の順に items から1件を選ぶ。
+func resolveNamed[T any](kind, project, value string, items []T, key func(*T) (int, string)) (*T, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, fmt.Errorf("%s cannot be blank", kind)
+	}
+	id, idErr := strconv.Atoi(value)
+
+	var names []string
+	var exact, prefix []int
+	for i := range items {
+		itemID, name := key(&items[i])
+		names = append(names, name)
+		switch {
+		case idErr == nil:
+			if itemID == id {
+				return &items[i], nil
+			}
+		case strings.EqualFold(name, value):
+			exact = append(exact, i)
+		case strings.HasPrefix(strings.ToLower(name), strings.ToLower(value)):
+			prefix = append(prefix, i)
+		}
+	}
+
+	matches := exact
+	if len(matches) == 0 {
+		matches = prefix
+	}
+	if len(matches) == 1 {
+		return &items[matches[0]], nil
+	}
+	err := &ResolveError{Kind: kind, Value: value, Project: project, Candidates: names}
+	if len(matches) > 1 {
+		err.Ambiguous = true
+		err.Candidates = nil
+		for _, i := range matches {
+			itemID, name := key(&items[i])
+			err.Candidates = append(err.Candidates, fmt.Sprintf("%s (%d)", name, itemID))
+		}
+	}
+	return nil, err
+}
+
+// ResolveStatus accepts an issue status ID, name or unambiguous name prefix.
+func (c *Client) ResolveStatus(value string) (*IssueStatus, error) {
+	return c.ResolveStatusContext(context.Background(), value)
+}
+
+// ResolveStatusContext is like ResolveStatus but uses ctx for the request.
+func (c *Client) ResolveStatusContext(ctx context.Context, value string) (*IssueStatus, error) {
+	statuses, cached, err := c.listIssueStatuses(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list issue statuses: %w", err)
+	}
+	status, err := resolveNamed("status", "", value, statuses, func(s *IssueStatus) (int, string) { return s.ID, s.Name })
+	// キャッシュが古い可能性があるので取り直して再検索
+	if cached && unmatched(err) {
+		return c.ResolveStatusContext(WithCacheRefresh(ctx), value)
+	}
+	return status, err
+}
+
+// ResolveTracker accepts a tracker ID, name or unambiguous name prefix.
+func (c *Client) ResolveTracker(value string) (*Tracker, error) {
+	return c.ResolveTrackerContext(context.Background(), value)
+}
+
+// ResolveTrackerContext is like ResolveTracker but uses ctx for the request.
+func (c *Client) ResolveTrackerContext(ctx context.Context, value string) (*Tracker, error) {
+	trackers, cached, err := c.listTrackers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trackers: %w", err)
+	}
+	tracker, err := resolveNamed("tracker", "", value, trackers, func(t *Tracker) (int, string) { return t.ID, t.Name })
+	if cached && unmatched(err) {
+		return c.ResolveTrackerContext(WithCacheRefresh(ctx), value)
+	}
+	return tracker, err
+}
+
+// ResolvePriority accepts an issue priority ID, name or unambiguous name prefix.
+func (c *Client) ResolvePriority(value string) (*IssuePriority, error) {
+	return c.ResolvePriorityContext(context.Background(), value)
+}
+
+// ResolvePriorityContext is like ResolvePriority but uses ctx for the request.
+func (c *Client) ResolvePriorityContext(ctx context.Context, value string) (*IssuePriority, error) {
+	priorities, cached, err := c.listIssuePriorities(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list issue priorities: %w", err)
+	}
+	priority, err := resolveNamed("priority", "", value, priorities, func(p *IssuePriority) (int, string) { return p.ID, p.Name })
+	if cached && unmatched(err) {
+		return c.ResolvePriorityContext(WithCacheRefresh(ctx), value)
+	}
+	return priority, err
+}
+
+// ResolveCategory accepts the ID, name or unambiguous name prefix of an
+// issue category of projectID.
+func (c *Client) ResolveCategory(projectID, value string) (*IssueCategory, error) {
+	return c.ResolveCategoryContext(context.Background(), projectID, value)
+}
+
+// ResolveCategoryContext is like ResolveCategory but uses ctx for the request.
+func (c *Client) ResolveCategoryContext(ctx context.Context, projectID, value string) (*IssueCategory, error) {
+	categories, cached, err := c.listIssueCategories(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list issue categories: %w", err)
+	}
+	category, err := resolveNamed("category", projectID, value, categories, func(c *IssueCategory) (int, string) { return c.ID, c.Name })
+	if cached && unmatched(err) {
+		return c.ResolveCategoryContext(WithCacheRefresh(ctx), projectID, value)
+	}
+	return category, err
+}
@@ -0,0 +1,74 @@
+package redmine_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+	"github.com/ikasamt/rd/pkg/redmine/redminetest"
+)
+
+func TestNotFoundListsCandidates(t *testing.T) {
+	srv := redminetest.NewServer()
+	defer srv.Close()
+	srv.AddProject("demo", "Demo")
+	srv.AddVersion("demo", "v1.0")
+	srv.AddVersion("demo", "v2.0")
+	client := srv.Client()
+
+	tests := []struct {
+		name string
+		find func() error
+		want redmine.ResolveError
+	}{
+		{"version", func() error {
+			_, err := client.FindVersionByName("demo", "v3.0")
+			return err
+		}, redmine.ResolveError{Kind: "version", Value: "v3.0", Project: "demo", Candidates: []string{"v1.0", "v2.0"}}},
+		{"time entry activity", func() error {
+			_, err := client.FindTimeEntryActivityByName("Meeting")
+			return err
+		}, redmine.ResolveError{Kind: "time entry activity", Value: "Meeting", Candidates: []string{"Design", "Development", "Testing"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.find()
+			if !errors.Is(err, redmine.ErrValidation) {
+				t.Fatalf("err = %v, want ErrValidation", err)
+			}
+			var resolveErr *redmine.ResolveError
+			if !errors.As(err, &resolveErr) {
+				t.Fatalf("err = %T, want *ResolveError", err)
+			}
+			got := *resolveErr
+			if got.Kind != tt.want.Kind || got.Value != tt.want.Value || got.Project != tt.want.Project || !slices.Equal(got.Candidates, tt.want.Candidates) {
+				t.Errorf("err = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveRefetchesStaleCache(t *testing.T) {
+	srv := redminetest.NewServer()
+	defer srv.Close()
+	srv.AddProject("demo", "Demo")
+	srv.AddCategory("demo", "Backend")
+	client := srv.Client()
+	client.Cache = &redmine.Cache{Dir: t.TempDir(), TTL: redmine.DefaultCacheTTLs()}
+
+	if _, err := client.ResolveCategory("demo", "Backend"); err != nil {
+		t.Fatal(err)
+	}
+	// キャッシュした後に追加された名前も取り直して見つける
+	added := srv.AddCategory("demo", "Frontend")
+	category, err := client.ResolveCategory("demo", "Frontend")
+	if err != nil || category.ID != added.ID {
+		t.Fatalf("ResolveCategory = %+v, %v, want #%d", category, err, added.ID)
+	}
+
+	_, err = client.ResolveCategory("demo", "Docs")
+	if !errors.Is(err, redmine.ErrValidation) || errors.Is(err, redmine.ErrNotFound) {
+		t.Errorf("err = %v, want ErrValidation only", err)
+	}
+}
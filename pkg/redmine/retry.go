@@ -0,0 +1,116 @@
+package redmine
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how failed requests are retried.
+// Only idempotent methods, or requests whose context was passed through
+// WithRetrySafe, are retried.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt. 0 disables retries.
+	MaxRetries int
+	// MinWait is the base backoff before the first retry.
+	MinWait time.Duration
+	// MaxWait caps the backoff and any Retry-After value sent by the server.
+	MaxWait time.Duration
+}
+
+// DefaultRetryPolicy returns the policy used by NewClient.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: 3,
+		MinWait:    500 * time.Millisecond,
+		MaxWait:    30 * time.Second,
+	}
+}
+
+type retrySafeKey struct{}
+
+// WithRetrySafe marks requests made with the returned context as safe to
+// retry even if their method is not idempotent (e.g. a POST the caller knows
+// can be repeated without side effects).
+func WithRetrySafe(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retrySafeKey{}, true)
+}
+
+func canRetry(ctx context.Context, method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	safe, _ := ctx.Value(retrySafeKey{}).(bool)
+	return safe
+}
+
+// isRetryableStatus reports whether the status indicates a transient failure,
+// such as a reverse proxy returning 502/503 during a deploy.
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// backoff returns how long to wait before retry number attempt (0-based).
+// A Retry-After header takes precedence over exponential backoff with jitter.
+func (p RetryPolicy) backoff(attempt int, header http.Header) time.Duration {
+	if wait, ok := parseRetryAfter(header); ok {
+		if p.MaxWait > 0 && wait > p.MaxWait {
+			wait = p.MaxWait
+		}
+		return wait
+	}
+
+	wait := p.MinWait
+	for i := 0; i < attempt && (p.MaxWait <= 0 || wait < p.MaxWait); i++ {
+		wait *= 2
+	}
+	if p.MaxWait > 0 && wait > p.MaxWait {
+		wait = p.MaxWait
+	}
+	if wait <= 0 {
+		return 0
+	}
+	// 半分を固定、残り半分をランダムにして同時リトライの集中を避ける
+	half := wait / 2
+	return half + time.Duration(rand.Int63n(int64(wait-half)+1))
+}
+
+func parseRetryAfter(header http.Header) (time.Duration, bool) {
+	if header == nil {
+		return 0, false
+	}
+	v := header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		wait := time.Until(t)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+// sleepContext waits for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
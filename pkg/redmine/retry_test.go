@@ -0,0 +1,137 @@
+package redmine
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// statusServer は statuses を順に返し（尽きたら最後のものを繰り返す）、リクエスト数を数える。
+func statusServer(t *testing.T, header http.Header, statuses ...int) (*httptest.Server, *int32) {
+	t.Helper()
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&calls, 1)) - 1
+		if n >= len(statuses) {
+			n = len(statuses) - 1
+		}
+		for k, v := range header {
+			w.Header()[k] = v
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statuses[n])
+		w.Write([]byte("{}"))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func TestRetry(t *testing.T) {
+	fast := RetryPolicy{MaxRetries: 3, MinWait: time.Millisecond, MaxWait: 10 * time.Millisecond}
+	tests := []struct {
+		name      string
+		method    string
+		safe      bool
+		policy    RetryPolicy
+		header    http.Header
+		statuses  []int
+		wantCalls int32
+		// wantStatus は最後に返るエラーのステータス。0 なら成功
+		wantStatus int
+	}{
+		// MinWait が1時間でも Retry-After: 0 に従ってすぐ再試行する
+		{"429 honours Retry-After", http.MethodGet, false, RetryPolicy{MaxRetries: 3, MinWait: time.Hour, MaxWait: time.Hour},
+			http.Header{"Retry-After": {"0"}}, []int{429, 200}, 2, 0},
+		{"503 then success", http.MethodGet, false, fast, nil, []int{503, 200}, 2, 0},
+		{"gives up after MaxRetries", http.MethodGet, false, fast, nil, []int{502}, 4, 502},
+		{"PUT is retried", http.MethodPut, false, fast, nil, []int{504, 200}, 2, 0},
+		{"POST is not retried", http.MethodPost, false, fast, nil, []int{503, 200}, 1, 503},
+		{"POST marked safe is retried", http.MethodPost, true, fast, nil, []int{503, 200}, 2, 0},
+		{"4xx is not retried", http.MethodGet, false, fast, nil, []int{404, 200}, 1, 404},
+		{"retries disabled", http.MethodGet, false, RetryPolicy{}, nil, []int{503, 200}, 1, 503},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, calls := statusServer(t, tt.header, tt.statuses...)
+			c := NewClient(srv.URL, "key")
+			c.Retry = tt.policy
+			ctx := context.Background()
+			if tt.safe {
+				ctx = WithRetrySafe(ctx)
+			}
+
+			done := make(chan error, 1)
+			go func() {
+				_, err := c.doRequest(ctx, tt.method, "/issues.json", nil, nil)
+				done <- err
+			}()
+			select {
+			case err := <-done:
+				var apiErr *APIError
+				switch {
+				case tt.wantStatus == 0 && err != nil:
+					t.Errorf("err = %v, want success", err)
+				case tt.wantStatus != 0 && (!errors.As(err, &apiErr) || apiErr.StatusCode != tt.wantStatus):
+					t.Errorf("err = %v, want status %d", err, tt.wantStatus)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("request did not finish")
+			}
+			if got := atomic.LoadInt32(calls); got != tt.wantCalls {
+				t.Errorf("%d requests, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryStopsWhenContextIsCancelled(t *testing.T) {
+	srv, calls := statusServer(t, nil, http.StatusServiceUnavailable)
+	c := NewClient(srv.URL, "key")
+	c.Retry = RetryPolicy{MaxRetries: 5, MinWait: time.Hour, MaxWait: time.Hour}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	_, err := c.doRequest(ctx, http.MethodGet, "/issues.json", nil, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("waited %s after cancel", elapsed)
+	}
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("%d requests, want 1", got)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	p := RetryPolicy{MinWait: 100 * time.Millisecond, MaxWait: time.Second}
+	tests := []struct {
+		name     string
+		attempt  int
+		header   http.Header
+		min, max time.Duration
+	}{
+		{"first retry", 0, nil, 50 * time.Millisecond, 100 * time.Millisecond},
+		{"doubles", 2, nil, 200 * time.Millisecond, 400 * time.Millisecond},
+		{"capped at MaxWait", 10, nil, 500 * time.Millisecond, time.Second},
+		{"Retry-After seconds", 0, http.Header{"Retry-After": {"1"}}, time.Second, time.Second},
+		{"Retry-After capped", 0, http.Header{"Retry-After": {"120"}}, time.Second, time.Second},
+		{"Retry-After date in the past", 0, http.Header{"Retry-After": {"Mon, 02 Jan 2006 15:04:05 GMT"}}, 0, 0},
+		{"invalid Retry-After falls back", 0, http.Header{"Retry-After": {"soon"}}, 50 * time.Millisecond, 100 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ジッターがあるので何度か試して範囲に収まることを確かめる
+			for i := 0; i < 50; i++ {
+				if got := p.backoff(tt.attempt, tt.header); got < tt.min || got > tt.max {
+					t.Fatalf("backoff = %s, want between %s and %s", got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package redmine
 
 import (
+	"context"
 	"net/url"
 	"strconv"
 )
@@ -42,6 +43,11 @@ type SearchOptions struct {
 
 // Search performs a search using Redmine's /search API
 func (c *Client) Search(opts *SearchOptions) (*SearchResponse, error) {
+	return c.SearchContext(context.Background(), opts)
+}
+
+// SearchContext is like Search but uses ctx for the request
+func (c *Client) SearchContext(ctx context.Context, opts *SearchOptions) (*SearchResponse, error) {
 	params := url.Values{}
 	params.Set("q", opts.Query)
 	
@@ -86,7 +92,7 @@ func (c *Client) Search(opts *SearchOptions) (*SearchResponse, error) {
 	}
 	
 	var response SearchResponse
-	if err := c.Get("/search.json", params, &response); err != nil {
+	if err := c.GetContext(ctx, "/search.json", params, &response); err != nil {
 		return nil, err
 	}
 	
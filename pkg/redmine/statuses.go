@@ -0,0 +1,31 @@
+package redmine
+
+import "context"
+
+type IssueStatus struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	IsClosed bool   `json:"is_closed"`
+}
+
+type IssueStatusesResponse struct {
+	IssueStatuses []IssueStatus `json:"issue_statuses"`
+}
+
+func (c *Client) ListIssueStatuses() ([]IssueStatus, error) {
+	return c.ListIssueStatusesContext(context.Background())
+}
+
+func (c *Client) ListIssueStatusesContext(ctx context.Context) ([]IssueStatus, error) {
+	statuses, _, err := c.listIssueStatuses(ctx)
+	return statuses, err
+}
+
+func (c *Client) listIssueStatuses(ctx context.Context) ([]IssueStatus, bool, error) {
+	var response IssueStatusesResponse
+	cached, err := c.cachedGet(ctx, ResourceIssueStatuses, "", "/issue_statuses.json", nil, &response)
+	if err != nil {
+		return nil, false, err
+	}
+	return response.IssueStatuses, cached, nil
+}
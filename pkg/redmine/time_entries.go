@@ -0,0 +1,228 @@
+package redmine
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Activity struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type TimeEntry struct {
+	ID        int          `json:"id"`
+	Project   Project      `json:"project"`
+	Issue     *IssueParent `json:"issue,omitempty"`
+	User      User         `json:"user"`
+	Activity  Activity     `json:"activity"`
+	Hours     float64      `json:"hours"`
+	Comments  string       `json:"comments"`
+	SpentOn   string       `json:"spent_on"`
+	CreatedOn time.Time    `json:"created_on"`
+	UpdatedOn time.Time    `json:"updated_on"`
+}
+
+type TimeEntriesResponse struct {
+	TimeEntries []TimeEntry `json:"time_entries"`
+	TotalCount  int         `json:"total_count"`
+	Offset      int         `json:"offset"`
+	Limit       int         `json:"limit"`
+}
+
+type TimeEntryResponse struct {
+	TimeEntry TimeEntry `json:"time_entry"`
+}
+
+// TimeEntryCreate logs time on an issue or, without IssueID, on a project.
+type TimeEntryCreate struct {
+	IssueID    int     `json:"issue_id,omitempty"`
+	ProjectID  string  `json:"project_id,omitempty"`
+	SpentOn    string  `json:"spent_on,omitempty"`
+	Hours      float64 `json:"hours"`
+	ActivityID int     `json:"activity_id,omitempty"`
+	Comments   string  `json:"comments,omitempty"`
+	UserID     int     `json:"user_id,omitempty"`
+}
+
+// TimeEntryUpdate changes only the fields that are set.
+type TimeEntryUpdate struct {
+	IssueID    *int     `json:"issue_id,omitempty"`
+	SpentOn    *string  `json:"spent_on,omitempty"`
+	Hours      *float64 `json:"hours,omitempty"`
+	ActivityID *int     `json:"activity_id,omitempty"`
+	Comments   *string  `json:"comments,omitempty"`
+}
+
+type timeEntryCreateRequest struct {
+	TimeEntry TimeEntryCreate `json:"time_entry"`
+}
+
+type timeEntryUpdateRequest struct {
+	TimeEntry TimeEntryUpdate `json:"time_entry"`
+}
+
+// TimeEntryFilter narrows ListTimeEntries. From and To are YYYY-MM-DD dates
+// (inclusive); UserID accepts "me".
+type TimeEntryFilter struct {
+	ProjectID  string
+	IssueID    int
+	UserID     string
+	ActivityID int
+	From       string
+	To         string
+	Limit      int
+	Offset     int
+}
+
+func (f *TimeEntryFilter) params() url.Values {
+	params := url.Values{}
+	if f.ProjectID != "" {
+		params.Set("project_id", f.ProjectID)
+	}
+	if f.IssueID > 0 {
+		params.Set("issue_id", strconv.Itoa(f.IssueID))
+	}
+	if f.UserID != "" {
+		params.Set("user_id", f.UserID)
+	}
+	if f.ActivityID > 0 {
+		params.Set("activity_id", strconv.Itoa(f.ActivityID))
+	}
+	if f.From != "" {
+		params.Set("from", f.From)
+	}
+	if f.To != "" {
+		params.Set("to", f.To)
+	}
+	limit := f.Limit
+	if limit <= 0 {
+		limit = 25
+	}
+	params.Set("limit", strconv.Itoa(limit))
+	if f.Offset > 0 {
+		params.Set("offset", strconv.Itoa(f.Offset))
+	}
+	return params
+}
+
+func (c *Client) ListTimeEntries(filter *TimeEntryFilter) (*TimeEntriesResponse, error) {
+	return c.ListTimeEntriesContext(context.Background(), filter)
+}
+
+// ListTimeEntriesContext is like ListTimeEntries but uses ctx for the request.
+func (c *Client) ListTimeEntriesContext(ctx context.Context, filter *TimeEntryFilter) (*TimeEntriesResponse, error) {
+	if filter == nil {
+		filter = &TimeEntryFilter{}
+	}
+
+	var response TimeEntriesResponse
+	if err := c.GetContext(ctx, "/time_entries.json", filter.params(), &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+func (c *Client) GetTimeEntry(id int) (*TimeEntry, error) {
+	return c.GetTimeEntryContext(context.Background(), id)
+}
+
+// GetTimeEntryContext is like GetTimeEntry but uses ctx for the request.
+func (c *Client) GetTimeEntryContext(ctx context.Context, id int) (*TimeEntry, error) {
+	var response TimeEntryResponse
+	if err := c.GetContext(ctx, fmt.Sprintf("/time_entries/%d.json", id), nil, &response); err != nil {
+		return nil, err
+	}
+	return &response.TimeEntry, nil
+}
+
+func (c *Client) CreateTimeEntry(entry *TimeEntryCreate) (*TimeEntry, error) {
+	return c.CreateTimeEntryContext(context.Background(), entry)
+}
+
+// CreateTimeEntryContext is like CreateTimeEntry but uses ctx for the request.
+func (c *Client) CreateTimeEntryContext(ctx context.Context, entry *TimeEntryCreate) (*TimeEntry, error) {
+	var response TimeEntryResponse
+	if err := c.PostContext(ctx, "/time_entries.json", nil, timeEntryCreateRequest{TimeEntry: *entry}, &response); err != nil {
+		return nil, err
+	}
+	return &response.TimeEntry, nil
+}
+
+func (c *Client) UpdateTimeEntry(id int, update *TimeEntryUpdate) error {
+	return c.UpdateTimeEntryContext(context.Background(), id, update)
+}
+
+// UpdateTimeEntryContext is like UpdateTimeEntry but uses ctx for the request.
+func (c *Client) UpdateTimeEntryContext(ctx context.Context, id int, update *TimeEntryUpdate) error {
+	return c.PutContext(ctx, fmt.Sprintf("/time_entries/%d.json", id), nil, timeEntryUpdateRequest{TimeEntry: *update})
+}
+
+func (c *Client) DeleteTimeEntry(id int) error {
+	return c.DeleteTimeEntryContext(context.Background(), id)
+}
+
+// DeleteTimeEntryContext is like DeleteTimeEntry but uses ctx for the request.
+func (c *Client) DeleteTimeEntryContext(ctx context.Context, id int) error {
+	return c.DeleteContext(ctx, fmt.Sprintf("/time_entries/%d.json", id), nil)
+}
+
+type TimeEntryActivity struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	IsDefault bool   `json:"is_default"`
+	Active    bool   `json:"active"`
+}
+
+type TimeEntryActivitiesResponse struct {
+	TimeEntryActivities []TimeEntryActivity `json:"time_entry_activities"`
+}
+
+func (c *Client) ListTimeEntryActivities() ([]TimeEntryActivity, error) {
+	return c.ListTimeEntryActivitiesContext(context.Background())
+}
+
+// ListTimeEntryActivitiesContext is like ListTimeEntryActivities but uses ctx for the request.
+func (c *Client) ListTimeEntryActivitiesContext(ctx context.Context) ([]TimeEntryActivity, error) {
+	activities, _, err := c.listTimeEntryActivities(ctx)
+	return activities, err
+}
+
+func (c *Client) listTimeEntryActivities(ctx context.Context) ([]TimeEntryActivity, bool, error) {
+	var response TimeEntryActivitiesResponse
+	cached, err := c.cachedGet(ctx, ResourceTimeEntryActivities, "", "/enumerations/time_entry_activities.json", nil, &response)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to list time entry activities: %w", err)
+	}
+	return response.TimeEntryActivities, cached, nil
+}
+
+// FindTimeEntryActivityByName はアクティビティ名（大文字小文字を区別しない）からIDを解決する
+func (c *Client) FindTimeEntryActivityByName(name string) (*TimeEntryActivity, error) {
+	return c.FindTimeEntryActivityByNameContext(context.Background(), name)
+}
+
+func (c *Client) FindTimeEntryActivityByNameContext(ctx context.Context, name string) (*TimeEntryActivity, error) {
+	activities, cached, err := c.listTimeEntryActivities(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range activities {
+		if strings.EqualFold(a.Name, name) {
+			return &a, nil
+		}
+	}
+	// キャッシュが古い可能性があるので取り直して再検索
+	if cached {
+		return c.FindTimeEntryActivityByNameContext(WithCacheRefresh(ctx), name)
+	}
+	names := make([]string, 0, len(activities))
+	for _, a := range activities {
+		names = append(names, a.Name)
+	}
+	return nil, &ResolveError{Kind: "time entry activity", Value: name, Candidates: names}
+}
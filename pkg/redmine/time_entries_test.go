@@ -0,0 +1,122 @@
+package redmine_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+	"github.com/ikasamt/rd/pkg/redmine/redminetest"
+)
+
+func TestTimeEntryLifecycle(t *testing.T) {
+	srv := redminetest.NewServer()
+	defer srv.Close()
+	project := srv.AddProject("demo", "Demo")
+	issue := srv.AddIssue(redmine.IssueCreate{ProjectID: project.ID, Subject: "Parser"})
+	client := srv.Client()
+
+	created, err := client.CreateTimeEntry(&redmine.TimeEntryCreate{IssueID: issue.ID, Hours: 1.5, SpentOn: "2025-01-10", Comments: "Reviewed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 作業分類を省略するとデフォルトになる
+	if created.Activity.Name != "Development" || created.Project.ID != project.ID || created.Issue == nil || created.Issue.ID != issue.ID {
+		t.Errorf("created = %+v", created)
+	}
+
+	hours, comments, activity := 2.0, "Reviewed twice", 8
+	if err := client.UpdateTimeEntry(created.ID, &redmine.TimeEntryUpdate{Hours: &hours, Comments: &comments, ActivityID: &activity}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := client.GetTimeEntry(created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Hours != 2 || got.Comments != "Reviewed twice" || got.Activity.Name != "Design" || got.SpentOn != "2025-01-10" {
+		t.Errorf("after update = %+v", got)
+	}
+
+	if err := client.DeleteTimeEntry(created.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetTimeEntry(created.ID); !errors.Is(err, redmine.ErrNotFound) {
+		t.Errorf("after delete: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestCreateTimeEntryReportsValidationErrors(t *testing.T) {
+	srv := redminetest.NewServer()
+	defer srv.Close()
+	client := srv.Client()
+
+	_, err := client.CreateTimeEntry(&redmine.TimeEntryCreate{IssueID: 999, Hours: 1})
+	var apiErr *redmine.APIError
+	if !errors.Is(err, redmine.ErrValidation) || !errors.As(err, &apiErr) {
+		t.Fatalf("err = %v, want a validation error", err)
+	}
+	if len(apiErr.Errors) != 1 || apiErr.Errors[0] != "Issue is invalid" {
+		t.Errorf("errors = %q", apiErr.Errors)
+	}
+}
+
+func TestListTimeEntriesFilters(t *testing.T) {
+	srv := redminetest.NewServer()
+	defer srv.Close()
+	project := srv.AddProject("demo", "Demo")
+	srv.AddProject("other", "Other")
+	first := srv.AddIssue(redmine.IssueCreate{ProjectID: project.ID, Subject: "First"})
+	second := srv.AddIssue(redmine.IssueCreate{ProjectID: project.ID, Subject: "Second"})
+	bob := srv.AddUser(redminetest.User{Login: "bob", FirstName: "Bob", LastName: "Jones", APIKey: "bob-key"})
+	client := srv.Client()
+
+	for _, e := range []redmine.TimeEntryCreate{
+		{IssueID: first.ID, Hours: 1, SpentOn: "2025-01-01", ActivityID: 8},
+		{IssueID: first.ID, Hours: 2, SpentOn: "2025-01-15", ActivityID: 9},
+		{IssueID: second.ID, Hours: 3, SpentOn: "2025-02-01", ActivityID: 9, UserID: bob.ID},
+		{ProjectID: "other", Hours: 4, SpentOn: "2025-01-20", ActivityID: 10},
+	} {
+		if _, err := client.CreateTimeEntry(&e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter redmine.TimeEntryFilter
+		want   []float64
+	}{
+		{"all", redmine.TimeEntryFilter{}, []float64{4, 3, 2, 1}},
+		{"project", redmine.TimeEntryFilter{ProjectID: "demo"}, []float64{3, 2, 1}},
+		{"issue", redmine.TimeEntryFilter{IssueID: first.ID}, []float64{2, 1}},
+		{"user", redmine.TimeEntryFilter{UserID: "me"}, []float64{4, 2, 1}},
+		{"activity", redmine.TimeEntryFilter{ActivityID: 9}, []float64{3, 2}},
+		{"date range", redmine.TimeEntryFilter{From: "2025-01-15", To: "2025-01-31"}, []float64{4, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := client.ListTimeEntries(&tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var hours []float64
+			for _, e := range got.TimeEntries {
+				hours = append(hours, e.Hours)
+			}
+			if !slices.Equal(hours, tt.want) || got.TotalCount != len(tt.want) {
+				t.Errorf("hours = %v (total %d), want %v", hours, got.TotalCount, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindTimeEntryActivityByName(t *testing.T) {
+	srv := redminetest.NewServer()
+	defer srv.Close()
+	client := srv.Client()
+
+	activity, err := client.FindTimeEntryActivityByName("testing")
+	if err != nil || activity.ID != 10 {
+		t.Errorf("FindTimeEntryActivityByName = %+v, %v, want #10", activity, err)
+	}
+}
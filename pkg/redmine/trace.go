@@ -0,0 +1,139 @@
+package redmine
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const redacted = "REDACTED"
+
+// sensitiveHeaders are never written to traces, HAR files or cassettes.
+var sensitiveHeaders = []string{"X-Redmine-Api-Key", "Authorization", "Cookie", "Set-Cookie"}
+
+// RedactURL hides credentials passed in the query string or user info.
+func RedactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	if u.User != nil {
+		u.User = url.User(redacted)
+	}
+	q := u.Query()
+	if q.Has("key") {
+		q.Set("key", redacted)
+		u.RawQuery = q.Encode()
+	}
+	return u.String()
+}
+
+// RedactHeader returns a copy of h with credential headers masked.
+func RedactHeader(h http.Header) http.Header {
+	out := h.Clone()
+	if out == nil {
+		return http.Header{}
+	}
+	for _, name := range sensitiveHeaders {
+		if out.Get(name) != "" {
+			out.Set(name, redacted)
+		}
+	}
+	return out
+}
+
+func (c *Client) debugOutput() io.Writer {
+	if c.DebugOutput != nil {
+		return c.DebugOutput
+	}
+	return os.Stderr
+}
+
+func (c *Client) debugf(format string, args ...interface{}) {
+	if c.Debug {
+		fmt.Fprintf(c.debugOutput(), "[DEBUG] "+format+"\n", args...)
+	}
+}
+
+// trace logs one HTTP exchange. Bodies are included when c.DebugBodies is set.
+func (c *Client) trace(req *http.Request, reqBody []byte, resp *http.Response, respBody []byte, elapsed time.Duration, err error) {
+	if !c.Debug {
+		return
+	}
+	w := c.debugOutput()
+
+	status := "error"
+	if resp != nil {
+		status = fmt.Sprintf("%d", resp.StatusCode)
+	}
+	// アップロードはボディを保持しないので Content-Length を使う
+	reqBytes := int64(len(reqBody))
+	if reqBody == nil && req.ContentLength > 0 {
+		reqBytes = req.ContentLength
+	}
+	fmt.Fprintf(w, "[DEBUG] method=%s url=%s status=%s duration=%s req_bytes=%d resp_bytes=%d",
+		req.Method, RedactURL(req.URL.String()), status, elapsed.Round(time.Millisecond), reqBytes, len(respBody))
+	if err != nil {
+		fmt.Fprintf(w, " error=%q", err.Error())
+	}
+	fmt.Fprintln(w)
+
+	if !c.DebugBodies {
+		return
+	}
+	// /users/current.json は api_key を返し、リクエストにも鍵やパスワードが含まれうる
+	secrets := requestSecrets(req)
+	writeHeaders(w, "> ", RedactHeader(req.Header))
+	if reqBody == nil && req.ContentLength > 0 {
+		fmt.Fprintf(w, "> [%d bytes of %s]\n", req.ContentLength, req.Header.Get("Content-Type"))
+	} else {
+		writeBody(w, "> ", req.Header.Get("Content-Type"), reqBody, secrets)
+	}
+	if resp != nil {
+		writeHeaders(w, "< ", RedactHeader(resp.Header))
+		writeBody(w, "< ", resp.Header.Get("Content-Type"), respBody, secrets)
+	}
+}
+
+func writeHeaders(w io.Writer, prefix string, h http.Header) {
+	for name, values := range h {
+		fmt.Fprintf(w, "%s%s: %s\n", prefix, name, strings.Join(values, ", "))
+	}
+}
+
+// writeBody prints a text body with credentials scrubbed.
+func writeBody(w io.Writer, prefix, contentType string, body []byte, secrets []string) {
+	if len(body) == 0 {
+		return
+	}
+	if !isTextContent(contentType) {
+		fmt.Fprintf(w, "%s[%d bytes of %s]\n", prefix, len(body), contentType)
+		return
+	}
+	for _, line := range strings.Split(strings.TrimRight(scrub(string(body), secrets), "\n"), "\n") {
+		fmt.Fprintf(w, "%s%s\n", prefix, line)
+	}
+}
+
+func isTextContent(contentType string) bool {
+	return contentType == "" ||
+		strings.HasPrefix(contentType, "text/") ||
+		strings.Contains(contentType, "json") ||
+		strings.Contains(contentType, "xml")
+}
+
+// readBody reads and restores an http body so it can be inspected.
+func readBody(body *io.ReadCloser) []byte {
+	if *body == nil || *body == http.NoBody {
+		return nil
+	}
+	data, _ := io.ReadAll(*body)
+	(*body).Close()
+	*body = io.NopCloser(bytes.NewReader(data))
+	return data
+}
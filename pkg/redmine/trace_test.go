@@ -0,0 +1,58 @@
+package redmine_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+	"github.com/ikasamt/rd/pkg/redmine/redminetest"
+)
+
+// The API key is sent in a header, returned by /users/current.json and may
+// appear in a request body; none of them may reach a trace or HAR file.
+func TestTraceAndHARRedactAPIKey(t *testing.T) {
+	srv := redminetest.NewServer()
+	defer srv.Close()
+	project := srv.AddProject("demo", "Demo")
+
+	var trace bytes.Buffer
+	client := srv.Client()
+	client.Debug = true
+	client.DebugBodies = true
+	client.DebugOutput = &trace
+	har := client.RecordHAR()
+
+	if _, err := client.GetCurrentUser(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CreateIssue(&redmine.IssueCreate{ProjectID: project.ID, Subject: "Key", Description: "key is " + srv.APIKey}); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "session.har")
+	if err := har.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	harData, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, out := range map[string]string{"trace": trace.String(), "HAR": string(harData)} {
+		if strings.Contains(out, srv.APIKey) {
+			t.Errorf("%s contains the API key:\n%s", name, out)
+		}
+		if !strings.Contains(out, "REDACTED") {
+			t.Errorf("%s has nothing redacted:\n%s", name, out)
+		}
+		if !strings.Contains(out, "/users/current.json") {
+			t.Errorf("%s is missing the request:\n%s", name, out)
+		}
+	}
+	if !strings.Contains(trace.String(), `"api_key":"REDACTED"`) {
+		t.Errorf("trace does not show the redacted api_key field:\n%s", trace.String())
+	}
+}
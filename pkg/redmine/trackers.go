@@ -0,0 +1,25 @@
+package redmine
+
+import "context"
+
+type TrackersResponse struct {
+	Trackers []Tracker `json:"trackers"`
+}
+
+func (c *Client) ListTrackers() ([]Tracker, error) {
+	return c.ListTrackersContext(context.Background())
+}
+
+func (c *Client) ListTrackersContext(ctx context.Context) ([]Tracker, error) {
+	trackers, _, err := c.listTrackers(ctx)
+	return trackers, err
+}
+
+func (c *Client) listTrackers(ctx context.Context) ([]Tracker, bool, error) {
+	var response TrackersResponse
+	cached, err := c.cachedGet(ctx, ResourceTrackers, "", "/trackers.json", nil, &response)
+	if err != nil {
+		return nil, false, err
+	}
+	return response.Trackers, cached, nil
+}
@@ -0,0 +1,175 @@
+package redmine
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout is the connect and response timeout used by NewClient.
+const DefaultTimeout = 30 * time.Second
+
+// TransportOptions configures TLS, proxy and timeout of the HTTP client.
+type TransportOptions struct {
+	// CAFile is a PEM bundle trusted in addition to the system roots.
+	CAFile string
+	// CertFile and KeyFile hold a PEM client certificate for mTLS.
+	CertFile string
+	KeyFile  string
+	// ProxyURL overrides the HTTP(S)_PROXY environment variables. Hosts
+	// listed in NO_PROXY and loopback addresses are still reached directly.
+	ProxyURL string
+	// Timeout limits connecting and waiting for the response headers.
+	// Bodies are not limited, so large uploads and downloads can take as
+	// long as they need; cancel the request context to stop them.
+	// 0 uses DefaultTimeout.
+	Timeout time.Duration
+	// Insecure disables TLS certificate verification. Use only for test instances.
+	Insecure bool
+}
+
+// NewHTTPClient builds an http.Client from opts.
+func NewHTTPClient(opts TransportOptions) (*http.Client, error) {
+	transport := newTransport(opts.Timeout)
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: opts.Insecure,
+	}
+	if opts.CAFile != "" {
+		pem, err := os.ReadFile(opts.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", opts.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if opts.CertFile != "" || opts.KeyFile != "" {
+		if opts.CertFile == "" || opts.KeyFile == "" {
+			return nil, fmt.Errorf("client certificate requires both a certificate and a key file")
+		}
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	if opts.ProxyURL != "" {
+		proxy, err := url.Parse(opts.ProxyURL)
+		if err != nil || proxy.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL: %s", opts.ProxyURL)
+		}
+		noProxy := os.Getenv("NO_PROXY")
+		if noProxy == "" {
+			noProxy = os.Getenv("no_proxy")
+		}
+		transport.Proxy = proxyFunc(proxy, noProxy)
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
+
+// proxyFunc は proxy を使うが、noProxy（NO_PROXY の形式）に該当するホストと
+// ループバックには直接つなぐ。http.ProxyFromEnvironment と同じ規則にしている。
+func proxyFunc(proxy *url.URL, noProxy string) func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		if bypassProxy(req.URL, noProxy) {
+			return nil, nil
+		}
+		return proxy, nil
+	}
+}
+
+// bypassProxy は u へプロキシを通さずにつなぐべきかを返す。noProxy はカンマ区切りで、
+// "*"、ホスト名（サブドメインも含む）、".example.com"（サブドメインのみ）、IP、CIDR を受け付け、
+// それぞれ ":port" でポートを限定できる。
+func bypassProxy(u *url.URL, noProxy string) bool {
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	ip := net.ParseIP(host)
+	if host == "localhost" || ip != nil && ip.IsLoopback() {
+		return true
+	}
+
+	for _, entry := range strings.Split(noProxy, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if entry == "*" {
+			return true
+		}
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			if p != port {
+				continue
+			}
+			entry = h
+		}
+		if entryIP := net.ParseIP(entry); entryIP != nil {
+			if ip != nil && entryIP.Equal(ip) {
+				return true
+			}
+			continue
+		}
+		entry = strings.TrimPrefix(entry, "*")
+		if strings.HasPrefix(entry, ".") {
+			if strings.HasSuffix(host, entry) {
+				return true
+			}
+		} else if host == entry || strings.HasSuffix(host, "."+entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// newTransport は接続とレスポンスヘッダーまでの待ち時間を timeout に制限する。
+// http.Client.Timeout は本文の転送も含むため、大きなファイルの送受信が途中で切れてしまう。
+func newTransport(timeout time.Duration) *http.Transport {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = timeout
+	transport.ResponseHeaderTimeout = timeout
+	return transport
+}
+
+// ConfigureTransport replaces c.HTTPClient with one built from opts.
+func (c *Client) ConfigureTransport(opts TransportOptions) error {
+	httpClient, err := NewHTTPClient(opts)
+	if err != nil {
+		return err
+	}
+	if opts.Insecure {
+		c.debugf("TLS certificate verification is disabled")
+	}
+	c.HTTPClient = httpClient
+	return nil
+}
@@ -0,0 +1,225 @@
+package redmine
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBypassProxy(t *testing.T) {
+	tests := []struct {
+		noProxy string
+		url     string
+		want    bool
+	}{
+		{"", "https://redmine.example.com/", false},
+		{"*", "https://redmine.example.com/", true},
+		{"example.com", "https://redmine.example.com/", true},
+		{"example.com", "https://example.com/", true},
+		{"example.com", "https://notexample.com/", false},
+		{".example.com", "https://redmine.example.com/", true},
+		{".example.com", "https://example.com/", false},
+		{"*.example.com", "https://redmine.example.com/", true},
+		{"other.org, Redmine.Example.com", "https://redmine.example.com/", true},
+		{"redmine.example.com:8443", "https://redmine.example.com:8443/", true},
+		{"redmine.example.com:8443", "https://redmine.example.com/", false},
+		{"redmine.example.com:443", "https://redmine.example.com/", true},
+		{"10.0.0.0/8", "http://10.1.2.3/", true},
+		{"10.0.0.0/8", "http://192.168.0.1/", false},
+		{"192.168.0.1", "http://192.168.0.1:3000/", true},
+		{"[::2]:80", "http://[::2]/", true},
+		// ループバックは常に直接つなぐ
+		{"", "http://localhost:3000/", true},
+		{"", "http://127.0.0.1/", true},
+		{"", "http://[::1]/", true},
+	}
+	for _, tt := range tests {
+		u, _ := url.Parse(tt.url)
+		if got := bypassProxy(u, tt.noProxy); got != tt.want {
+			t.Errorf("bypassProxy(%s, %q) = %v, want %v", tt.url, tt.noProxy, got, tt.want)
+		}
+	}
+}
+
+func TestProxyURLHonoursNoProxy(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		w.Write([]byte(`{"issue":{"id":1}}`))
+	}))
+	defer proxy.Close()
+
+	t.Setenv("NO_PROXY", "internal.example")
+	client, err := NewHTTPClient(TransportOptions{ProxyURL: proxy.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Get("http://redmine.example/issues/1.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if len(proxied) != 1 || proxied[0] != "http://redmine.example/issues/1.json" {
+		t.Errorf("proxy received %q", proxied)
+	}
+
+	transport := client.Transport.(*http.Transport)
+	req, _ := http.NewRequest("GET", "http://redmine.internal.example/issues.json", nil)
+	if u, err := transport.Proxy(req); u != nil || err != nil {
+		t.Errorf("host in NO_PROXY goes through %v, %v", u, err)
+	}
+
+	if _, err := NewHTTPClient(TransportOptions{ProxyURL: "proxy.internal"}); err == nil {
+		t.Error("proxy URL without a scheme was accepted")
+	}
+}
+
+// testPKI は CA と、それが署名したサーバー証明書（127.0.0.1）とクライアント証明書を作り、PEM ファイルに書く。
+type testPKI struct {
+	caFile, certFile, keyFile string
+	serverCert                tls.Certificate
+	pool                      *x509.CertPool
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	t.Helper()
+	dir := t.TempDir()
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "rd test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, _ := x509.ParseCertificate(caDER)
+
+	issue := func(serial int64, usage x509.ExtKeyUsage) ([]byte, *ecdsa.PrivateKey) {
+		key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: "rd test"},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+			IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return der, key
+	}
+	writePEM := func(name, blockType string, der []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	pki := &testPKI{pool: x509.NewCertPool()}
+	pki.pool.AddCert(ca)
+	pki.caFile = writePEM("ca.pem", "CERTIFICATE", caDER)
+
+	serverDER, serverKey := issue(2, x509.ExtKeyUsageServerAuth)
+	pki.serverCert = tls.Certificate{Certificate: [][]byte{serverDER}, PrivateKey: serverKey}
+
+	clientDER, clientKey := issue(3, x509.ExtKeyUsageClientAuth)
+	keyDER, _ := x509.MarshalECPrivateKey(clientKey)
+	pki.certFile = writePEM("client.crt", "CERTIFICATE", clientDER)
+	pki.keyFile = writePEM("client.key", "EC PRIVATE KEY", keyDER)
+	return pki
+}
+
+func TestCAFileAndClientCertificate(t *testing.T) {
+	pki := newTestPKI(t)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	srv.TLS = &tls.Config{
+		Certificates: []tls.Certificate{pki.serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pki.pool,
+	}
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	srv.StartTLS()
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		opts    TransportOptions
+		wantErr string
+	}{
+		{"untrusted server", TransportOptions{CertFile: pki.certFile, KeyFile: pki.keyFile}, "certificate"},
+		{"no client certificate", TransportOptions{CAFile: pki.caFile}, "certificate"},
+		{"trusted with client certificate", TransportOptions{CAFile: pki.caFile, CertFile: pki.certFile, KeyFile: pki.keyFile}, ""},
+		{"insecure with client certificate", TransportOptions{Insecure: true, CertFile: pki.certFile, KeyFile: pki.keyFile}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewHTTPClient(tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := client.Get(srv.URL)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				resp.Body.Close()
+				return
+			}
+			if err == nil {
+				resp.Body.Close()
+				t.Fatal("request succeeded")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTransportOptionErrors(t *testing.T) {
+	pki := newTestPKI(t)
+	tests := []struct {
+		name    string
+		opts    TransportOptions
+		wantErr string
+	}{
+		{"missing CA file", TransportOptions{CAFile: filepath.Join(t.TempDir(), "none.pem")}, "failed to read CA file"},
+		{"CA file without certificates", TransportOptions{CAFile: pki.keyFile}, "no certificates found"},
+		{"certificate without key", TransportOptions{CertFile: pki.certFile}, "both a certificate and a key file"},
+		{"key without certificate", TransportOptions{KeyFile: pki.keyFile}, "both a certificate and a key file"},
+		{"key that does not match", TransportOptions{CertFile: pki.caFile, KeyFile: pki.keyFile}, "failed to load client certificate"},
+	}
+	for _, tt := range tests {
+		if _, err := NewHTTPClient(tt.opts); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: err = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -19,8 +19,12 @@ type Issue struct {
 	Parent         *IssueParent           `json:"parent,omitempty"`
 	Children       []IssueChild           `json:"children,omitempty"`
 	CustomFields   []CustomField          `json:"custom_fields,omitempty"`
+	Attachments    []Attachment           `json:"attachments,omitempty"`
+	Relations      []Relation             `json:"relations,omitempty"`
+	Watchers       []User                 `json:"watchers,omitempty"`
 	CreatedOn      time.Time              `json:"created_on"`
 	UpdatedOn      time.Time              `json:"updated_on"`
+	ClosedOn       *time.Time             `json:"closed_on,omitempty"`
 	Journals       []Journal              `json:"journals,omitempty"`
 }
 
@@ -61,9 +65,10 @@ type User struct {
 }
 
 type CustomField struct {
-	ID    int         `json:"id"`
-	Name  string      `json:"name"`
-	Value interface{} `json:"value"`
+	ID       int         `json:"id"`
+	Name     string      `json:"name"`
+	Multiple bool        `json:"multiple,omitempty"`
+	Value    interface{} `json:"value"`
 }
 
 type IssueParent struct {
@@ -104,6 +109,7 @@ type IssueCreate struct {
 	ParentIssueID  int                    `json:"parent_issue_id,omitempty"`
 	CustomFields   []CustomFieldValue     `json:"custom_fields,omitempty"`
 	WatcherUserIDs []int                  `json:"watcher_user_ids,omitempty"`
+	Uploads        []Upload               `json:"uploads,omitempty"`
 	StartDate      string                 `json:"start_date,omitempty"`
 	DueDate        string                 `json:"due_date,omitempty"`
 	EstimatedHours float64                `json:"estimated_hours,omitempty"`
@@ -136,6 +142,7 @@ type IssueUpdate struct {
 	EstimatedHours *float64               `json:"estimated_hours,omitempty"`
 	DoneRatio      *int                   `json:"done_ratio,omitempty"`
 	Notes          string                 `json:"notes,omitempty"`
+	Uploads        []Upload               `json:"uploads,omitempty"`
 }
 
 type IssueUpdateRequest struct {
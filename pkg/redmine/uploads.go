@@ -0,0 +1,92 @@
+package redmine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// Upload references a file uploaded with Client.Upload. Set it in
+// IssueCreate.Uploads or IssueUpdate.Uploads to attach the file.
+type Upload struct {
+	Token       string `json:"token"`
+	Filename    string `json:"filename,omitempty"`
+	Description string `json:"description,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+}
+
+type UploadResponse struct {
+	Upload struct {
+		ID    int    `json:"id"`
+		Token string `json:"token"`
+	} `json:"upload"`
+}
+
+// Upload streams size bytes from body to /uploads.json and returns the
+// upload token. body is rewound before each attempt, so retries resend the
+// whole file without buffering it in memory.
+func (c *Client) Upload(filename string, body io.ReadSeeker, size int64) (string, error) {
+	return c.UploadContext(context.Background(), filename, body, size)
+}
+
+// UploadContext is like Upload but uses ctx for the request.
+func (c *Client) UploadContext(ctx context.Context, filename string, body io.ReadSeeker, size int64) (string, error) {
+	params := url.Values{}
+	if filename != "" {
+		params.Set("filename", filename)
+	}
+	rawURL, err := c.requestURL("/uploads.json", params)
+	if err != nil {
+		return "", err
+	}
+
+	// 未使用のアップロードは Redmine が後で削除するので、再送しても問題ない
+	p := &payload{contentType: "application/octet-stream", stream: body, size: size}
+	respBody, err := c.execute(WithRetrySafe(ctx), "POST", rawURL, p)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload %s: %w", filename, err)
+	}
+
+	var response UploadResponse
+	if err := json.Unmarshal(respBody, &response); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	if response.Upload.Token == "" {
+		return "", fmt.Errorf("failed to upload %s: no token in response", filename)
+	}
+	return response.Upload.Token, nil
+}
+
+// UploadFile uploads the file at path and returns an Upload ready to be
+// attached, with Filename set to the base name.
+func (c *Client) UploadFile(path string) (*Upload, error) {
+	return c.UploadFileContext(context.Background(), path)
+}
+
+// UploadFileContext is like UploadFile but uses ctx for the request.
+func (c *Client) UploadFileContext(ctx context.Context, path string) (*Upload, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s is not a regular file", path)
+	}
+
+	name := filepath.Base(path)
+	token, err := c.UploadContext(ctx, name, f, info.Size())
+	if err != nil {
+		return nil, err
+	}
+	return &Upload{Token: token, Filename: name}, nil
+}
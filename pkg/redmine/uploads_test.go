@@ -0,0 +1,133 @@
+package redmine_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+	"github.com/ikasamt/rd/pkg/redmine/redminetest"
+)
+
+// slowReader は Read のたびに待つので、転送全体が delay×回数 かかる。
+type slowReader struct {
+	io.ReadSeeker
+	chunk int
+	delay time.Duration
+}
+
+func (r *slowReader) Read(p []byte) (int, error) {
+	time.Sleep(r.delay)
+	if len(p) > r.chunk {
+		p = p[:r.chunk]
+	}
+	return r.ReadSeeker.Read(p)
+}
+
+func TestUploadFileAttachesContent(t *testing.T) {
+	srv := redminetest.NewServer()
+	defer srv.Close()
+	project := srv.AddProject("demo", "Demo")
+	client := srv.Client()
+
+	path := filepath.Join(t.TempDir(), "report.bin")
+	content := bytes.Repeat([]byte("0123456789abcdef"), 64<<10)
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	upload, err := client.UploadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upload.Token == "" || upload.Filename != "report.bin" {
+		t.Fatalf("upload = %+v", upload)
+	}
+	issue, err := client.CreateIssue(&redmine.IssueCreate{ProjectID: project.ID, Subject: "With file", Uploads: []redmine.Upload{*upload}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := srv.Attachments(issue.ID)
+	if len(files) != 1 || files[0].Filename != "report.bin" || !bytes.Equal(files[0].Content, content) {
+		t.Errorf("attachments = %d files", len(files))
+	}
+}
+
+// 本文はメモリに溜めずに Content-Length 付きで送り、再試行では最初から送り直す。
+func TestUploadStreamsAndRewindsOnRetry(t *testing.T) {
+	content := strings.Repeat("x", 1<<20)
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		if r.ContentLength != int64(len(content)) || len(r.TransferEncoding) > 0 {
+			t.Errorf("Content-Length = %d, Transfer-Encoding = %v", r.ContentLength, r.TransferEncoding)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != content {
+			t.Errorf("attempt %d sent %d bytes, want the whole file", n, len(body))
+		}
+		if n == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"upload":{"id":1,"token":"1.abc"}}`))
+	}))
+	defer srv.Close()
+
+	client := redmine.NewClient(srv.URL, "key")
+	client.Retry = redmine.RetryPolicy{MaxRetries: 1, MinWait: time.Millisecond}
+	token, err := client.Upload("big.txt", strings.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "1.abc" || calls != 2 {
+		t.Errorf("token = %q after %d requests", token, calls)
+	}
+}
+
+// タイムアウトは接続と応答待ちだけに掛かり、時間のかかる転送は途中で切らない。
+func TestTimeoutDoesNotCutOffSlowUpload(t *testing.T) {
+	srv := redminetest.NewServer()
+	defer srv.Close()
+	client := srv.Client()
+	if err := client.ConfigureTransport(redmine.TransportOptions{Timeout: 100 * time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+
+	content := bytes.Repeat([]byte("a"), 16<<10)
+	body := &slowReader{ReadSeeker: bytes.NewReader(content), chunk: 1 << 10, delay: 20 * time.Millisecond}
+	start := time.Now()
+	token, err := client.UploadContext(context.Background(), "slow.bin", body, int64(len(content)))
+	if err != nil {
+		t.Fatalf("upload taking %s failed: %v", time.Since(start), err)
+	}
+	if token == "" {
+		t.Error("no token")
+	}
+}
+
+func TestTimeoutStillLimitsWaitingForResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+		w.Write([]byte("{}"))
+	}))
+	defer srv.Close()
+
+	client := redmine.NewClient(srv.URL, "key")
+	client.Retry.MaxRetries = 0
+	if err := client.ConfigureTransport(redmine.TransportOptions{Timeout: 50 * time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+	var v struct{}
+	if err := client.GetContext(context.Background(), "/issues.json", nil, &v); err == nil {
+		t.Error("request outliving the timeout succeeded")
+	}
+}
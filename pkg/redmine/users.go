@@ -1,19 +1,156 @@
 package redmine
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
 type UserDetail struct {
-	ID    int    `json:"id"`
-	Login string `json:"login"`
-	Name  string `json:"firstname"`
+	ID       int    `json:"id"`
+	Login    string `json:"login"`
+	Name     string `json:"firstname"`
+	LastName string `json:"lastname"`
+}
+
+// FullName returns "firstname lastname" as Redmine displays it by default.
+func (u *UserDetail) FullName() string {
+	return strings.TrimSpace(u.Name + " " + u.LastName)
 }
 
 type CurrentUserResponse struct {
 	User UserDetail `json:"user"`
 }
 
+type UsersResponse struct {
+	Users      []UserDetail `json:"users"`
+	TotalCount int          `json:"total_count"`
+	Offset     int          `json:"offset"`
+	Limit      int          `json:"limit"`
+}
+
 func (c *Client) GetCurrentUser() (*UserDetail, error) {
+	return c.GetCurrentUserContext(context.Background())
+}
+
+func (c *Client) GetCurrentUserContext(ctx context.Context) (*UserDetail, error) {
+	// 現在のユーザーは認証情報ごとに異なるので、キーに含める
 	var response CurrentUserResponse
-	if err := c.Get("/users/current.json", nil, &response); err != nil {
+	if _, err := c.cachedGet(ctx, ResourceUsers, "current:"+c.identity(), "/users/current.json", nil, &response); err != nil {
 		return nil, err
 	}
 	return &response.User, nil
 }
+
+// ListUsers returns active users whose login, name or mail contains name.
+// It requires admin rights.
+func (c *Client) ListUsers(name string) ([]UserDetail, error) {
+	return c.ListUsersContext(context.Background(), name)
+}
+
+// ListUsersContext is like ListUsers but uses ctx for the request.
+func (c *Client) ListUsersContext(ctx context.Context, name string) ([]UserDetail, error) {
+	params := url.Values{}
+	params.Set("limit", strconv.Itoa(maxPageSize))
+	if name != "" {
+		params.Set("name", name)
+	}
+
+	var response UsersResponse
+	if err := c.GetContext(ctx, "/users.json", params, &response); err != nil {
+		return nil, err
+	}
+	return response.Users, nil
+}
+
+// ResolveUser turns "me", a numeric ID, a login or a display name into a user.
+// Names (or unambiguous name prefixes) are matched case-insensitively against
+// the members of projectID, which works without admin rights; logins other
+// than the caller's own need the admin-only /users.json, so without admin
+// rights the not-found error says to use the display name instead.
+func (c *Client) ResolveUser(projectID, value string) (*User, error) {
+	return c.ResolveUserContext(context.Background(), projectID, value)
+}
+
+// ResolveUserContext is like ResolveUser but uses ctx for the requests.
+func (c *Client) ResolveUserContext(ctx context.Context, projectID, value string) (*User, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, fmt.Errorf("user cannot be blank")
+	}
+	if strings.EqualFold(value, "me") {
+		me, err := c.GetCurrentUserContext(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get current user: %w", err)
+		}
+		return &User{ID: me.ID, Name: me.FullName()}, nil
+	}
+	if id, err := strconv.Atoi(value); err == nil {
+		return &User{ID: id}, nil
+	}
+
+	// プロジェクトのメンバーから表示名で探す
+	var memberErr error
+	if projectID != "" {
+		memberships, cached, err := c.listMemberships(ctx, projectID)
+		if err != nil {
+			return nil, err
+		}
+		var members []User
+		for i := range memberships {
+			if p := memberships[i].Principal(); p != nil {
+				members = append(members, *p)
+			}
+		}
+		member, err := resolveNamed("user", projectID, value, members, func(u *User) (int, string) { return u.ID, u.Name })
+		if err == nil {
+			return member, nil
+		}
+		if !unmatched(err) {
+			return nil, err
+		}
+		if cached {
+			return c.ResolveUserContext(WithCacheRefresh(ctx), projectID, value)
+		}
+		memberErr = err
+	}
+
+	// ログイン名は自分のものか、管理者のみ参照できる /users.json で探す
+	if me, err := c.GetCurrentUserContext(ctx); err == nil && strings.EqualFold(me.Login, value) {
+		return &User{ID: me.ID, Name: me.FullName()}, nil
+	}
+	users, err := c.ListUsersContext(ctx, value)
+	forbidden := errors.Is(err, ErrForbidden)
+	if err != nil && !forbidden {
+		return nil, fmt.Errorf("failed to look up user '%s': %w", value, err)
+	}
+	var found []UserDetail
+	for _, u := range users {
+		if strings.EqualFold(u.Login, value) || strings.EqualFold(u.FullName(), value) {
+			found = append(found, u)
+		}
+	}
+	if len(found) == 1 {
+		return &User{ID: found[0].ID, Name: found[0].FullName()}, nil
+	}
+	if len(found) > 1 {
+		resolveErr := &ResolveError{Kind: "user", Value: value, Ambiguous: true}
+		for _, u := range found {
+			resolveErr.Candidates = append(resolveErr.Candidates, fmt.Sprintf("%s (%s)", u.FullName(), u.Login))
+		}
+		return nil, resolveErr
+	}
+
+	// メンバーの候補を挙げたエラーがあればそれに説明を加える
+	var notFound *ResolveError
+	if !errors.As(memberErr, &notFound) {
+		notFound = &ResolveError{Kind: "user", Value: value}
+	}
+	if forbidden {
+		notFound.Hint = "other users' logins can only be looked up with an admin API key; use the display name of a project member"
+	}
+	return nil, notFound
+}
@@ -0,0 +1,61 @@
+package redmine_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ikasamt/rd/pkg/redmine"
+	"github.com/ikasamt/rd/pkg/redmine/redminetest"
+)
+
+func TestResolveUserReadsEveryMembershipPage(t *testing.T) {
+	srv := redminetest.NewServer()
+	defer srv.Close()
+	srv.AddProject("demo", "Demo")
+	bob := srv.AddUser(redminetest.User{Login: "bob", FirstName: "Bob", APIKey: "bob-key"})
+	srv.AddMember("demo", bob)
+	var last *redminetest.User
+	for i := 0; i < 150; i++ {
+		last = srv.AddUser(redminetest.User{Login: fmt.Sprintf("user%d", i), FirstName: "User", LastName: fmt.Sprint(i)})
+		srv.AddMember("demo", last)
+	}
+
+	u, err := srv.ClientFor(bob).ResolveUser("demo", "User 149")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.ID != last.ID {
+		t.Errorf("resolved %d, want %d", u.ID, last.ID)
+	}
+}
+
+func TestResolveUserLoginWithoutAdminKey(t *testing.T) {
+	srv := redminetest.NewServer()
+	defer srv.Close()
+	srv.AddProject("demo", "Demo")
+	alice := srv.AddUser(redminetest.User{Login: "asmith", FirstName: "Alice", LastName: "Smith"})
+	bob := srv.AddUser(redminetest.User{Login: "bob", FirstName: "Bob", APIKey: "bob-key"})
+	srv.AddMember("demo", alice)
+	srv.AddMember("demo", bob)
+
+	// 管理者なら他人のログイン名でも解決できる
+	if u, err := srv.Client().ResolveUser("demo", "asmith"); err != nil || u.ID != alice.ID {
+		t.Errorf("admin: %+v, %v", u, err)
+	}
+	// 自分のログイン名は誰でも使える
+	if u, err := srv.ClientFor(bob).ResolveUser("demo", "bob"); err != nil || u.ID != bob.ID {
+		t.Errorf("own login: %+v, %v", u, err)
+	}
+
+	_, err := srv.ClientFor(bob).ResolveUser("demo", "asmith")
+	if !errors.Is(err, redmine.ErrValidation) {
+		t.Fatalf("err = %v, want ErrValidation", err)
+	}
+	for _, want := range []string{"Alice Smith", "admin API key", "display name"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
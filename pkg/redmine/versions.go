@@ -1,6 +1,7 @@
 package redmine
 
 import (
+	"context"
 	"fmt"
 )
 
@@ -19,18 +20,32 @@ type VersionsResponse struct {
 }
 
 func (c *Client) ListVersions(projectID string) (*VersionsResponse, error) {
+	return c.ListVersionsContext(context.Background(), projectID)
+}
+
+func (c *Client) ListVersionsContext(ctx context.Context, projectID string) (*VersionsResponse, error) {
+	versions, _, err := c.listVersions(ctx, projectID)
+	return versions, err
+}
+
+func (c *Client) listVersions(ctx context.Context, projectID string) (*VersionsResponse, bool, error) {
 	path := fmt.Sprintf("/projects/%s/versions.json", projectID)
 	
 	var response VersionsResponse
-	if err := c.Get(path, nil, &response); err != nil {
-		return nil, err
+	cached, err := c.cachedGet(ctx, ResourceVersions, projectID, path, nil, &response)
+	if err != nil {
+		return nil, false, err
 	}
 
-	return &response, nil
+	return &response, cached, nil
 }
 
 func (c *Client) FindVersionByName(projectID, versionName string) (*Version, error) {
-	versions, err := c.ListVersions(projectID)
+	return c.FindVersionByNameContext(context.Background(), projectID, versionName)
+}
+
+func (c *Client) FindVersionByNameContext(ctx context.Context, projectID, versionName string) (*Version, error) {
+	versions, cached, err := c.listVersions(ctx, projectID)
 	if err != nil {
 		return nil, err
 	}
@@ -41,5 +56,14 @@ func (c *Client) FindVersionByName(projectID, versionName string) (*Version, err
 		}
 	}
 
-	return nil, fmt.Errorf("version '%s' not found in project '%s'", versionName, projectID)
+	// キャッシュが古い可能性があるので取り直して再検索
+	if cached {
+		return c.FindVersionByNameContext(WithCacheRefresh(ctx), projectID, versionName)
+	}
+
+	names := make([]string, 0, len(versions.Versions))
+	for _, version := range versions.Versions {
+		names = append(names, version.Name)
+	}
+	return nil, &ResolveError{Kind: "version", Value: versionName, Project: projectID, Candidates: names}
 }
\ No newline at end of file
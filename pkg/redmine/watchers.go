@@ -0,0 +1,31 @@
+package redmine
+
+import (
+	"context"
+	"fmt"
+)
+
+type watcherRequest struct {
+	UserID int `json:"user_id"`
+}
+
+// AddWatcher makes a user watch an issue. Adding an existing watcher is not an error.
+func (c *Client) AddWatcher(issueID, userID int) error {
+	return c.AddWatcherContext(context.Background(), issueID, userID)
+}
+
+// AddWatcherContext is like AddWatcher but uses ctx for the request.
+func (c *Client) AddWatcherContext(ctx context.Context, issueID, userID int) error {
+	// 同じユーザーを何度追加しても結果は変わらないので、POST でもリトライしてよい
+	return c.PostContext(WithRetrySafe(ctx), fmt.Sprintf("/issues/%d/watchers.json", issueID), nil, watcherRequest{UserID: userID}, nil)
+}
+
+// RemoveWatcher stops a user from watching an issue.
+func (c *Client) RemoveWatcher(issueID, userID int) error {
+	return c.RemoveWatcherContext(context.Background(), issueID, userID)
+}
+
+// RemoveWatcherContext is like RemoveWatcher but uses ctx for the request.
+func (c *Client) RemoveWatcherContext(ctx context.Context, issueID, userID int) error {
+	return c.DeleteContext(ctx, fmt.Sprintf("/issues/%d/watchers/%d.json", issueID, userID), nil)
+}
@@ -0,0 +1,144 @@
+package redmine
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type WikiPageRef struct {
+	Title string `json:"title"`
+}
+
+// WikiPageInfo is an entry of the wiki index (without text).
+type WikiPageInfo struct {
+	Title     string       `json:"title"`
+	Parent    *WikiPageRef `json:"parent,omitempty"`
+	Version   int          `json:"version"`
+	CreatedOn time.Time    `json:"created_on"`
+	UpdatedOn time.Time    `json:"updated_on"`
+}
+
+type WikiPage struct {
+	Title       string       `json:"title"`
+	Parent      *WikiPageRef `json:"parent,omitempty"`
+	Text        string       `json:"text"`
+	Version     int          `json:"version"`
+	Author      User         `json:"author"`
+	Comments    string       `json:"comments"`
+	CreatedOn   time.Time    `json:"created_on"`
+	UpdatedOn   time.Time    `json:"updated_on"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+type WikiPagesResponse struct {
+	WikiPages []WikiPageInfo `json:"wiki_pages"`
+}
+
+type WikiPageResponse struct {
+	WikiPage WikiPage `json:"wiki_page"`
+}
+
+// WikiPageUpdate creates or replaces a wiki page. When Version is set,
+// Redmine rejects the update with 409 (ErrConflict) if the page has been
+// changed since that version.
+type WikiPageUpdate struct {
+	Text        string   `json:"text"`
+	Comments    string   `json:"comments,omitempty"`
+	Version     int      `json:"version,omitempty"`
+	ParentTitle string   `json:"parent_title,omitempty"`
+	Uploads     []Upload `json:"uploads,omitempty"`
+}
+
+type wikiPageUpdateRequest struct {
+	WikiPage WikiPageUpdate `json:"wiki_page"`
+}
+
+func wikiPath(projectID, title string) string {
+	return fmt.Sprintf("/projects/%s/wiki/%s", url.PathEscape(projectID), url.PathEscape(title))
+}
+
+func (c *Client) ListWikiPages(projectID string) ([]WikiPageInfo, error) {
+	return c.ListWikiPagesContext(context.Background(), projectID)
+}
+
+// ListWikiPagesContext is like ListWikiPages but uses ctx for the request.
+func (c *Client) ListWikiPagesContext(ctx context.Context, projectID string) ([]WikiPageInfo, error) {
+	var response WikiPagesResponse
+	path := fmt.Sprintf("/projects/%s/wiki/index.json", url.PathEscape(projectID))
+	if err := c.GetContext(ctx, path, nil, &response); err != nil {
+		return nil, err
+	}
+	return response.WikiPages, nil
+}
+
+// GetWikiPage returns a wiki page with its attachments. version 0 means the
+// current version.
+func (c *Client) GetWikiPage(projectID, title string, version int) (*WikiPage, error) {
+	return c.GetWikiPageContext(context.Background(), projectID, title, version)
+}
+
+// GetWikiPageContext is like GetWikiPage but uses ctx for the request.
+func (c *Client) GetWikiPageContext(ctx context.Context, projectID, title string, version int) (*WikiPage, error) {
+	path := wikiPath(projectID, title)
+	if version > 0 {
+		path += "/" + strconv.Itoa(version)
+	}
+	params := url.Values{}
+	params.Set("include", "attachments")
+
+	var response WikiPageResponse
+	if err := c.GetContext(ctx, path+".json", params, &response); err != nil {
+		return nil, err
+	}
+	return &response.WikiPage, nil
+}
+
+// PutWikiPage creates the page or saves a new version of it.
+func (c *Client) PutWikiPage(projectID, title string, page *WikiPageUpdate) error {
+	return c.PutWikiPageContext(context.Background(), projectID, title, page)
+}
+
+// PutWikiPageContext is like PutWikiPage but uses ctx for the request.
+func (c *Client) PutWikiPageContext(ctx context.Context, projectID, title string, page *WikiPageUpdate) error {
+	return c.PutContext(ctx, wikiPath(projectID, title)+".json", nil, wikiPageUpdateRequest{WikiPage: *page})
+}
+
+func (c *Client) DeleteWikiPage(projectID, title string) error {
+	return c.DeleteWikiPageContext(context.Background(), projectID, title)
+}
+
+// DeleteWikiPageContext is like DeleteWikiPage but uses ctx for the request.
+func (c *Client) DeleteWikiPageContext(ctx context.Context, projectID, title string) error {
+	return c.DeleteContext(ctx, wikiPath(projectID, title)+".json", nil)
+}
+
+// WikiPageHistory returns up to limit versions of a page, newest first
+// (limit <= 0 means all). The REST API has no history endpoint, so each
+// version is fetched separately.
+func (c *Client) WikiPageHistory(projectID, title string, limit int) ([]WikiPage, error) {
+	return c.WikiPageHistoryContext(context.Background(), projectID, title, limit)
+}
+
+// WikiPageHistoryContext is like WikiPageHistory but uses ctx for the requests.
+func (c *Client) WikiPageHistoryContext(ctx context.Context, projectID, title string, limit int) ([]WikiPage, error) {
+	current, err := c.GetWikiPageContext(ctx, projectID, title, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	history := []WikiPage{*current}
+	for v := current.Version - 1; v >= 1; v-- {
+		if limit > 0 && len(history) >= limit {
+			break
+		}
+		page, err := c.GetWikiPageContext(ctx, projectID, title, v)
+		if err != nil {
+			return history, fmt.Errorf("failed to get version %d: %w", v, err)
+		}
+		history = append(history, *page)
+	}
+	return history, nil
+}